@@ -67,6 +67,12 @@ func AddSubmoduleInContext(context *models.NestContext, url urls.HttpUrl, ref st
 		Ref:  ref,
 	}
 
+	// nothing is cloned if the policy forbids the new module
+	err = internal.PolicyError(internal.SubmodulePolicyViolations(context.Policy, newSubmodule, context.ProjectRoot))
+	if err != nil {
+		return nil, err
+	}
+
 	// append submodule and clone it
 	migrationChain.Add(mcontext.AppendSubmodule{Context: context, Submodule: newSubmodule})
 	migrationChain.Add(git.Clone{Url: newSubmodule.Url, Path: absolutePath.Parent(), CloneDirName: absolutePath.Base()})
@@ -80,5 +86,9 @@ func AddSubmoduleInContext(context *models.NestContext, url urls.HttpUrl, ref st
 		migrationChain.Add(git.Checkout{Path: context.ProjectRoot.SJoin(localSubmoduleClonePath), Ref: newSubmodule.Ref})
 	}
 
+	if context.Config.Config.RecordIntegrity {
+		migrationChain.Add(mcontext.UpdateSubmoduleIntegrity{Context: context, SubmodulePath: relativeToRoot})
+	}
+
 	return migrationChain.Migrations(), nil
 }
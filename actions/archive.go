@@ -0,0 +1,89 @@
+package actions
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jeftadlvw/git-nest/interfaces"
+	"github.com/jeftadlvw/git-nest/internal"
+	"github.com/jeftadlvw/git-nest/internal/constants"
+	"github.com/jeftadlvw/git-nest/migrations/fs"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/models/urls"
+	"github.com/jeftadlvw/git-nest/utils"
+)
+
+/*
+ArchiveProject is a wrapper that exports the committed state of the project and every nested module at its pinned ref
+into a single archive or directory of plain files. An empty format is inferred from the output path.
+If groups are passed, only nested modules within these groups are archived. Nested modules with sparse paths
+are restricted to them.
+*/
+func ArchiveProject(context *models.NestContext, output models.Path, format string, groups []string) ([]interfaces.Migration, error) {
+	if !context.IsGitInstalled {
+		return nil, errors.New("unable to archive if git is not installed")
+	}
+
+	if format == "" {
+		format = internal.ArchiveFormatFromPath(output.String())
+	}
+
+	err := internal.ValidateArchiveFormat(format)
+	if err != nil {
+		return nil, err
+	}
+
+	submodules, err := internal.SubmodulesInGroups(context.Config.Submodules, groups)
+	if err != nil {
+		return nil, err
+	}
+
+	projectCommit, err := utils.GetGitRefCommit(context.ProjectRoot, "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve project commit: %w", err)
+	}
+
+	sources := []internal.ArchiveSource{{Repository: context.ProjectRoot, Revision: projectCommit}}
+	manifest := internal.ArchiveManifest{
+		GeneratedBy: constants.ApplicationName + " " + constants.Version(),
+		Commit:      projectCommit,
+		Groups:      groups,
+		Modules:     []internal.ArchiveManifestModule{},
+	}
+
+	for _, submodule := range submodules {
+		repository := context.ProjectRoot.Join(submodule.Path)
+		if !repository.IsDir() {
+			return nil, fmt.Errorf("nested module %s does not exist, run %s sync first", submodule.Path, constants.ApplicationName)
+		}
+
+		commit, err := internal.SubmoduleArchiveCommit(submodule, repository)
+		if err != nil {
+			return nil, fmt.Errorf("could not resolve ref of nested module %s: %w", submodule.Path, err)
+		}
+
+		treeHash, err := utils.GetGitTreeHash(repository, commit)
+		if err != nil {
+			return nil, fmt.Errorf("could not resolve tree of nested module %s: %w", submodule.Path, err)
+		}
+
+		moduleUrl := ""
+		if submodule.Url != nil {
+			moduleUrl = urls.RedactCredentials(submodule.Url.String())
+		}
+
+		sources = append(sources, internal.ArchiveSource{Repository: repository, Revision: commit, Prefix: submodule.Path.UnixString(), Paths: submodule.SparsePaths})
+		manifest.Modules = append(manifest.Modules, internal.ArchiveManifestModule{
+			Path:        submodule.Path.UnixString(),
+			Url:         moduleUrl,
+			Ref:         submodule.Ref,
+			RefKind:     string(submodule.RefKind),
+			Commit:      commit,
+			TreeHash:    treeHash,
+			SparsePaths: submodule.SparsePaths,
+		})
+	}
+
+	return []interfaces.Migration{
+		fs.WriteArchive{Output: output, Format: format, Sources: sources, Manifest: manifest},
+	}, nil
+}
@@ -0,0 +1,112 @@
+package actions
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jeftadlvw/git-nest/interfaces"
+	"github.com/jeftadlvw/git-nest/internal"
+	"github.com/jeftadlvw/git-nest/internal/constants"
+	"github.com/jeftadlvw/git-nest/migrations"
+	"github.com/jeftadlvw/git-nest/migrations/fs"
+	"github.com/jeftadlvw/git-nest/migrations/git"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/models/urls"
+	"github.com/jeftadlvw/git-nest/utils"
+)
+
+/*
+CreateBundle is a wrapper that writes an offline bundle, which contains the configuration file and a git bundle of every
+nested module, e.g. to carry a project into an air-gapped network.
+*/
+func CreateBundle(context *models.NestContext, output models.Path) ([]interfaces.Migration, error) {
+	if !context.IsGitInstalled {
+		return nil, errors.New("unable to bundle if git is not installed")
+	}
+
+	if !context.ConfigFileExists {
+		return nil, errors.New("project has no configuration file")
+	}
+
+	manifest := internal.BundleManifest{
+		GeneratedBy: constants.ApplicationName + " " + constants.Version(),
+		Modules:     []internal.BundleModule{},
+	}
+
+	for index, submodule := range context.Config.Submodules {
+		repository := context.ProjectRoot.Join(submodule.Path)
+		if !repository.IsDir() {
+			return nil, fmt.Errorf("nested module %s does not exist, run %s sync first", submodule.Path, constants.ApplicationName)
+		}
+
+		commit, err := utils.GetGitRefCommit(repository, "HEAD")
+		if err != nil {
+			return nil, fmt.Errorf("could not resolve head of nested module %s: %w", submodule.Path, err)
+		}
+
+		moduleUrl := ""
+		if submodule.Url != nil {
+			moduleUrl = urls.RedactCredentials(submodule.Url.String())
+		}
+
+		manifest.Modules = append(manifest.Modules, internal.BundleModule{
+			Path:    submodule.Path.UnixString(),
+			Url:     moduleUrl,
+			Remote:  submodule.RemoteName(),
+			Ref:     submodule.Ref,
+			RefKind: string(submodule.RefKind),
+			Commit:  commit,
+			Bundle:  internal.BundleFileName(index),
+		})
+	}
+
+	return []interfaces.Migration{
+		fs.WriteBundle{Output: output, ProjectRoot: context.ProjectRoot, ConfigFile: context.ConfigFile, Manifest: manifest},
+	}, nil
+}
+
+/*
+RestoreBundle is a wrapper that clones every missing nested module from the git bundles of an extracted offline bundle
+and resets its remote to the configured url. Refs are not checked out, which is left to ApplyConfigToModulesOffline.
+*/
+func RestoreBundle(context *models.NestContext, dir models.Path, manifest internal.BundleManifest) ([]interfaces.Migration, error) {
+	migrationChain := migrations.MigrationChain{}
+
+	if !context.IsGitInstalled {
+		return nil, errors.New("unable to restore if git is not installed")
+	}
+
+	// no module is cloned if the configuration violates the policy
+	err := internal.PolicyError(internal.PolicyViolations(context.Policy, context.Config.Submodules, context.ProjectRoot))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, submodule := range context.Config.Submodules {
+		absolutePath := context.ProjectRoot.Join(submodule.Path)
+		if absolutePath.Exists() {
+			continue
+		}
+
+		module, ok := manifest.Module(submodule.Path)
+		if !ok {
+			return nil, fmt.Errorf("nested module %s is not contained in the bundle", submodule.Path)
+		}
+
+		migrationChain.Add(git.CloneBundle{
+			Bundle:       dir.SJoin(module.Bundle),
+			Path:         absolutePath.Parent(),
+			CloneDirName: submodule.Path.Base(),
+			RemoteName:   submodule.RemoteName(),
+		})
+
+		if submodule.Url != nil {
+			migrationChain.Add(git.SetRemoteUrl{
+				Path:   absolutePath,
+				Remote: submodule.RemoteName(),
+				Url:    submodule.Url.String(),
+			})
+		}
+	}
+
+	return migrationChain.Migrations(), nil
+}
@@ -0,0 +1,107 @@
+package actions
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jeftadlvw/git-nest/interfaces"
+	"github.com/jeftadlvw/git-nest/internal"
+	"github.com/jeftadlvw/git-nest/migrations"
+	"github.com/jeftadlvw/git-nest/migrations/fs"
+	"github.com/jeftadlvw/git-nest/models"
+	"slices"
+)
+
+/*
+InstallHooks is a high-level wrapper that installs the passed managed git hooks, e.g. internal.SyncHooks.
+Existing user hooks are kept and called by the managed hooks. Managed hooks that are already installed are updated.
+*/
+func InstallHooks(context *models.NestContext, hooks []string) ([]interfaces.Migration, error) {
+	migrationChain := migrations.MigrationChain{}
+
+	for _, hook := range hooks {
+		if !slices.Contains(internal.ManagedHooks, hook) {
+			return nil, fmt.Errorf("%s is not a hook managed by git-nest", hook)
+		}
+	}
+
+	hooksDir, err := hooksDirectoryFromContext(context)
+	if err != nil {
+		return nil, err
+	}
+
+	projectDir, err := internal.HookProjectDirectory(*context)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, hook := range hooks {
+		status, err := internal.HookStatus(hooksDir, hook)
+		if err != nil {
+			return nil, err
+		}
+
+		hookFile := hooksDir.SJoin(hook)
+		chainedHookFile := hooksDir.SJoin(hook + internal.ChainedHookSuffix)
+
+		// keep user hook by chaining it
+		if status == internal.HOOK_STATUS_FOREIGN {
+			if chainedHookFile.Exists() {
+				return nil, fmt.Errorf("cannot chain %s: %s already exists", hookFile, chainedHookFile)
+			}
+			migrationChain.Add(fs.MoveFile{From: hookFile, To: chainedHookFile})
+		}
+
+		migrationChain.Add(fs.WriteFile{
+			Path:    hookFile,
+			Content: internal.HookScript(hook, projectDir),
+			Perm:    0755,
+		})
+	}
+
+	return migrationChain.Migrations(), nil
+}
+
+/*
+UninstallHooks is a high-level wrapper that removes all managed git hooks and restores chained user hooks.
+Hooks that were not written by git-nest are left untouched.
+*/
+func UninstallHooks(context *models.NestContext) ([]interfaces.Migration, error) {
+	migrationChain := migrations.MigrationChain{}
+
+	hooksDir, err := hooksDirectoryFromContext(context)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, hook := range internal.ManagedHooks {
+		status, err := internal.HookStatus(hooksDir, hook)
+		if err != nil {
+			return nil, err
+		}
+
+		if status != internal.HOOK_STATUS_INSTALLED && status != internal.HOOK_STATUS_INSTALLED_CHAINED {
+			continue
+		}
+
+		hookFile := hooksDir.SJoin(hook)
+		migrationChain.Add(fs.DeleteFile{Path: hookFile})
+
+		if status == internal.HOOK_STATUS_INSTALLED_CHAINED {
+			migrationChain.Add(fs.MoveFile{From: hooksDir.SJoin(hook + internal.ChainedHookSuffix), To: hookFile})
+		}
+	}
+
+	return migrationChain.Migrations(), nil
+}
+
+func hooksDirectoryFromContext(context *models.NestContext) (models.Path, error) {
+	if !context.IsGitInstalled {
+		return "", errors.New("git is not installed")
+	}
+
+	if context.GitRepositoryRoot.Empty() {
+		return "", errors.New("project is not located within a git repository")
+	}
+
+	return internal.HooksDirectory(context.ProjectRoot)
+}
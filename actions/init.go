@@ -0,0 +1,51 @@
+package actions
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/interfaces"
+	"github.com/jeftadlvw/git-nest/migrations"
+	mcontext "github.com/jeftadlvw/git-nest/migrations/context"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/utils"
+)
+
+/*
+InitializeProject is a high-level wrapper that creates a new git-nest project at the context's working directory.
+It also returns warnings about the project's location, e.g. if project and repository roots are not aligned.
+*/
+func InitializeProject(context *models.NestContext, useConfigDir bool, config models.Config) ([]interfaces.Migration, []string, error) {
+
+	var warnings []string
+	migrationChain := migrations.MigrationChain{}
+	projectRoot := context.WorkingDirectory
+
+	err := config.Validate()
+	if err != nil {
+		return nil, nil, fmt.Errorf("validation error: %w", err)
+	}
+
+	// refuse to initialize an existing project
+	if context.ConfigFileExists && context.ProjectRoot.Equals(projectRoot) {
+		return nil, nil, fmt.Errorf("%s is already a git-nest project (%s)", projectRoot, context.ConfigFile)
+	}
+
+	// warn if project and repository roots are not aligned
+	if context.IsGitInstalled && !config.AllowUnequalRoots {
+		gitRoot, _, err := utils.GetGitRepositoryDirectories(projectRoot)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("%s is not part of a git repository", projectRoot))
+		} else if !gitRoot.Equals(projectRoot) {
+			warnings = append(warnings, fmt.Sprintf("git-nest root and git repository root directories do not match: %s != %s\n"+
+				"git-nest will not manage git excludes. Initialize at the repository root or use --allow-unequal-roots to silence this warning.", gitRoot, projectRoot))
+		}
+	}
+
+	migrationChain.Add(mcontext.CreateConfigFile{
+		Context:      context,
+		ProjectRoot:  projectRoot,
+		UseConfigDir: useConfigDir,
+		Config:       config,
+	})
+
+	return migrationChain.Migrations(), warnings, nil
+}
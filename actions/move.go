@@ -0,0 +1,105 @@
+package actions
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/interfaces"
+	"github.com/jeftadlvw/git-nest/internal"
+	"github.com/jeftadlvw/git-nest/migrations"
+	"github.com/jeftadlvw/git-nest/migrations/fs"
+	"github.com/jeftadlvw/git-nest/migrations/submodules"
+	"github.com/jeftadlvw/git-nest/models"
+	"path/filepath"
+	"strings"
+)
+
+/*
+MoveSubmoduleInContext is a high-level wrapper that moves a submodule to a new path within the context.
+The submodule's directory is moved if it exists, and the submodule's path is updated afterward.
+Moves to paths the policy forbids are refused, and parent directories left empty by the move are deleted.
+*/
+func MoveSubmoduleInContext(context *models.NestContext, oldPath models.Path, newPath models.Path) ([]interfaces.Migration, error) {
+
+	var (
+		err            error
+		migrationChain = migrations.MigrationChain{}
+	)
+
+	oldRelativeToRoot, err := internal.PathRelativeToRootWithJoinedOriginIfNotAbs(context.ProjectRoot, context.WorkingDirectory, oldPath)
+	if err != nil {
+		return nil, fmt.Errorf("internal error: could not find relative to project root: %w", err)
+	}
+
+	// check if relative path escapes project root
+	if internal.PathContainsUp(oldRelativeToRoot) {
+		return nil, fmt.Errorf("validation error: %s escapes the project root", oldPath)
+	}
+
+	// check if passed submodule exists in context
+	var moveIndex = -1
+	for i, submodule := range context.Config.Submodules {
+		if submodule.Path.String() == oldRelativeToRoot.String() {
+			moveIndex = i
+		}
+	}
+
+	// return error if no match found
+	if moveIndex == -1 {
+		return nil, fmt.Errorf("passed submodule does not exist: %s", oldRelativeToRoot)
+	}
+
+	// if newPath has trailing separator, move submodule into that directory
+	if strings.HasSuffix(string(newPath), string(filepath.Separator)) {
+		newPath = newPath.SJoin(oldRelativeToRoot.Base())
+	}
+
+	newRelativeToRoot, err := internal.PathRelativeToRootWithJoinedOriginIfNotAbs(context.ProjectRoot, context.WorkingDirectory, newPath)
+	if err != nil {
+		return nil, fmt.Errorf("internal error: could not find relative to project root: %w", err)
+	}
+
+	// check if relative path escapes project root
+	if internal.PathContainsUp(newRelativeToRoot) {
+		return nil, fmt.Errorf("validation error: %s escapes the project root", newPath)
+	}
+
+	oldAbsolutePath := context.ProjectRoot.Join(oldRelativeToRoot)
+	newAbsolutePath := context.ProjectRoot.Join(newRelativeToRoot)
+	if newAbsolutePath.Equals(context.ProjectRoot) {
+		return nil, fmt.Errorf("validation error: path cannot be project root")
+	}
+
+	if newRelativeToRoot.String() == oldRelativeToRoot.String() {
+		return nil, fmt.Errorf("validation error: %s is already located at %s", oldPath, newRelativeToRoot)
+	}
+
+	// a module can neither be moved into itself nor into another module
+	for _, submodule := range context.Config.Submodules {
+		if !internal.PathOutsideRoot(submodule.Path, newRelativeToRoot) {
+			return nil, fmt.Errorf("validation error: %s is located within nested module %s", newPath, submodule.Path)
+		}
+	}
+
+	if newAbsolutePath.Exists() {
+		return nil, fmt.Errorf("validation error: %s already exists", newPath)
+	}
+
+	// nothing is moved if the policy forbids the new path
+	err = internal.PolicyError(internal.MovedSubmodulePolicyViolations(context.Policy, context.Config.Submodules[moveIndex], newRelativeToRoot, context.ProjectRoot))
+	if err != nil {
+		return nil, err
+	}
+
+	// move directory if it exists and delete the parent directories it leaves empty
+	if oldAbsolutePath.IsFile() {
+		return nil, fmt.Errorf("validation error: %s is a file", oldPath)
+	}
+	if oldAbsolutePath.IsDir() {
+		migrationChain.Add(fs.MoveDirectory{From: oldAbsolutePath, To: newAbsolutePath})
+		migrationChain.Add(fs.DeleteEmptyParents{Path: oldAbsolutePath, Root: context.ProjectRoot})
+	}
+
+	// update submodule path
+	migrationChain.Add(submodules.UpdatePath{Submodule: &context.Config.Submodules[moveIndex], Path: newRelativeToRoot})
+
+	return migrationChain.Migrations(), nil
+}
@@ -0,0 +1,40 @@
+package actions
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/interfaces"
+	"github.com/jeftadlvw/git-nest/internal"
+	"github.com/jeftadlvw/git-nest/migrations"
+	"github.com/jeftadlvw/git-nest/migrations/fs"
+	"github.com/jeftadlvw/git-nest/models"
+)
+
+/*
+PruneOrphanedModules is a wrapper that deletes the directories of orphaned nested modules, see internal.FindOrphanedModules.
+Like RemoveSubmoduleFromContext, no directory is deleted if any of them contains uncommitted changes or unpushed commits (unless forced).
+*/
+func PruneOrphanedModules(context *models.NestContext, orphans []internal.OrphanedModule, forceDelete bool) ([]interfaces.Migration, error) {
+	migrationChain := migrations.MigrationChain{}
+
+	for _, orphan := range orphans {
+		absolutePath := context.ProjectRoot.Join(orphan.Path)
+		if absolutePath.Equals(context.ProjectRoot) || internal.PathContainsUp(orphan.Path) {
+			return nil, fmt.Errorf("validation error: %s is not within the project root", orphan.Path)
+		}
+
+		if !absolutePath.IsDir() {
+			continue
+		}
+
+		if context.IsGitInstalled && !forceDelete {
+			err := checkRepositoryDeletable(absolutePath, orphan.Path, "git-nest prune -f")
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		migrationChain.Add(fs.DeleteDirectory{Path: absolutePath})
+	}
+
+	return migrationChain.Migrations(), nil
+}
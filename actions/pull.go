@@ -1,20 +1,38 @@
 package actions
 
 import (
+	"fmt"
 	"github.com/jeftadlvw/git-nest/interfaces"
+	"github.com/jeftadlvw/git-nest/internal"
 	"github.com/jeftadlvw/git-nest/migrations"
 	"github.com/jeftadlvw/git-nest/migrations/git"
 	"github.com/jeftadlvw/git-nest/models"
 )
 
 /*
-PullAllSubmodules is a wrapper that adds a git.Pull migration for every nested module.
+PullAllSubmodules is a wrapper that adds a git.Pull migration for every nested module that follows a branch.
+Modules that are pinned to a tag or commit are skipped, pulled commits are verified if signature verification is enabled
+and their integrity hashes are recorded if integrity recording is enabled.
 */
 func PullAllSubmodules(context *models.NestContext) ([]interfaces.Migration, error) {
 	migrationChain := migrations.MigrationChain{}
 
-	for _, submodule := range context.Config.Submodules {
-		migrationChain.Add(git.Pull{Path: submodule.Path})
+	for index, submodule := range context.Config.Submodules {
+		if submodule.Ref != "" {
+			kind := internal.SubmoduleRefKind(submodule, context.ProjectRoot.Join(submodule.Path))
+			if kind != models.RefKindBranch {
+				fmt.Printf("skipping %s: pinned to %s %s\n", submodule.Path, kind, submodule.Ref)
+				continue
+			}
+		}
+
+		migrationArr := []interfaces.Migration{git.Pull{Path: submodule.Path}}
+		migrationArr = append(migrationArr, signatureMigrations(context, &context.Config.Submodules[index])...)
+		migrationArr = append(migrationArr, integrityMigrations(context, &context.Config.Submodules[index], migrationArr)...)
+
+		for _, migration := range migrationArr {
+			migrationChain.Add(migration)
+		}
 	}
 
 	return migrationChain.Migrations(), nil
@@ -13,9 +13,10 @@ import (
 
 /*
 RemoveSubmoduleFromContext is a high-level wrapper that removes a submodule from the context.
-It also removes any existing directories if there are no changes (unless forced).
+It also removes any existing directories if there are no changes (unless forced). If keepDir is set, the directory
+is kept and recorded as kept on purpose, so that it is not pruned.
 */
-func RemoveSubmoduleFromContext(context *models.NestContext, p models.Path, removeDir bool, forceDelete bool) ([]interfaces.Migration, error) {
+func RemoveSubmoduleFromContext(context *models.NestContext, p models.Path, removeDir bool, forceDelete bool, keepDir bool) ([]interfaces.Migration, error) {
 
 	var (
 		err            error
@@ -50,31 +51,20 @@ func RemoveSubmoduleFromContext(context *models.NestContext, p models.Path, remo
 		return nil, fmt.Errorf("passed submodule does not exist: %s", relativeToRoot)
 	}
 
+	if keepDir && removeDir {
+		return nil, fmt.Errorf("validation error: directory of %s cannot be both kept and deleted", p)
+	}
+
 	// check if directory exists
-	if absolutePath.IsDir() {
+	if absolutePath.IsDir() && !keepDir {
 		if absolutePath.BContains("*") && !removeDir {
 			return nil, fmt.Errorf("submodule directry at %s is not empty.\nUse git-nest rm [path] -d to remove it.", p)
 		}
 
-		if context.IsGitInstalled {
-			// check if repository has untracked changes
-			hasUntrackedChanges, err := utils.GetGitHasUntrackedChanges(absolutePath)
-			if err != nil {
-				return nil, fmt.Errorf("internal error: could not check if uncommitted changes exist: %w", err)
-			}
-
-			if hasUntrackedChanges && !forceDelete {
-				return nil, fmt.Errorf("submodule repository at %s contains uncommitted changes.\nCommit and push your changes or use git-nest rm [path] -df to forcefully remove it.", p)
-			}
-
-			// check if repository has unpublished changes
-			hasUnpublishedChanges, err := utils.GetGitHasUnpublishedChanges(absolutePath)
+		if context.IsGitInstalled && !forceDelete {
+			err = checkRepositoryDeletable(absolutePath, p, "git-nest rm [path] -df")
 			if err != nil {
-				return nil, fmt.Errorf("internal error: could not check if unpushed commits exist: %w", err)
-			}
-
-			if hasUnpublishedChanges && !forceDelete {
-				return nil, fmt.Errorf("submodule repository at %s has unpushed commits.\nPush your changes or use git-nest rm [path] -df to forcefully remove it.", p)
+				return nil, err
 			}
 		}
 
@@ -84,5 +74,37 @@ func RemoveSubmoduleFromContext(context *models.NestContext, p models.Path, remo
 	// remove submodule from context
 	migrationChain.Add(mcontext.RemoveSubmodule{Context: context, SubmoduleIndex: removeIndex})
 
+	if keepDir && absolutePath.IsDir() {
+		migrationChain.Add(mcontext.KeepModuleDirectory{Context: context, Path: relativeToRoot})
+	}
+
 	return migrationChain.Migrations(), nil
 }
+
+/*
+checkRepositoryDeletable returns an error if a nested module's repository contains uncommitted changes or unpushed commits.
+forceHint contains the command that deletes the repository regardless.
+*/
+func checkRepositoryDeletable(repository models.Path, displayPath models.Path, forceHint string) error {
+	// check if repository has untracked changes
+	hasUntrackedChanges, err := utils.GetGitHasUntrackedChanges(repository)
+	if err != nil {
+		return fmt.Errorf("internal error: could not check if uncommitted changes exist: %w", err)
+	}
+
+	if hasUntrackedChanges {
+		return fmt.Errorf("submodule repository at %s contains uncommitted changes.\nCommit and push your changes or use %s to forcefully remove it.", displayPath, forceHint)
+	}
+
+	// check if repository has unpublished changes
+	hasUnpublishedChanges, err := utils.GetGitHasUnpublishedChanges(repository)
+	if err != nil {
+		return fmt.Errorf("internal error: could not check if unpushed commits exist: %w", err)
+	}
+
+	if hasUnpublishedChanges {
+		return fmt.Errorf("submodule repository at %s has unpushed commits.\nPush your changes or use %s to forcefully remove it.", displayPath, forceHint)
+	}
+
+	return nil
+}
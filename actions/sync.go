@@ -4,12 +4,14 @@ import (
 	"errors"
 	"fmt"
 	"github.com/jeftadlvw/git-nest/interfaces"
+	"github.com/jeftadlvw/git-nest/internal"
 	"github.com/jeftadlvw/git-nest/migrations"
 	"github.com/jeftadlvw/git-nest/migrations/git"
 	"github.com/jeftadlvw/git-nest/migrations/submodules"
 	"github.com/jeftadlvw/git-nest/models"
 	"github.com/jeftadlvw/git-nest/models/urls"
 	"github.com/jeftadlvw/git-nest/utils"
+	"slices"
 )
 
 /*
@@ -23,11 +25,20 @@ func SynchronizeConfigAndModules(context *models.NestContext) ([]interfaces.Migr
 		return nil, errors.New("unable to synchronize if git is not installed")
 	}
 
+	// no module is changed if the configuration violates the policy
+	err := internal.PolicyError(internal.PolicyViolations(context.Policy, context.Config.Submodules, context.ProjectRoot))
+	if err != nil {
+		return nil, err
+	}
+
 	for index := range len(context.Config.Submodules) {
-		migrationArr, serr := SynchronizeSubmodule(&context.Config.Submodules[index], context.ProjectRoot)
+		migrationArr, serr := SynchronizeSubmodule(&context.Config.Submodules[index], context.ProjectRoot, context.Config.Config.SshHttpsEquivalent)
 
 		if serr != nil {
 			fmt.Printf("could not synchronize nested module at position %d: %s\n", index+1, serr)
+		} else {
+			migrationArr = append(migrationArr, signatureMigrations(context, &context.Config.Submodules[index])...)
+			migrationArr = append(migrationArr, integrityMigrations(context, &context.Config.Submodules[index], migrationArr)...)
 		}
 
 		for _, migration := range migrationArr {
@@ -40,9 +51,9 @@ func SynchronizeConfigAndModules(context *models.NestContext) ([]interfaces.Migr
 
 /*
 SynchronizeSubmodule is a high level wrapper to synchronize changes between one nested module
-and an updated configuration.
+and an updated configuration. Equivalent remote urls are not treated as changes, see internal.RemoteUrlMatches.
 */
-func SynchronizeSubmodule(s *models.Submodule, projectRoot models.Path) ([]interfaces.Migration, error) {
+func SynchronizeSubmodule(s *models.Submodule, projectRoot models.Path, sshHttpsEquivalent bool) ([]interfaces.Migration, error) {
 	err := s.Validate()
 	if err != nil {
 		return nil, fmt.Errorf("valdation error: %s", err)
@@ -62,8 +73,18 @@ func SynchronizeSubmodule(s *models.Submodule, projectRoot models.Path) ([]inter
 			Url:          s.Url,
 			Path:         absolutePath.Parent(),
 			CloneDirName: s.Path.Base(),
+			RemoteName:   s.RemoteName(),
 		})
 
+		// additional remotes are added after cloning
+		for _, name := range s.RemoteNames() {
+			migrationChain.Add(git.SetRemoteUrl{
+				Path:   absolutePath,
+				Remote: name,
+				Url:    s.Remotes[name],
+			})
+		}
+
 		// and if s.Ref set, also perform checkout
 		if s.Ref != "" {
 			migrationChain.Add(git.Checkout{
@@ -72,44 +93,308 @@ func SynchronizeSubmodule(s *models.Submodule, projectRoot models.Path) ([]inter
 			})
 		}
 	} else {
-		// if s.PathS already exists check the repository's origin url
-		repositoryRemoteUrlStr, err := utils.GetGitRemoteUrl(absolutePath)
+		// if s.PathS already exists check the repository's remotes
+		repositoryRemotes, err := utils.GetGitRemotes(absolutePath)
 		if err != nil {
-			return nil, fmt.Errorf("could not get remote url: %w", err)
+			return nil, fmt.Errorf("could not get remotes: %w", err)
 		}
 
-		repositoryRemoteUrl, err := urls.HttpUrlFromString(repositoryRemoteUrlStr)
-		if err != nil {
-			return nil, fmt.Errorf("internal error: could not parse url %s: %w", repositoryRemoteUrlStr, err)
-		}
+		// a missing declared remote is created, as the repository has no url that could be used as truth
+		if !slices.Contains(repositoryRemotes, s.RemoteName()) {
+			migrationChain.Add(git.SetRemoteUrl{
+				Path:   absolutePath,
+				Remote: s.RemoteName(),
+				Url:    s.Url.String(),
+			})
+		} else if repositoryRemoteUrlStr, err := utils.GetGitRemoteUrlByName(absolutePath, s.RemoteName()); err != nil {
+			return nil, fmt.Errorf("could not get remote url: %w", err)
+		} else if !internal.RemoteUrlMatches(repositoryRemoteUrlStr, s.Url.String(), sshHttpsEquivalent) {
+			// if remote url's do not match, choose repository as truth
+			// embedded credentials are dropped when parsing and are therefore never written to the configuration
+			repositoryRemoteUrl, err := urls.HttpUrlFromString(repositoryRemoteUrlStr)
+			if err != nil {
+				return nil, fmt.Errorf("internal error: could not parse url %s: %w", urls.RedactCredentials(repositoryRemoteUrlStr), err)
+			}
 
-		// if origin url's do not match, choose repository as truth
-		if repositoryRemoteUrl.String() != s.Url.String() {
 			migrationChain.Add(submodules.UpdateUrl{
 				Submodule: s,
 				Url:       repositoryRemoteUrl,
 			})
 		}
 
-		// check the repository's head
-		repositoryHeadLong, repositoryHeadAbbrev, err := utils.GetGitFetchHead(absolutePath)
+		// additional remotes are always created or repaired
+		remoteMigrationArr, err := additionalRemoteMigrations(s, absolutePath, repositoryRemotes, sshHttpsEquivalent)
 		if err != nil {
-			return nil, fmt.Errorf("could not get head: %w", err)
+			return nil, err
+		}
+		for _, migration := range remoteMigrationArr {
+			migrationChain.Add(migration)
 		}
 
-		repositoryHead := repositoryHeadAbbrev
-		if repositoryHead == "" {
-			repositoryHead = repositoryHeadLong
+		// check the repository's head
+		head, err := internal.GetSubmoduleHead(absolutePath)
+		if err != nil {
+			return nil, fmt.Errorf("could not get head: %w", err)
 		}
 
 		// if the heads do not match, choose repository head as truth (== set submodule ref)
-		if repositoryHead != s.Ref {
+		// a tag pin is kept as long as the module is detached at the tag
+		if s.Ref == "" || !internal.SubmoduleHeadMatches(*s, absolutePath, head) {
+			ref, kind := head.Ref()
+			if kind == models.RefKindTag && s.RefKind == models.RefKindCommit {
+				ref, kind = head.Commit, models.RefKindCommit
+			}
+
+			// undeclared kinds remain detected
+			if s.RefKind == models.RefKindAuto {
+				kind = models.RefKindAuto
+			}
+
 			migrationChain.Add(submodules.UpdateRef{
 				Submodule: s,
-				Ref:       repositoryHead,
+				Ref:       ref,
+				RefKind:   kind,
 			})
 		}
 	}
 
 	return migrationChain.Migrations(), nil
 }
+
+/*
+ApplyConfigToModules is a high level wrapper for applying the configuration to all nested modules.
+In contrast to SynchronizeConfigAndModules, the configuration is treated as the truth and nested modules
+are changed to match it.
+*/
+func ApplyConfigToModules(context *models.NestContext) ([]interfaces.Migration, error) {
+	return applyConfigToModules(context, false)
+}
+
+/*
+ApplyConfigToModulesOffline is like ApplyConfigToModules, but never contacts a remote, e.g. after modules were restored
+from an offline bundle. In contrast to ApplyConfigToModules, it fails if any nested module is missing or if its ref
+does not exist locally.
+*/
+func ApplyConfigToModulesOffline(context *models.NestContext) ([]interfaces.Migration, error) {
+	return applyConfigToModules(context, true)
+}
+
+func applyConfigToModules(context *models.NestContext, offline bool) ([]interfaces.Migration, error) {
+	migrationChain := migrations.MigrationChain{}
+
+	if !context.IsGitInstalled {
+		return nil, errors.New("unable to synchronize if git is not installed")
+	}
+
+	// no module is changed if the configuration violates the policy
+	err := internal.PolicyError(internal.PolicyViolations(context.Policy, context.Config.Submodules, context.ProjectRoot))
+	if err != nil {
+		return nil, err
+	}
+
+	for index := range len(context.Config.Submodules) {
+		migrationArr, serr := applyConfigToSubmodule(&context.Config.Submodules[index], context.ProjectRoot, context.Config.Config.SshHttpsEquivalent, offline)
+
+		if serr != nil && offline {
+			return nil, fmt.Errorf("nested module %s: %w", context.Config.Submodules[index].Path, serr)
+		} else if serr != nil {
+			fmt.Printf("could not synchronize nested module at position %d: %s\n", index+1, serr)
+		} else {
+			migrationArr = append(migrationArr, signatureMigrations(context, &context.Config.Submodules[index])...)
+			migrationArr = append(migrationArr, integrityMigrations(context, &context.Config.Submodules[index], migrationArr)...)
+		}
+
+		for _, migration := range migrationArr {
+			migrationChain.Add(migration)
+		}
+	}
+
+	return migrationChain.Migrations(), nil
+}
+
+/*
+ApplyConfigToSubmodule is a high level wrapper to change one nested module to match its configuration.
+Missing modules are cloned, mismatching origin urls are reset and the configured ref is checked out.
+*/
+func ApplyConfigToSubmodule(s *models.Submodule, projectRoot models.Path, sshHttpsEquivalent bool) ([]interfaces.Migration, error) {
+	return applyConfigToSubmodule(s, projectRoot, sshHttpsEquivalent, false)
+}
+
+/*
+applyConfigToSubmodule implements ApplyConfigToSubmodule. If offline is set, missing modules and refs are not fetched
+from a remote, but result in an error.
+*/
+func applyConfigToSubmodule(s *models.Submodule, projectRoot models.Path, sshHttpsEquivalent bool, offline bool) ([]interfaces.Migration, error) {
+	err := s.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("valdation error: %s", err)
+	}
+
+	migrationChain := migrations.MigrationChain{}
+	absolutePath := projectRoot.Join(s.Path)
+
+	if absolutePath.IsFile() {
+		return nil, fmt.Errorf("%s is a file", s.Path)
+	}
+
+	// missing modules are created the same way regular synchronization does
+	if !absolutePath.Exists() && offline {
+		return nil, fmt.Errorf("%s does not exist and cannot be cloned offline", s.Path)
+	}
+	if !absolutePath.Exists() {
+		return SynchronizeSubmodule(s, projectRoot, sshHttpsEquivalent)
+	}
+
+	// create or reset the declared remote and all additional remotes if they do not match
+	// equivalent remote urls or urls that point to a rewritten url are not reset
+	repositoryRemotes, err := utils.GetGitRemotes(absolutePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not get remotes: %w", err)
+	}
+
+	remoteMigrationArr, err := remoteMigrations(absolutePath, repositoryRemotes, s.RemoteName(), s.Url.String(), sshHttpsEquivalent)
+	if err != nil {
+		return nil, err
+	}
+	for _, migration := range remoteMigrationArr {
+		migrationChain.Add(migration)
+	}
+
+	remoteMigrationArr, err = additionalRemoteMigrations(s, absolutePath, repositoryRemotes, sshHttpsEquivalent)
+	if err != nil {
+		return nil, err
+	}
+	for _, migration := range remoteMigrationArr {
+		migrationChain.Add(migration)
+	}
+
+	if s.Ref == "" {
+		return migrationChain.Migrations(), nil
+	}
+
+	// check if the repository's head already matches the configured ref
+	head, err := internal.GetSubmoduleHead(absolutePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not get head: %w", err)
+	}
+
+	if internal.SubmoduleHeadMatches(*s, absolutePath, head) {
+		return migrationChain.Migrations(), nil
+	}
+
+	// never discard local work
+	hasUntrackedChanges, err := utils.GetGitHasUntrackedChanges(absolutePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not check if uncommitted changes exist: %w", err)
+	}
+	if hasUntrackedChanges {
+		return nil, fmt.Errorf("%s contains uncommitted changes, not changing ref to %s", s.Path, s.Ref)
+	}
+
+	// ref might have been created after the module was cloned
+	if _, err := utils.GetGitRefKind(absolutePath, s.Ref, s.RemoteName()); err != nil && offline {
+		return nil, fmt.Errorf("ref %s does not exist locally and cannot be fetched offline: %w", s.Ref, err)
+	} else if err != nil {
+		migrationChain.Add(git.Fetch{Path: absolutePath, Remote: s.RemoteName()})
+	}
+
+	// tags are checked out by their full name, so that branches with the same name are not checked out instead
+	checkoutRef := s.Ref
+	if s.RefKind == models.RefKindTag {
+		checkoutRef = "refs/tags/" + s.Ref
+	}
+
+	migrationChain.Add(git.Checkout{
+		Path: absolutePath,
+		Ref:  checkoutRef,
+	})
+
+	return migrationChain.Migrations(), nil
+}
+
+/*
+signatureMigrations returns a migration that verifies the signature of a nested module's checked out ref
+after it was synchronized, if signature verification is enabled for the module.
+*/
+func signatureMigrations(context *models.NestContext, s *models.Submodule) []interfaces.Migration {
+	verification := context.Config.Config.SignatureVerification(*s, context.ProjectRoot)
+	if !verification.Enabled {
+		return nil
+	}
+
+	return []interfaces.Migration{git.VerifySignature{
+		Path:         context.ProjectRoot.Join(s.Path),
+		Submodule:    s,
+		Verification: verification,
+	}}
+}
+
+/*
+integrityMigrations returns a migration that records the integrity hashes of a nested module if integrity recording
+is enabled and the passed migrations change the module's content by cloning, checking out or pulling it.
+Hashes are never recorded from an unchanged worktree, as that would accept any modification made to it.
+*/
+func integrityMigrations(context *models.NestContext, s *models.Submodule, migrationArr []interfaces.Migration) []interfaces.Migration {
+	if !context.Config.Config.RecordIntegrity {
+		return nil
+	}
+
+	changesContent := slices.ContainsFunc(migrationArr, func(migration interfaces.Migration) bool {
+		switch migration.(type) {
+		case git.Clone, git.Checkout, git.Pull:
+			return true
+		}
+		return false
+	})
+	if !changesContent {
+		return nil
+	}
+
+	return []interfaces.Migration{submodules.UpdateIntegrity{
+		Submodule: s,
+		Path:      context.ProjectRoot.Join(s.Path),
+	}}
+}
+
+/*
+additionalRemoteMigrations returns the migrations that create or repair a Submodule's additional remotes.
+*/
+func additionalRemoteMigrations(s *models.Submodule, repository models.Path, repositoryRemotes []string, sshHttpsEquivalent bool) ([]interfaces.Migration, error) {
+	var remoteMigrationArr []interfaces.Migration
+
+	for _, name := range s.RemoteNames() {
+		migrationArr, err := remoteMigrations(repository, repositoryRemotes, name, s.Remotes[name], sshHttpsEquivalent)
+		if err != nil {
+			return nil, err
+		}
+		remoteMigrationArr = append(remoteMigrationArr, migrationArr...)
+	}
+
+	return remoteMigrationArr, nil
+}
+
+/*
+remoteMigrations returns the migrations that create a remote or reset its url if it does not match the configured url.
+*/
+func remoteMigrations(repository models.Path, repositoryRemotes []string, name string, configuredUrl string, sshHttpsEquivalent bool) ([]interfaces.Migration, error) {
+	setRemoteUrl := git.SetRemoteUrl{
+		Path:   repository,
+		Remote: name,
+		Url:    configuredUrl,
+	}
+
+	if !slices.Contains(repositoryRemotes, name) {
+		return []interfaces.Migration{setRemoteUrl}, nil
+	}
+
+	remoteUrl, err := utils.GetGitRemoteUrlByName(repository, name)
+	if err != nil {
+		return nil, fmt.Errorf("could not get url of remote %s: %w", name, err)
+	}
+
+	if internal.RemoteUrlMatches(remoteUrl, configuredUrl, sshHttpsEquivalent) {
+		return nil, nil
+	}
+
+	return []interfaces.Migration{setRemoteUrl}, nil
+}
@@ -63,11 +63,11 @@ func TestAddSubmoduleInContext(t *testing.T) {
 		{test_env.RepoUrl, "/foo", "", []models.Submodule{}, false, false, nil, true},
 		{test_env.RepoUrl, "/../foo", "", []models.Submodule{}, false, false, nil, true},
 		{test_env.RepoUrl, "foo", test_env.RepoBranch1, []models.Submodule{}, false, false, expectedMigrationsRef, false},
-		{test_env.RepoUrl, "", "", []models.Submodule{{"example-repository", &testRepoUrl, ""}}, false, false, nil, true},
-		{test_env.RepoUrl, "", "", []models.Submodule{{"example-repository", &testRepoUrl, ""}}, false, true, nil, true},
-		{test_env.RepoUrl, "", test_env.RepoBranch1, []models.Submodule{{"foo", &testRepoUrl, ""}}, false, false, nil, true},
-		{test_env.RepoUrl, "", test_env.RepoBranch1, []models.Submodule{{"foo", &testRepoUrl, ""}}, false, true, expectedMigrationsRef, false},
-		{test_env.RepoUrl, "", test_env.RepoBranch1, []models.Submodule{{"foo", &testRepoUrl, test_env.RepoBranch1}}, false, true, expectedMigrationsRef, false},
+		{test_env.RepoUrl, "", "", []models.Submodule{{Path: "example-repository", Url: &testRepoUrl, Ref: ""}}, false, false, nil, true},
+		{test_env.RepoUrl, "", "", []models.Submodule{{Path: "example-repository", Url: &testRepoUrl, Ref: ""}}, false, true, nil, true},
+		{test_env.RepoUrl, "", test_env.RepoBranch1, []models.Submodule{{Path: "foo", Url: &testRepoUrl, Ref: ""}}, false, false, nil, true},
+		{test_env.RepoUrl, "", test_env.RepoBranch1, []models.Submodule{{Path: "foo", Url: &testRepoUrl, Ref: ""}}, false, true, expectedMigrationsRef, false},
+		{test_env.RepoUrl, "", test_env.RepoBranch1, []models.Submodule{{Path: "foo", Url: &testRepoUrl, Ref: test_env.RepoBranch1}}, false, true, expectedMigrationsRef, false},
 	}
 
 	for index, tc := range cases {
@@ -150,3 +150,44 @@ func TestAddSubmoduleInContext(t *testing.T) {
 		})
 	}
 }
+
+func TestAddSubmoduleInContextPolicy(t *testing.T) {
+	testRepoUrl, err := urls.HttpUrlFromString(test_env.RepoUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		policy   models.Policy
+		cloneDir string
+		err      bool
+	}{
+		{models.Policy{}, "", false},
+		{models.Policy{AllowedHosts: []string{"github.com"}}, "", false},
+		{models.Policy{AllowedHosts: []string{"*.example.com"}}, "", true},
+		{models.Policy{AllowedUrls: []string{"https://github.com/jeftadlvw/*"}}, "", false},
+		{models.Policy{AllowedUrls: []string{"https://github.com/other/*"}}, "", true},
+		{models.Policy{ForbiddenPaths: []string{"vendor"}}, "lib/", false},
+		{models.Policy{ForbiddenPaths: []string{"vendor"}}, "vendor/", true},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestAddSubmoduleInContextPolicy-%d", index+1), func(t *testing.T) {
+			t.Parallel()
+
+			root := models.Path(t.TempDir())
+			context := models.NestContext{WorkingDirectory: root, ProjectRoot: root, IsGitInstalled: true, Policy: tc.policy}
+
+			migrationArr, err := actions.AddSubmoduleInContext(&context, testRepoUrl, "", models.Path(tc.cloneDir))
+			if tc.err && err == nil {
+				t.Fatalf("no error, but expected one")
+			}
+			if !tc.err && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if tc.err && len(migrationArr) != 0 {
+				t.Fatalf("expected no migrations if the policy is violated")
+			}
+		})
+	}
+}
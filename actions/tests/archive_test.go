@@ -0,0 +1,329 @@
+package tests
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"github.com/jeftadlvw/git-nest/actions"
+	"github.com/jeftadlvw/git-nest/internal"
+	"github.com/jeftadlvw/git-nest/internal/constants"
+	"github.com/jeftadlvw/git-nest/migrations"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/models/urls"
+	"github.com/jeftadlvw/git-nest/test_env"
+	"github.com/jeftadlvw/git-nest/utils"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestArchiveProject(t *testing.T) {
+	testRepoUrl, err := urls.HttpUrlFromString(test_env.RepoUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		output string
+		format string
+		err    bool
+	}{
+		{"out.tar.gz", "", false},
+		{"out.tgz", "", false},
+		{"out.tar", "", false},
+		{"out.zip", "", false},
+		{"out", "", false},
+		{"out.bin", internal.ArchiveFormatZip, false},
+		{"out.tar.gz", "rar", true},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestArchiveProject-%d", index+1), func(t *testing.T) {
+			t.Parallel()
+
+			root := models.Path(t.TempDir())
+			err := test_env.CreateLocalRepository(root)
+			if err != nil {
+				t.Fatalf("error creating project repository: %s", err)
+			}
+			commitArchiveFile(t, root, "README.md", "project")
+
+			modulePath := root.SJoin("lib", "module")
+			err = test_env.CreateLocalRepository(modulePath)
+			if err != nil {
+				t.Fatalf("error creating module repository: %s", err)
+			}
+			commitArchiveFile(t, modulePath, "module.txt", "v1")
+			out, err := utils.RunCommandCombinedOutput(modulePath, "git", "tag", "v1")
+			if err != nil {
+				t.Fatalf("error creating tag: %s; %s", err, out)
+			}
+			commitArchiveFile(t, modulePath, "module.txt", "v2")
+
+			// neither uncommitted files of the project nor of nested modules are archived
+			for _, p := range []string{filepath.Join(string(root), "untracked.txt"), filepath.Join(string(modulePath), "untracked.txt")} {
+				err = os.WriteFile(p, []byte("untracked"), 0644)
+				if err != nil {
+					t.Fatalf("error writing untracked file: %s", err)
+				}
+			}
+
+			context := models.NestContext{ProjectRoot: root, IsGitInstalled: true}
+			context.Config.Submodules = []models.Submodule{{Path: "lib/module", Url: &testRepoUrl, Ref: "v1"}}
+
+			output := models.Path(filepath.Join(t.TempDir(), tc.output))
+			migrationArr, err := actions.ArchiveProject(&context, output, tc.format, nil)
+			if err == nil {
+				err = migrations.RunMigrations(migrationArr...)
+			}
+
+			if tc.err {
+				if err == nil {
+					t.Fatalf("expected error")
+				}
+				if output.Exists() {
+					t.Fatalf("partial archive %s was not removed", output)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			format := tc.format
+			if format == "" {
+				format = internal.ArchiveFormatFromPath(tc.output)
+			}
+
+			files := readArchiveFiles(t, output, format)
+			expected := map[string]string{
+				"README.md":             "project",
+				"lib/module/module.txt": "v1",
+			}
+			for name, content := range expected {
+				if files[name] != content {
+					t.Fatalf("unexpected content of %s: %q, expected %q", name, files[name], content)
+				}
+			}
+			if len(files) != len(expected)+1 {
+				t.Fatalf("unexpected files in archive: %d, expected %d", len(files), len(expected)+1)
+			}
+
+			manifest := internal.ArchiveManifest{}
+			err = json.Unmarshal([]byte(files[constants.ArchiveManifestFileName]), &manifest)
+			if err != nil {
+				t.Fatalf("error parsing manifest: %s", err)
+			}
+
+			projectCommit, _ := utils.GetGitRefCommit(root, "HEAD")
+			moduleCommit, _ := utils.GetGitRefCommit(modulePath, "refs/tags/v1")
+			if manifest.Commit != projectCommit {
+				t.Fatalf("unexpected project commit %s, expected %s", manifest.Commit, projectCommit)
+			}
+			if len(manifest.Modules) != 1 || manifest.Modules[0].Path != "lib/module" || manifest.Modules[0].Commit != moduleCommit || manifest.Modules[0].Ref != "v1" {
+				t.Fatalf("unexpected manifest modules: %+v", manifest.Modules)
+			}
+		})
+	}
+}
+
+func TestArchiveProjectMissingModule(t *testing.T) {
+	root := models.Path(t.TempDir())
+	err := test_env.CreateLocalRepository(root, "first")
+	if err != nil {
+		t.Fatalf("error creating project repository: %s", err)
+	}
+
+	context := models.NestContext{ProjectRoot: root, IsGitInstalled: true}
+	context.Config.Submodules = []models.Submodule{{Path: "module", Ref: "v1"}}
+
+	_, err = actions.ArchiveProject(&context, models.Path(filepath.Join(t.TempDir(), "out.zip")), "", nil)
+	if err == nil {
+		t.Fatalf("expected error for missing nested module")
+	}
+}
+
+func TestArchiveProjectGroupsAndSparsePaths(t *testing.T) {
+	testRepoUrl, err := urls.HttpUrlFromString(test_env.RepoUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root := models.Path(t.TempDir())
+	err = test_env.CreateLocalRepository(root)
+	if err != nil {
+		t.Fatalf("error creating project repository: %s", err)
+	}
+	commitArchiveFile(t, root, "README.md", "project")
+
+	for _, module := range []string{"frontend", "backend"} {
+		modulePath := root.SJoin(module)
+		err = test_env.CreateLocalRepository(modulePath)
+		if err != nil {
+			t.Fatalf("error creating module repository: %s", err)
+		}
+		commitArchiveFile(t, modulePath, "module.txt", module)
+		commitArchiveFile(t, modulePath, "docs.txt", "docs")
+	}
+
+	submodules := []models.Submodule{
+		{Path: "frontend", Url: &testRepoUrl, Groups: []string{"web"}},
+		{Path: "backend", Url: &testRepoUrl, Groups: []string{"server"}, SparsePaths: []string{"module.txt"}},
+	}
+
+	cases := []struct {
+		groups   []string
+		expected map[string]string
+		sparse   map[string][]string
+		err      bool
+	}{
+		{nil, map[string]string{"README.md": "project", "frontend/module.txt": "frontend", "frontend/docs.txt": "docs", "backend/module.txt": "backend"}, map[string][]string{"backend": {"module.txt"}}, false},
+		{[]string{"web"}, map[string]string{"README.md": "project", "frontend/module.txt": "frontend", "frontend/docs.txt": "docs"}, nil, false},
+		{[]string{"server"}, map[string]string{"README.md": "project", "backend/module.txt": "backend"}, map[string][]string{"backend": {"module.txt"}}, false},
+		{[]string{"web", "server"}, map[string]string{"README.md": "project", "frontend/module.txt": "frontend", "frontend/docs.txt": "docs", "backend/module.txt": "backend"}, map[string][]string{"backend": {"module.txt"}}, false},
+		{[]string{"unknown"}, nil, nil, true},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestArchiveProjectGroupsAndSparsePaths-%d", index+1), func(t *testing.T) {
+			context := models.NestContext{ProjectRoot: root, IsGitInstalled: true}
+			context.Config.Submodules = submodules
+
+			output := models.Path(filepath.Join(t.TempDir(), "out.tar"))
+			migrationArr, err := actions.ArchiveProject(&context, output, "", tc.groups)
+			if err == nil {
+				err = migrations.RunMigrations(migrationArr...)
+			}
+
+			if tc.err {
+				if err == nil {
+					t.Fatalf("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			files := readArchiveFiles(t, output, internal.ArchiveFormatTar)
+			for name, content := range tc.expected {
+				if files[name] != content {
+					t.Fatalf("unexpected content of %s: %q, expected %q", name, files[name], content)
+				}
+			}
+			if len(files) != len(tc.expected)+1 {
+				t.Fatalf("unexpected files in archive: %v, expected %v", files, tc.expected)
+			}
+
+			manifest := internal.ArchiveManifest{}
+			err = json.Unmarshal([]byte(files[constants.ArchiveManifestFileName]), &manifest)
+			if err != nil {
+				t.Fatalf("error parsing manifest: %s", err)
+			}
+
+			for _, module := range manifest.Modules {
+				if strings.Join(module.SparsePaths, ",") != strings.Join(tc.sparse[module.Path], ",") {
+					t.Fatalf("unexpected sparse paths of %s in manifest: %v", module.Path, module.SparsePaths)
+				}
+			}
+			if strings.Join(manifest.Groups, ",") != strings.Join(tc.groups, ",") {
+				t.Fatalf("unexpected groups in manifest: %v, expected %v", manifest.Groups, tc.groups)
+			}
+		})
+	}
+}
+
+func commitArchiveFile(t *testing.T, repository models.Path, name string, content string) {
+	err := os.WriteFile(filepath.Join(string(repository), name), []byte(content), 0644)
+	if err != nil {
+		t.Fatalf("error writing %s: %s", name, err)
+	}
+
+	for _, args := range [][]string{{"add", name}, {"commit", "-m", "update " + name}} {
+		out, err := utils.RunCommandCombinedOutput(repository, "git", args...)
+		if err != nil {
+			t.Fatalf("error running git %s: %s; %s", args[0], err, out)
+		}
+	}
+}
+
+func readArchiveFiles(t *testing.T, output models.Path, format string) map[string]string {
+	files := make(map[string]string)
+
+	switch format {
+	case internal.ArchiveFormatDirectory:
+		err := filepath.WalkDir(string(output), func(p string, d os.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			content, err := os.ReadFile(p)
+			if err != nil {
+				return err
+			}
+			relative, _ := filepath.Rel(string(output), p)
+			files[filepath.ToSlash(relative)] = string(content)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("error reading directory: %s", err)
+		}
+
+	case internal.ArchiveFormatZip:
+		zr, err := zip.OpenReader(string(output))
+		if err != nil {
+			t.Fatalf("error opening zip archive: %s", err)
+		}
+		defer zr.Close()
+
+		for _, f := range zr.File {
+			if f.FileInfo().IsDir() {
+				continue
+			}
+			r, err := f.Open()
+			if err != nil {
+				t.Fatalf("error opening %s: %s", f.Name, err)
+			}
+			content, _ := io.ReadAll(r)
+			_ = r.Close()
+			files[f.Name] = string(content)
+		}
+
+	default:
+		f, err := os.Open(string(output))
+		if err != nil {
+			t.Fatalf("error opening archive: %s", err)
+		}
+		defer f.Close()
+
+		var r io.Reader = f
+		if format == internal.ArchiveFormatTarGz {
+			gz, err := gzip.NewReader(f)
+			if err != nil {
+				t.Fatalf("error opening gzip stream: %s", err)
+			}
+			r = gz
+		}
+
+		tr := tar.NewReader(r)
+		for {
+			header, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("error reading tar archive: %s", err)
+			}
+			if header.Typeflag != tar.TypeReg {
+				continue
+			}
+			content, _ := io.ReadAll(tr)
+			files[header.Name] = string(content)
+		}
+	}
+
+	return files
+}
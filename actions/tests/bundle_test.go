@@ -0,0 +1,148 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/actions"
+	"github.com/jeftadlvw/git-nest/internal"
+	"github.com/jeftadlvw/git-nest/migrations"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/models/urls"
+	"github.com/jeftadlvw/git-nest/test_env"
+	"github.com/jeftadlvw/git-nest/utils"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCreateAndRestoreBundle(t *testing.T) {
+	testRepoUrl, err := urls.HttpUrlFromString(test_env.RepoUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		ref        string
+		remoteName string
+		restore    []string
+		restoreRef string
+		err        bool
+		applyErr   bool
+	}{
+		{"v1", "", []string{"lib/module"}, "v1", false, false},
+		{test_env.RepoBranchDefault, "", []string{"lib/module"}, test_env.RepoBranchDefault, false, false},
+		{"v1", "upstream", []string{"lib/module"}, "v1", false, false},
+		{"", "", []string{"lib/module"}, "", false, false},
+		{"v1", "", []string{"lib/module", "lib/other"}, "v1", true, false},
+		{"v1", "", []string{"lib/module"}, "v2", false, true},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestCreateAndRestoreBundle-%d", index+1), func(t *testing.T) {
+			t.Parallel()
+
+			// create a bundle from a project with one nested module
+			root := models.Path(t.TempDir())
+			configFile := root.SJoin("nestmodules.toml")
+			err := os.WriteFile(configFile.String(), []byte("# bundled\n"), 0644)
+			if err != nil {
+				t.Fatalf("error writing config file: %s", err)
+			}
+
+			modulePath := root.SJoin("lib", "module")
+			err = test_env.CreateLocalRepository(modulePath, "first")
+			if err != nil {
+				t.Fatalf("error creating module repository: %s", err)
+			}
+			for _, args := range [][]string{{"tag", "v1"}, {"commit", "--allow-empty", "-m", "second"}} {
+				out, err := utils.RunCommandCombinedOutput(modulePath, "git", args...)
+				if err != nil {
+					t.Fatalf("error running git %s: %s; %s", args[0], err, out)
+				}
+			}
+
+			expectedRef := tc.ref
+			if expectedRef == "" {
+				expectedRef = "HEAD"
+			}
+			expectedCommit, err := utils.GetGitRefCommit(modulePath, expectedRef)
+			if err != nil {
+				t.Fatalf("error resolving %s: %s", expectedRef, err)
+			}
+
+			context := models.NestContext{ProjectRoot: root, ConfigFile: configFile, ConfigFileExists: true, IsGitInstalled: true}
+			context.Config.Submodules = []models.Submodule{{Path: "lib/module", Url: &testRepoUrl, Ref: tc.ref, Remote: tc.remoteName}}
+
+			bundle := models.Path(filepath.Join(t.TempDir(), "project.bundle.tar.gz"))
+			migrationArr, err := actions.CreateBundle(&context, bundle)
+			if err != nil {
+				t.Fatalf("unexpected error creating bundle: %s", err)
+			}
+			err = migrations.RunMigrations(migrationArr...)
+			if err != nil {
+				t.Fatalf("unexpected error writing bundle: %s", err)
+			}
+
+			dir := models.Path(t.TempDir())
+			manifest, err := internal.ExtractBundle(bundle, dir)
+			if err != nil {
+				t.Fatalf("unexpected error extracting bundle: %s", err)
+			}
+			config, err := utils.ReadFileToStr(dir.SJoin("nestmodules.toml"))
+			if err != nil || config != "# bundled\n" {
+				t.Fatalf("unexpected bundled config %q: %v", config, err)
+			}
+
+			// restore the bundle into an empty project
+			restoreRoot := models.Path(t.TempDir())
+			restoreContext := models.NestContext{ProjectRoot: restoreRoot, IsGitInstalled: true}
+			for _, p := range tc.restore {
+				restoreContext.Config.Submodules = append(restoreContext.Config.Submodules, models.Submodule{Path: models.Path(p), Url: &testRepoUrl, Ref: tc.restoreRef, Remote: tc.remoteName})
+			}
+
+			migrationArr, err = actions.RestoreBundle(&restoreContext, dir, manifest)
+			if tc.err {
+				if err == nil {
+					t.Fatalf("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error restoring bundle: %s", err)
+			}
+
+			err = migrations.RunMigrations(migrationArr...)
+			if err != nil {
+				t.Fatalf("unexpected error running restore migrations: %s", err)
+			}
+
+			// refs that are not contained in the bundle are never fetched
+			migrationArr, err = actions.ApplyConfigToModulesOffline(&restoreContext)
+			if tc.applyErr {
+				if err == nil || !strings.Contains(err.Error(), "cannot be fetched offline") {
+					t.Fatalf("expected offline error applying config: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error applying config: %s", err)
+			}
+			err = migrations.RunMigrations(migrationArr...)
+			if err != nil {
+				t.Fatalf("unexpected error running apply migrations: %s", err)
+			}
+
+			restoredPath := restoreRoot.SJoin("lib", "module")
+			commit, err := utils.GetGitRefCommit(restoredPath, "HEAD")
+			if err != nil || commit != expectedCommit {
+				t.Fatalf("unexpected restored commit %s, expected %s: %v", commit, expectedCommit, err)
+			}
+
+			remoteName := restoreContext.Config.Submodules[0].RemoteName()
+			remoteUrl, err := utils.GetGitRemoteUrlByName(restoredPath, remoteName)
+			if err != nil || remoteUrl != testRepoUrl.String() {
+				t.Fatalf("unexpected url %s of remote %s: %v", remoteUrl, remoteName, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/actions"
+	"github.com/jeftadlvw/git-nest/internal"
+	"github.com/jeftadlvw/git-nest/migrations"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/test_env"
+	"github.com/jeftadlvw/git-nest/utils"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestInstallAndUninstallHooks(t *testing.T) {
+
+	const userHookContent = "#!/bin/sh\necho user hook\n"
+
+	cases := []struct {
+		hooksPath      string
+		userHook       string
+		projectSubDir  string
+		installTwice   bool
+		expectedStatus int
+	}{
+		{"", "", "", false, internal.HOOK_STATUS_INSTALLED},
+		{"", "", "", true, internal.HOOK_STATUS_INSTALLED},
+		{"", "post-merge", "", false, internal.HOOK_STATUS_INSTALLED_CHAINED},
+		{"", "post-merge", "", true, internal.HOOK_STATUS_INSTALLED_CHAINED},
+		{".githooks", "", "", false, internal.HOOK_STATUS_INSTALLED},
+		{".githooks", "post-merge", "", false, internal.HOOK_STATUS_INSTALLED_CHAINED},
+		{"", "", "sub dir", false, internal.HOOK_STATUS_INSTALLED},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestInstallAndUninstallHooks-%d", index+1), func(t *testing.T) {
+			t.Parallel()
+
+			repoDir := models.Path(t.TempDir())
+			err := test_env.CreateLocalRepository(repoDir)
+			if err != nil {
+				t.Fatalf("error creating repository: %s", err)
+			}
+
+			if tc.hooksPath != "" {
+				out, err := utils.RunCommandCombinedOutput(repoDir, "git", "config", "core.hooksPath", tc.hooksPath)
+				if err != nil {
+					t.Fatalf("error setting hooks path: %s; %s", err, out)
+				}
+			}
+
+			hooksDir, err := internal.HooksDirectory(repoDir)
+			if err != nil {
+				t.Fatalf("error evaluating hooks directory: %s", err)
+			}
+			expectedHooksDir := repoDir.SJoin(tc.hooksPath)
+			if tc.hooksPath != "" && hooksDir.String() != expectedHooksDir.String() {
+				t.Fatalf("hooks directory does not respect core.hooksPath: %s", hooksDir)
+			}
+
+			if tc.userHook != "" {
+				err = os.MkdirAll(hooksDir.String(), os.ModePerm)
+				if err != nil {
+					t.Fatalf("error creating hooks directory: %s", err)
+				}
+				userHookFile := hooksDir.SJoin(tc.userHook)
+				err = os.WriteFile(userHookFile.String(), []byte(userHookContent), 0755)
+				if err != nil {
+					t.Fatalf("error writing user hook: %s", err)
+				}
+			}
+
+			projectRoot := repoDir
+			if tc.projectSubDir != "" {
+				projectRoot = repoDir.SJoin(tc.projectSubDir)
+				err = os.Mkdir(projectRoot.String(), os.ModePerm)
+				if err != nil {
+					t.Fatalf("error creating project directory: %s", err)
+				}
+			}
+
+			context, err := internal.CreateContext(projectRoot)
+			if err != nil {
+				t.Fatalf("error creating context: %s", err)
+			}
+
+			installCount := 1
+			if tc.installTwice {
+				installCount = 2
+			}
+
+			for range installCount {
+				migrationArr, err := actions.InstallHooks(&context, internal.ManagedHooks)
+				if err != nil {
+					t.Fatalf("unexpected error installing hooks: %s", err)
+				}
+
+				err = migrations.RunMigrations(migrationArr...)
+				if err != nil {
+					t.Fatalf("unexpected error running migrations: %s", err)
+				}
+			}
+
+			// check installed hooks
+			for _, hook := range internal.ManagedHooks {
+				status, err := internal.HookStatus(hooksDir, hook)
+				if err != nil {
+					t.Fatalf("unexpected error reading hook status: %s", err)
+				}
+
+				expectedStatus := internal.HOOK_STATUS_INSTALLED
+				if hook == tc.userHook {
+					expectedStatus = tc.expectedStatus
+				}
+				if status != expectedStatus {
+					t.Fatalf("unexpected status for %s: %d, expected %d", hook, status, expectedStatus)
+				}
+
+				hookFile := hooksDir.SJoin(hook)
+				info, err := os.Stat(hookFile.String())
+				if err != nil {
+					t.Fatalf("error reading hook file info: %s", err)
+				}
+				if info.Mode().Perm()&0100 == 0 {
+					t.Fatalf("hook %s is not executable", hook)
+				}
+
+				content, err := utils.ReadFileToStr(hookFile)
+				if err != nil {
+					t.Fatalf("error reading hook: %s", err)
+				}
+				if tc.projectSubDir != "" && !strings.Contains(content, fmt.Sprintf("cd '%s'", tc.projectSubDir)) {
+					t.Fatalf("hook does not change into project directory:\n%s", content)
+				}
+			}
+
+			// uninstall hooks
+			migrationArr, err := actions.UninstallHooks(&context)
+			if err != nil {
+				t.Fatalf("unexpected error uninstalling hooks: %s", err)
+			}
+
+			err = migrations.RunMigrations(migrationArr...)
+			if err != nil {
+				t.Fatalf("unexpected error running migrations: %s", err)
+			}
+
+			for _, hook := range internal.ManagedHooks {
+				status, err := internal.HookStatus(hooksDir, hook)
+				if err != nil {
+					t.Fatalf("unexpected error reading hook status: %s", err)
+				}
+
+				expectedStatus := internal.HOOK_STATUS_NOT_INSTALLED
+				if hook == tc.userHook {
+					expectedStatus = internal.HOOK_STATUS_FOREIGN
+				}
+				if status != expectedStatus {
+					t.Fatalf("unexpected status after uninstall for %s: %d, expected %d", hook, status, expectedStatus)
+				}
+			}
+
+			if tc.userHook != "" {
+				content, err := utils.ReadFileToStr(hooksDir.SJoin(tc.userHook))
+				if err != nil {
+					t.Fatalf("error reading restored user hook: %s", err)
+				}
+				if content != userHookContent {
+					t.Fatalf("user hook was not restored:\n%s", content)
+				}
+			}
+		})
+	}
+}
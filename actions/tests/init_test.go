@@ -0,0 +1,127 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/actions"
+	"github.com/jeftadlvw/git-nest/internal"
+	"github.com/jeftadlvw/git-nest/internal/constants"
+	"github.com/jeftadlvw/git-nest/migrations"
+	mcontext "github.com/jeftadlvw/git-nest/migrations/context"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/test_env"
+	test_env_models "github.com/jeftadlvw/git-nest/test_env/models"
+	"github.com/jeftadlvw/git-nest/utils"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestInitializeProject(t *testing.T) {
+
+	const subDir = "subdir"
+
+	cases := []struct {
+		useConfigDir   bool
+		existingConfig string
+		useSubDir      bool
+		noGit          bool
+		expectExclude  bool
+		warnings       int
+		err            bool
+	}{
+		{false, "", false, false, true, 0, false},
+		{true, "", false, false, true, 0, false},
+		{false, constants.ConfigFileName, false, false, false, 0, true},
+		{true, constants.ConfigSubDirFileName, false, false, false, 0, true},
+		{false, constants.ConfigFileName, true, false, false, 1, false},
+		{false, "", true, false, false, 1, false},
+		{false, "", false, true, false, 1, false},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestInitializeProject-%d", index+1), func(t *testing.T) {
+			t.Parallel()
+
+			tempDir := models.Path(t.TempDir())
+			err := test_env.CreateTestEnvironment(tempDir, test_env_models.EnvSettings{EmptyGit: !tc.noGit, NoGit: tc.noGit})
+			if err != nil {
+				t.Fatalf("error creating test environment: %s", err)
+			}
+
+			if tc.existingConfig != "" {
+				existingConfig := tempDir.SJoin(tc.existingConfig)
+				existingConfigDir := existingConfig.Parent()
+				err = os.MkdirAll(existingConfigDir.String(), os.ModePerm)
+				if err != nil {
+					t.Fatalf("error creating configuration directory: %s", err)
+				}
+
+				err = utils.WriteStrToFile(existingConfig, "")
+				if err != nil {
+					t.Fatalf("error writing existing configuration: %s", err)
+				}
+			}
+
+			workingDir := tempDir
+			if tc.useSubDir {
+				workingDir = tempDir.SJoin(subDir)
+				err = os.Mkdir(workingDir.String(), os.ModePerm)
+				if err != nil {
+					t.Fatalf("error creating sub directory: %s", err)
+				}
+			}
+
+			// create context
+			context, err := internal.CreateContext(workingDir)
+			if err != nil {
+				t.Fatalf("error creating context: %s", err)
+			}
+
+			config := models.Config{AllowDuplicateOrigins: true}
+			migrationArr, warnings, err := actions.InitializeProject(&context, tc.useConfigDir, config)
+			if tc.err && err == nil {
+				t.Fatalf("no error, but expected one")
+			}
+			if !tc.err && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if tc.err {
+				return
+			}
+
+			if len(warnings) != tc.warnings {
+				t.Fatalf("expected %d warnings, got %d: %q", tc.warnings, len(warnings), warnings)
+			}
+
+			migrationArr = append(migrationArr, mcontext.WriteConfigFiles{Context: &context})
+			err = migrations.RunMigrations(migrationArr...)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			expectedConfigFile := workingDir.SJoin(constants.ConfigFileName)
+			if tc.useConfigDir {
+				expectedConfigFile = workingDir.SJoin(constants.ConfigSubDirFileName)
+			}
+
+			configContent, err := utils.ReadFileToStr(expectedConfigFile)
+			if err != nil {
+				t.Fatalf("configuration file was not created: %s", err)
+			}
+			if !strings.Contains(configContent, "allow_duplicate_origins = true") {
+				t.Fatalf("configuration file does not contain configuration:\n%s", configContent)
+			}
+
+			excludeContent, _ := utils.ReadFileToStr(tempDir.SJoin(".git/info/exclude"))
+			if tc.expectExclude != strings.Contains(excludeContent, "# git-nest configuration start") {
+				t.Fatalf("unexpected exclude file state (expected block: %t):\n%s", tc.expectExclude, excludeContent)
+			}
+
+			// created project is found as project root
+			projectRoot, err := internal.FindProjectRoot(workingDir)
+			if err != nil || !projectRoot.Equals(workingDir) {
+				t.Fatalf("created project is not recognised as project root: %s, %v", projectRoot, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,242 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/actions"
+	"github.com/jeftadlvw/git-nest/interfaces"
+	"github.com/jeftadlvw/git-nest/internal"
+	"github.com/jeftadlvw/git-nest/migrations"
+	mcontext "github.com/jeftadlvw/git-nest/migrations/context"
+	"github.com/jeftadlvw/git-nest/migrations/fs"
+	"github.com/jeftadlvw/git-nest/migrations/submodules"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/models/urls"
+	"github.com/jeftadlvw/git-nest/test_env"
+	test_env_models "github.com/jeftadlvw/git-nest/test_env/models"
+	"github.com/jeftadlvw/git-nest/utils"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMoveSubmoduleInContext(t *testing.T) {
+
+	testRepoUrl, lerr := urls.HttpUrlFromString(test_env.RepoUrl)
+	if lerr != nil {
+		t.Fatal(lerr)
+	}
+
+	moduleDir := "module"
+	otherModuleDir := "other"
+	testFile := "testfile"
+
+	expectedMigrations := []interfaces.Migration{submodules.UpdatePath{}}
+	expectedMigrationsMoveDir := []interfaces.Migration{fs.MoveDirectory{}, fs.DeleteEmptyParents{}, submodules.UpdatePath{}}
+
+	cases := []struct {
+		oldPath            string
+		newPath            string
+		createModuleDir    bool
+		expectedPath       string
+		expectedMigrations []interfaces.Migration
+		err                bool
+	}{
+		// path conditions
+		{"foo", "bar", false, "", nil, true},
+		{"../" + moduleDir, "bar", false, "", nil, true},
+		{moduleDir, "../bar", false, "", nil, true},
+		{moduleDir, ".", false, "", nil, true},
+		{moduleDir, moduleDir, false, "", nil, true},
+		{moduleDir, moduleDir + "/inner", false, "", nil, true},
+		{moduleDir, otherModuleDir + "/inner", false, "", nil, true},
+		{moduleDir, testFile, false, "", nil, true},
+		{moduleDir, "vendor/lib", true, "", nil, true},
+
+		// successful moves
+		{moduleDir, "bar", false, "bar", expectedMigrations, false},
+		{moduleDir, "bar", true, "bar", expectedMigrationsMoveDir, false},
+		{moduleDir, "foo/bar", true, "foo/bar", expectedMigrationsMoveDir, false},
+		{moduleDir, "foo/", true, "foo/" + moduleDir, expectedMigrationsMoveDir, false},
+		{moduleDir, "foo/../bar", true, "bar", expectedMigrationsMoveDir, false},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestMoveSubmoduleInContext-%d", index+1), func(t *testing.T) {
+			t.Parallel()
+
+			tempDir := models.Path(t.TempDir())
+			err := test_env.CreateTestEnvironment(tempDir, test_env_models.EnvSettings{EmptyGit: true})
+			if err != nil {
+				t.Fatalf("error creating test environment: %s", err)
+			}
+
+			err = utils.WriteStrToFile(tempDir.SJoin(testFile), "")
+			if err != nil {
+				t.Fatalf("error writing test file: %s", err)
+			}
+
+			if tc.createModuleDir {
+				err = test_env.CreateLocalRepository(tempDir.SJoin(moduleDir), "initial commit")
+				if err != nil {
+					t.Fatalf("error creating module repository: %s", err)
+				}
+			}
+
+			// create context
+			context, err := internal.CreateContext(tempDir)
+			if err != nil {
+				t.Fatalf("error creating context: %s", err)
+			}
+
+			context.Config.Config.AllowDuplicateOrigins = true
+			context.Policy = models.Policy{ForbiddenPaths: []string{"vendor"}}
+			context.Config.Submodules = []models.Submodule{
+				{Path: models.Path(moduleDir), Url: &testRepoUrl},
+				{Path: models.Path(otherModuleDir), Url: &testRepoUrl},
+			}
+
+			// move submodule
+			migrationArr, err := actions.MoveSubmoduleInContext(&context, context.ProjectRoot.SJoin(tc.oldPath), models.Path(tc.newPath))
+			if tc.err && err == nil {
+				t.Fatalf("no error, but expected one")
+			}
+			if !tc.err && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if tc.err {
+				return
+			}
+
+			// check migration array
+			if len(tc.expectedMigrations) != len(migrationArr) {
+				t.Fatalf("unequal amounts of migrations: expected %d, got %d", len(tc.expectedMigrations), len(migrationArr))
+			}
+			for mindex, migration := range migrationArr {
+				if reflect.TypeOf(migration) != reflect.TypeOf(tc.expectedMigrations[mindex]) {
+					t.Fatalf("unexpected migration at index %d: %T != %T", mindex, migration, tc.expectedMigrations[mindex])
+				}
+			}
+
+			// run migrations and write configuration files
+			migrationArr = append(migrationArr, mcontext.WriteConfigFiles{Context: &context})
+			err = migrations.RunMigrations(migrationArr...)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			expectedPath := models.Path(tc.expectedPath)
+			if context.Config.Submodules[0].Path.String() != expectedPath.String() {
+				t.Fatalf("submodule path was not updated: >%s<, expected >%s<", context.Config.Submodules[0].Path, tc.expectedPath)
+			}
+
+			if tc.createModuleDir {
+				newAbsolutePath := tempDir.Join(expectedPath)
+				oldAbsolutePath := tempDir.SJoin(moduleDir)
+				if oldAbsolutePath.Exists() || !newAbsolutePath.BContains(".git") {
+					t.Fatalf("module directory was not moved to %s", newAbsolutePath)
+				}
+			}
+
+			excludeContent, err := utils.ReadFileToStr(tempDir.SJoin(".git/info/exclude"))
+			if err != nil {
+				t.Fatalf("error reading exclude file: %s", err)
+			}
+
+			excludeLines := strings.Split(excludeContent, "\n")
+			if !containsLine(excludeLines, expectedPath.UnixString()) || containsLine(excludeLines, moduleDir) {
+				t.Fatalf("exclude file was not updated:\n%s", excludeContent)
+			}
+
+			configContent, err := os.ReadFile(context.ConfigFile.String())
+			if err != nil {
+				t.Fatalf("error reading configuration file: %s", err)
+			}
+			if !strings.Contains(string(configContent), fmt.Sprintf("path = \"%s\"", expectedPath.UnixString())) {
+				t.Fatalf("configuration file was not updated:\n%s", configContent)
+			}
+		})
+	}
+}
+
+func containsLine(lines []string, line string) bool {
+	for _, l := range lines {
+		if strings.TrimSpace(l) == line {
+			return true
+		}
+	}
+	return false
+}
+
+func TestMoveSubmoduleInContextDeletesEmptyParents(t *testing.T) {
+	testRepoUrl, lerr := urls.HttpUrlFromString(test_env.RepoUrl)
+	if lerr != nil {
+		t.Fatal(lerr)
+	}
+
+	cases := []struct {
+		oldPath  string
+		newPath  string
+		keep     string
+		deleted  []string
+		retained []string
+	}{
+		{"vendor/lib", "lib", "", []string{"vendor"}, nil},
+		{"a/b/lib", "lib", "a/keep", []string{"a/b"}, []string{"a"}},
+		{"a/b/lib", "a/lib", "", []string{"a/b"}, []string{"a"}},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestMoveSubmoduleInContextDeletesEmptyParents-%d", index+1), func(t *testing.T) {
+			t.Parallel()
+
+			tempDir := models.Path(t.TempDir())
+			err := test_env.CreateTestEnvironment(tempDir, test_env_models.EnvSettings{EmptyGit: true})
+			if err != nil {
+				t.Fatalf("error creating test environment: %s", err)
+			}
+
+			err = test_env.CreateLocalRepository(tempDir.SJoin(tc.oldPath), "initial commit")
+			if err != nil {
+				t.Fatalf("error creating module repository: %s", err)
+			}
+
+			if tc.keep != "" {
+				err = utils.WriteStrToFile(tempDir.SJoin(tc.keep), "")
+				if err != nil {
+					t.Fatalf("error writing test file: %s", err)
+				}
+			}
+
+			context, err := internal.CreateContext(tempDir)
+			if err != nil {
+				t.Fatalf("error creating context: %s", err)
+			}
+			context.Config.Submodules = []models.Submodule{{Path: models.Path(tc.oldPath), Url: &testRepoUrl}}
+
+			migrationArr, err := actions.MoveSubmoduleInContext(&context, context.ProjectRoot.SJoin(tc.oldPath), models.Path(tc.newPath))
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			err = migrations.RunMigrations(migrationArr...)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			for _, deleted := range tc.deleted {
+				if directory := tempDir.SJoin(deleted); directory.Exists() {
+					t.Fatalf("empty parent directory %s was not deleted", deleted)
+				}
+			}
+			for _, retained := range tc.retained {
+				if directory := tempDir.SJoin(retained); !directory.IsDir() {
+					t.Fatalf("parent directory %s was deleted", retained)
+				}
+			}
+			if newPath := tempDir.SJoin(tc.newPath); !newPath.IsDir() {
+				t.Fatalf("module directory was not moved to %s", tc.newPath)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/actions"
+	"github.com/jeftadlvw/git-nest/internal"
+	"github.com/jeftadlvw/git-nest/migrations"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/test_env"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPruneOrphanedModules(t *testing.T) {
+	cases := []struct {
+		orphan             models.Path
+		uncommittedChanges bool
+		force              bool
+		expectDeleted      bool
+		err                bool
+	}{
+		{"module", false, false, true, false},
+		{"module", true, false, false, true},
+		{"module", true, true, true, false},
+		{"missing", false, false, false, false},
+		{"../module", false, false, false, true},
+		{".", false, false, false, true},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestPruneOrphanedModules-%d", index+1), func(t *testing.T) {
+			t.Parallel()
+
+			root := models.Path(t.TempDir())
+			modulePath := root.SJoin("module")
+			err := test_env.CreateLocalRepository(modulePath, "first")
+			if err != nil {
+				t.Fatalf("error creating module repository: %s", err)
+			}
+
+			if tc.uncommittedChanges {
+				err = os.WriteFile(filepath.Join(string(modulePath), "file.txt"), []byte("change"), 0644)
+				if err != nil {
+					t.Fatalf("error writing file: %s", err)
+				}
+			}
+
+			context := models.NestContext{ProjectRoot: root, IsGitInstalled: true}
+			migrationArr, err := actions.PruneOrphanedModules(&context, []internal.OrphanedModule{{Path: tc.orphan}}, tc.force)
+			if err == nil {
+				err = migrations.RunMigrations(migrationArr...)
+			}
+
+			if tc.err != (err != nil) {
+				t.Fatalf("unexpected error result: %v", err)
+			}
+
+			if deleted := !modulePath.Exists(); deleted != tc.expectDeleted {
+				t.Fatalf("unexpected deletion state %t, expected %t", deleted, tc.expectDeleted)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/actions"
+	"github.com/jeftadlvw/git-nest/migrations/git"
+	"github.com/jeftadlvw/git-nest/migrations/submodules"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/models/urls"
+	"github.com/jeftadlvw/git-nest/test_env"
+	"github.com/jeftadlvw/git-nest/utils"
+	"testing"
+)
+
+func TestPullAllSubmodulesSkipsPinnedModules(t *testing.T) {
+	const tag = "v1.0.0"
+
+	testRepoUrl, err := urls.HttpUrlFromString(test_env.RepoUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root := models.Path(t.TempDir())
+	err = test_env.CreateLocalRepository(root.SJoin("module"), "first", "second")
+	if err != nil {
+		t.Fatalf("error creating module repository: %s", err)
+	}
+
+	out, err := utils.RunCommandCombinedOutput(root.SJoin("module"), "git", "tag", tag, "HEAD~1")
+	if err != nil {
+		t.Fatalf("error creating tag: %s; %s", err, out)
+	}
+
+	context := models.NestContext{ProjectRoot: root}
+	context.Config.Submodules = []models.Submodule{
+		{Path: "module", Url: &testRepoUrl},
+		{Path: "module", Url: &testRepoUrl, Ref: test_env.RepoBranchDefault},
+		{Path: "module", Url: &testRepoUrl, Ref: tag},
+		{Path: "module", Url: &testRepoUrl, Ref: "abc1234"},
+		{Path: "module", Url: &testRepoUrl, Ref: test_env.RepoBranchDefault, RefKind: models.RefKindTag},
+	}
+
+	migrationArr, err := actions.PullAllSubmodules(&context)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(migrationArr) != 2 {
+		t.Fatalf("expected 2 pull migrations, got %d", len(migrationArr))
+	}
+
+	for _, migration := range migrationArr {
+		if _, ok := migration.(git.Pull); !ok {
+			t.Fatalf("unexpected migration %T", migration)
+		}
+	}
+}
+
+func TestPullAllSubmodulesRecordIntegrity(t *testing.T) {
+	testRepoUrl, err := urls.HttpUrlFromString(test_env.RepoUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for index, recordIntegrity := range []bool{false, true} {
+		t.Run(fmt.Sprintf("TestPullAllSubmodulesRecordIntegrity-%d", index+1), func(t *testing.T) {
+			t.Parallel()
+
+			context := models.NestContext{ProjectRoot: models.Path(t.TempDir())}
+			context.Config.Config.RecordIntegrity = recordIntegrity
+			context.Config.Submodules = []models.Submodule{{Path: "module", Url: &testRepoUrl}}
+
+			migrationArr, err := actions.PullAllSubmodules(&context)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			expected := 1
+			if recordIntegrity {
+				expected = 2
+			}
+			if len(migrationArr) != expected {
+				t.Fatalf("expected %d migrations, got %d", expected, len(migrationArr))
+			}
+
+			if recordIntegrity {
+				if _, ok := migrationArr[1].(submodules.UpdateIntegrity); !ok {
+					t.Fatalf("unexpected migration %T", migrationArr[1])
+				}
+			}
+		})
+	}
+}
@@ -134,7 +134,7 @@ func TestRemoveSubmoduleFromContext(t *testing.T) {
 			}
 
 			// remove submodule
-			migrationArr, err := actions.RemoveSubmoduleFromContext(&context, p, tc.removeNonEmptyDir, tc.forceDelete)
+			migrationArr, err := actions.RemoveSubmoduleFromContext(&context, p, tc.removeNonEmptyDir, tc.forceDelete, false)
 			if tc.err && err == nil {
 				t.Fatalf("no error, but expected one")
 			}
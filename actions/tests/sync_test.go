@@ -1,6 +1,7 @@
 package tests
 
 import (
+	"errors"
 	"fmt"
 	"github.com/jeftadlvw/git-nest/actions"
 	"github.com/jeftadlvw/git-nest/interfaces"
@@ -13,6 +14,7 @@ import (
 	"github.com/jeftadlvw/git-nest/test_env"
 	"github.com/jeftadlvw/git-nest/utils"
 	"os"
+	"os/exec"
 	"reflect"
 	"strings"
 	"testing"
@@ -134,7 +136,7 @@ func TestSynchronizeSubmodule(t *testing.T) {
 			}
 
 			// sync submodule
-			migrationArr, err := actions.SynchronizeSubmodule(&tc.submodule, context.ProjectRoot)
+			migrationArr, err := actions.SynchronizeSubmodule(&tc.submodule, context.ProjectRoot, false)
 
 			if tc.err && err == nil {
 				t.Fatalf("no error, but expected one")
@@ -190,3 +192,696 @@ func TestSynchronizeSubmodule(t *testing.T) {
 		})
 	}
 }
+
+func TestApplyConfigToSubmodule(t *testing.T) {
+
+	const moduleDir = "module"
+	const tag = "v1.0.0"
+
+	testRepoUrl, err := urls.HttpUrlFromString(test_env.RepoUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkoutMigration := []interfaces.Migration{git.Checkout{}}
+	fetchAndCheckoutMigration := []interfaces.Migration{git.Fetch{}, git.Checkout{}}
+	setUrlMigration := []interfaces.Migration{git.SetRemoteUrl{}}
+	setUrlAndCheckoutMigration := []interfaces.Migration{git.SetRemoteUrl{}, git.Checkout{}}
+
+	cases := []struct {
+		ref                string
+		repoOriginOverride string
+		repoRefOverride    string
+		dirty              bool
+		moduleIsFile       bool
+		expectedMigrations []interfaces.Migration
+		expectedHead       string
+		err                bool
+	}{
+		{"", "", "", false, true, nil, "", true},
+		{"", "", "", false, false, nil, test_env.RepoBranchDefault, false},
+		{test_env.RepoBranchDefault, "", "", false, false, nil, test_env.RepoBranchDefault, false},
+		{tag, "", "", false, false, checkoutMigration, tag, false},
+		{tag, "", tag, false, false, nil, tag, false},
+		{test_env.RepoBranchDefault, "", tag, false, false, checkoutMigration, test_env.RepoBranchDefault, false},
+		{tag, "", "", true, false, nil, "", true},
+		{"", "https://example.com/foo", "", false, false, setUrlMigration, test_env.RepoBranchDefault, false},
+		{tag, "https://example.com/foo", "", false, false, setUrlAndCheckoutMigration, tag, false},
+		{"nonexisting", "", "", false, false, fetchAndCheckoutMigration, "", false},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestApplyConfigToSubmodule-%d", index+1), func(t *testing.T) {
+			t.Parallel()
+
+			testEnvDir := models.Path(t.TempDir())
+			modulePath := testEnvDir.SJoin(moduleDir)
+
+			if tc.moduleIsFile {
+				err := utils.WriteStrToFile(modulePath, "")
+				if err != nil {
+					t.Fatalf("error writing test file: %s", err)
+				}
+			} else {
+				err := test_env.CreateLocalRepository(modulePath, "first", "second")
+				if err != nil {
+					t.Fatalf("error creating module repository: %s", err)
+				}
+
+				origin := testRepoUrl.String()
+				if tc.repoOriginOverride != "" {
+					origin = tc.repoOriginOverride
+				}
+
+				commands := [][]string{
+					{"remote", "add", "origin", origin},
+					{"tag", tag, "HEAD~1"},
+				}
+				if tc.repoRefOverride != "" {
+					commands = append(commands, []string{"checkout", "--quiet", tc.repoRefOverride})
+				}
+
+				for _, args := range commands {
+					out, err := utils.RunCommandCombinedOutput(modulePath, "git", args...)
+					if err != nil {
+						t.Fatalf("error running git %s: %s; %s", args[0], err, out)
+					}
+				}
+
+				if tc.dirty {
+					err = utils.WriteStrToFile(modulePath.SJoin("dirty"), "")
+					if err != nil {
+						t.Fatalf("error writing test file: %s", err)
+					}
+				}
+			}
+
+			submodule := models.Submodule{Path: moduleDir, Url: &testRepoUrl, Ref: tc.ref}
+			migrationArr, err := actions.ApplyConfigToSubmodule(&submodule, testEnvDir, false)
+			if tc.err && err == nil {
+				t.Fatalf("no error, but expected one")
+			}
+			if !tc.err && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if tc.err {
+				return
+			}
+
+			// check migration array
+			if len(tc.expectedMigrations) != len(migrationArr) {
+				t.Fatalf("unequal amounts of migrations: expected %d, got %d", len(tc.expectedMigrations), len(migrationArr))
+			}
+			for mindex, migration := range migrationArr {
+				if reflect.TypeOf(migration) != reflect.TypeOf(tc.expectedMigrations[mindex]) {
+					t.Fatalf("unexpected migration: %T != %T", migration, tc.expectedMigrations[mindex])
+				}
+			}
+
+			// fetching requires network access
+			if tc.expectedHead == "" {
+				return
+			}
+
+			err = migrations.RunMigrations(migrationArr...)
+			if err != nil {
+				t.Fatalf("unexpected error running migrations: %s", err)
+			}
+
+			remoteUrl, err := utils.GetGitRemoteUrl(modulePath)
+			if err != nil {
+				t.Fatalf("error reading remote url: %s", err)
+			}
+			if remoteUrl != testRepoUrl.String() {
+				t.Fatalf("remote url was not reset: >%s<", remoteUrl)
+			}
+
+			expectedCommit, err := utils.GetGitRefCommit(modulePath, tc.expectedHead)
+			if err != nil {
+				t.Fatalf("error resolving expected head: %s", err)
+			}
+			headCommit, _, err := utils.GetGitFetchHead(modulePath)
+			if err != nil {
+				t.Fatalf("error reading head: %s", err)
+			}
+			if headCommit != expectedCommit {
+				t.Fatalf("module was not checked out at %s", tc.expectedHead)
+			}
+		})
+	}
+}
+
+func TestSynchronizeSubmoduleUrlRewrite(t *testing.T) {
+	const moduleDir = "module"
+
+	remotesDir := models.Path(t.TempDir())
+	remoteDir := remotesDir.SJoin("repository")
+	err := test_env.CreateLocalRepository(remoteDir, "first")
+	if err != nil {
+		t.Fatalf("error creating remote repository: %s", err)
+	}
+
+	canonicalUrl, err := urls.HttpUrlFromString("https://git-nest.invalid/repository")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	utils.SetGitUrlRewrites(models.UrlRewrite{Prefix: "https://git-nest.invalid/", Replacement: remotesDir.String() + "/"})
+	defer utils.SetGitUrlRewrites()
+
+	cases := []struct {
+		origin string
+	}{
+		{""},
+		{canonicalUrl.String()},
+		{remoteDir.String()},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestSynchronizeSubmoduleUrlRewrite-%d", index+1), func(t *testing.T) {
+			testEnvDir := models.Path(t.TempDir())
+			modulePath := testEnvDir.SJoin(moduleDir)
+			submodule := models.Submodule{Path: moduleDir, Url: &canonicalUrl, Ref: test_env.RepoBranchDefault}
+
+			if tc.origin == "" {
+				// clone through the rewritten url
+				migrationArr, err := actions.SynchronizeSubmodule(&submodule, testEnvDir, false)
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				err = migrations.RunMigrations(migrationArr...)
+				if err != nil {
+					t.Fatalf("unexpected error running migrations: %s", err)
+				}
+
+				remoteUrl, err := utils.GetGitRemoteUrl(modulePath)
+				if err != nil {
+					t.Fatalf("error reading remote url: %s", err)
+				}
+				if remoteUrl != canonicalUrl.String() {
+					t.Fatalf("canonical url was not kept as origin: >%s<", remoteUrl)
+				}
+			} else {
+				err := test_env.CreateLocalRepository(modulePath, "first")
+				if err != nil {
+					t.Fatalf("error creating module repository: %s", err)
+				}
+				out, err := utils.RunCommandCombinedOutput(modulePath, "git", "remote", "add", "origin", tc.origin)
+				if err != nil {
+					t.Fatalf("error adding remote: %s; %s", err, out)
+				}
+			}
+
+			// neither the canonical nor the rewritten origin are a mismatch
+			migrationArr, err := actions.SynchronizeSubmodule(&submodule, testEnvDir, false)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			for _, migration := range migrationArr {
+				if _, ok := migration.(submodules.UpdateUrl); ok {
+					t.Fatalf("origin was flagged as mismatch")
+				}
+			}
+
+			migrationArr, err = actions.ApplyConfigToSubmodule(&submodule, testEnvDir, false)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			for _, migration := range migrationArr {
+				if _, ok := migration.(git.SetRemoteUrl); ok {
+					t.Fatalf("origin was reset")
+				}
+			}
+
+			status, _, err := internal.SubmoduleExists(submodule, testEnvDir, false)
+			if err != nil || status == internal.SUBMODULE_EXISTS_ERR_REMOTE {
+				t.Fatalf("unexpected submodule status %d: %v", status, err)
+			}
+		})
+	}
+}
+
+func TestSynchronizeSubmoduleRemotes(t *testing.T) {
+	const moduleDir = "module"
+
+	remotesDir := models.Path(t.TempDir())
+	err := test_env.CreateLocalRepository(remotesDir.SJoin("upstream"), "first")
+	if err != nil {
+		t.Fatalf("error creating remote repository: %s", err)
+	}
+
+	upstreamUrl, err := urls.HttpUrlFromString("https://git-nest.invalid/upstream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	const forkUrl = "https://git-nest.invalid/fork"
+
+	utils.SetGitUrlRewrites(models.UrlRewrite{Prefix: "https://git-nest.invalid/", Replacement: remotesDir.String() + "/"})
+	defer utils.SetGitUrlRewrites()
+
+	submodule := models.Submodule{
+		Path:    moduleDir,
+		Url:     &upstreamUrl,
+		Ref:     test_env.RepoBranchDefault,
+		Remote:  "upstream",
+		Remotes: map[string]string{"fork": forkUrl},
+	}
+
+	cases := []struct {
+		remotes                 map[string]string
+		expectedSyncMigrations  []interfaces.Migration
+		expectedApplyMigrations []interfaces.Migration
+	}{
+		{nil, nil, nil},
+		{map[string]string{"upstream": upstreamUrl.String(), "fork": forkUrl}, nil, nil},
+		{map[string]string{"upstream": upstreamUrl.String()}, []interfaces.Migration{git.SetRemoteUrl{}}, []interfaces.Migration{git.SetRemoteUrl{}}},
+		{map[string]string{"upstream": upstreamUrl.String(), "fork": "https://example.com/other"}, []interfaces.Migration{git.SetRemoteUrl{}}, []interfaces.Migration{git.SetRemoteUrl{}}},
+		{map[string]string{"origin": upstreamUrl.String(), "fork": forkUrl}, []interfaces.Migration{git.SetRemoteUrl{}}, []interfaces.Migration{git.SetRemoteUrl{}}},
+		{map[string]string{"upstream": "https://example.com/other", "fork": forkUrl}, []interfaces.Migration{submodules.UpdateUrl{}}, []interfaces.Migration{git.SetRemoteUrl{}}},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestSynchronizeSubmoduleRemotes-%d", index+1), func(t *testing.T) {
+			testEnvDir := models.Path(t.TempDir())
+			modulePath := testEnvDir.SJoin(moduleDir)
+			localSubmodule := submodule
+
+			if tc.remotes == nil {
+				// clone with declared remote name and additional remotes
+				migrationArr, err := actions.SynchronizeSubmodule(&localSubmodule, testEnvDir, false)
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				err = migrations.RunMigrations(migrationArr...)
+				if err != nil {
+					t.Fatalf("unexpected error running migrations: %s", err)
+				}
+
+				remotes, err := utils.GetGitRemotes(modulePath)
+				if err != nil {
+					t.Fatalf("error reading remotes: %s", err)
+				}
+				if strings.Join(remotes, ",") != "fork,upstream" {
+					t.Fatalf("unexpected remotes after clone: %v", remotes)
+				}
+			} else {
+				err := test_env.CreateLocalRepository(modulePath, "first")
+				if err != nil {
+					t.Fatalf("error creating module repository: %s", err)
+				}
+				for name, remoteUrl := range tc.remotes {
+					err = utils.GitSetRemoteUrl(modulePath, name, remoteUrl)
+					if err != nil {
+						t.Fatalf("error adding remote %s: %s", name, err)
+					}
+				}
+			}
+
+			for _, run := range []struct {
+				name     string
+				sync     func(*models.Submodule, models.Path, bool) ([]interfaces.Migration, error)
+				expected []interfaces.Migration
+			}{
+				{"sync", actions.SynchronizeSubmodule, tc.expectedSyncMigrations},
+				{"apply", actions.ApplyConfigToSubmodule, tc.expectedApplyMigrations},
+			} {
+				localSubmodule = submodule
+				migrationArr, err := run.sync(&localSubmodule, testEnvDir, false)
+				if err != nil {
+					t.Fatalf("%s: unexpected error: %s", run.name, err)
+				}
+				if len(run.expected) != len(migrationArr) {
+					t.Fatalf("%s: unequal amounts of migrations: expected %d, got %d", run.name, len(run.expected), len(migrationArr))
+				}
+				for mindex, migration := range migrationArr {
+					if reflect.TypeOf(migration) != reflect.TypeOf(run.expected[mindex]) {
+						t.Fatalf("%s: unexpected migration: %T != %T", run.name, migration, run.expected[mindex])
+					}
+				}
+			}
+
+			// after applying the configuration, the nested module is valid
+			localSubmodule = submodule
+			migrationArr, err := actions.ApplyConfigToSubmodule(&localSubmodule, testEnvDir, false)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			err = migrations.RunMigrations(migrationArr...)
+			if err != nil {
+				t.Fatalf("unexpected error running migrations: %s", err)
+			}
+
+			status, payload, err := internal.SubmoduleExists(submodule, testEnvDir, false)
+			if err != nil || !internal.SubmoduleStatusValid(status) {
+				t.Fatalf("unexpected submodule status %d (%s): %v", status, payload, err)
+			}
+		})
+	}
+}
+
+func TestSynchronizeSubmoduleRefKinds(t *testing.T) {
+
+	const moduleDir = "module"
+	const tag = "v1.0.0"
+
+	testRepoUrl, err := urls.HttpUrlFromString(test_env.RepoUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		checkout        string
+		ref             string
+		refKind         models.RefKind
+		expectedRef     string
+		expectedRefKind models.RefKind
+	}{
+		{tag, tag, models.RefKindAuto, tag, models.RefKindAuto},
+		{tag, tag, models.RefKindTag, tag, models.RefKindTag},
+		{tag, "", models.RefKindAuto, tag, models.RefKindAuto},
+		{tag, test_env.RepoBranchDefault, models.RefKindBranch, tag, models.RefKindTag},
+		{tag, "{first:7}", models.RefKindCommit, "{first:7}", models.RefKindCommit},
+		{tag, "{second:7}", models.RefKindCommit, "{first}", models.RefKindCommit},
+		{"{second}", tag, models.RefKindTag, "{second}", models.RefKindCommit},
+		{test_env.RepoBranchDefault, tag, models.RefKindAuto, test_env.RepoBranchDefault, models.RefKindAuto},
+		{test_env.RepoBranchDefault, "{second:7}", models.RefKindAuto, "{second:7}", models.RefKindAuto},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestSynchronizeSubmoduleRefKinds-%d", index+1), func(t *testing.T) {
+			t.Parallel()
+
+			testEnvDir := models.Path(t.TempDir())
+			modulePath := testEnvDir.SJoin(moduleDir)
+
+			err := test_env.CreateLocalRepository(modulePath, "first", "second")
+			if err != nil {
+				t.Fatalf("error creating module repository: %s", err)
+			}
+
+			commits := make(map[string]string)
+			for name, rev := range map[string]string{"first": "HEAD~1", "second": "HEAD"} {
+				commits[name], err = utils.GetGitRefCommit(modulePath, rev)
+				if err != nil {
+					t.Fatalf("error resolving %s: %s", rev, err)
+				}
+			}
+
+			replaceCommits := func(s string) string {
+				for name, commit := range commits {
+					s = strings.ReplaceAll(s, "{"+name+":7}", commit[:7])
+					s = strings.ReplaceAll(s, "{"+name+"}", commit)
+				}
+				return s
+			}
+
+			for _, args := range [][]string{{"remote", "add", "origin", testRepoUrl.String()}, {"tag", tag, "HEAD~1"}, {"checkout", "--quiet", replaceCommits(tc.checkout)}} {
+				out, err := utils.RunCommandCombinedOutput(modulePath, "git", args...)
+				if err != nil {
+					t.Fatalf("error running git %s: %s; %s", args[0], err, out)
+				}
+			}
+
+			submodule := models.Submodule{Path: moduleDir, Url: &testRepoUrl, Ref: replaceCommits(tc.ref), RefKind: tc.refKind}
+			migrationArr, err := actions.SynchronizeSubmodule(&submodule, testEnvDir, false)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			err = migrations.RunMigrations(migrationArr...)
+			if err != nil {
+				t.Fatalf("unexpected error running migrations: %s", err)
+			}
+
+			if submodule.Ref != replaceCommits(tc.expectedRef) || submodule.RefKind != tc.expectedRefKind {
+				t.Fatalf("unexpected ref %s (%s), expected %s (%s)", submodule.Ref, submodule.RefKind, replaceCommits(tc.expectedRef), tc.expectedRefKind)
+			}
+		})
+	}
+}
+
+func TestSynchronizeConfigAndModulesSignatures(t *testing.T) {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen is not installed")
+	}
+
+	const moduleDir = "module"
+
+	testRepoUrl, err := urls.HttpUrlFromString(test_env.RepoUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		signed     bool
+		trusted    bool
+		fromConfig bool
+		err        bool
+	}{
+		{true, true, false, false},
+		{true, true, true, false},
+		{true, false, false, true},
+		{false, true, false, true},
+		{false, true, true, true},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestSynchronizeConfigAndModulesSignatures-%d", index+1), func(t *testing.T) {
+			t.Parallel()
+
+			testEnvDir := models.Path(t.TempDir())
+			modulePath := testEnvDir.SJoin(moduleDir)
+
+			key, trustedSigners, err := test_env.CreateSshSigningKey(testEnvDir, "trusted", "foo@email.com")
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, untrustedSigners, err := test_env.CreateSshSigningKey(testEnvDir, "untrusted", "foo@email.com")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = test_env.CreateLocalRepository(modulePath)
+			if err != nil {
+				t.Fatalf("error creating module repository: %s", err)
+			}
+			err = test_env.ConfigureSshSigning(modulePath, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			commitArgs := []string{"commit", "--allow-empty", "-m", "commit"}
+			if tc.signed {
+				commitArgs = append(commitArgs, "-S")
+			}
+			for _, args := range [][]string{{"remote", "add", "origin", testRepoUrl.String()}, commitArgs} {
+				out, err := utils.RunCommandCombinedOutput(modulePath, "git", args...)
+				if err != nil {
+					t.Fatalf("error running git %s: %s; %s", args[0], err, out)
+				}
+			}
+
+			allowedSigners := trustedSigners
+			if !tc.trusted {
+				allowedSigners = untrustedSigners
+			}
+
+			context := models.NestContext{ProjectRoot: testEnvDir, IsGitInstalled: true}
+			context.Config.Config = models.Config{VerifySignature: true, AllowedSigners: allowedSigners.Base()}
+			context.Config.Submodules = []models.Submodule{{Path: moduleDir, Url: &testRepoUrl, Ref: test_env.RepoBranchDefault}}
+
+			syncFunc := actions.SynchronizeConfigAndModules
+			if tc.fromConfig {
+				syncFunc = actions.ApplyConfigToModules
+			}
+
+			migrationArr, err := syncFunc(&context)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			err = migrations.RunMigrations(migrationArr...)
+			if tc.err && err == nil {
+				t.Fatalf("no error, but expected one")
+			}
+			if !tc.err && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if tc.err && !errors.Is(err, utils.ErrGitSignatureUnverified) {
+				t.Fatalf("unexpected error type: %s", err)
+			}
+		})
+	}
+}
+
+func TestSynchronizeConfigAndModulesPolicy(t *testing.T) {
+	testRepoUrl, err := urls.HttpUrlFromString(test_env.RepoUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		policy     models.Policy
+		fromConfig bool
+		err        bool
+	}{
+		{models.Policy{AllowedHosts: []string{"github.com"}}, false, false},
+		{models.Policy{AllowedHosts: []string{"*.example.com"}}, false, true},
+		{models.Policy{AllowedHosts: []string{"*.example.com"}}, true, true},
+		{models.Policy{RequiredRefKinds: []models.RefKind{models.RefKindTag}}, false, true},
+		{models.Policy{ForbiddenPaths: []string{"mod*"}}, true, true},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestSynchronizeConfigAndModulesPolicy-%d", index+1), func(t *testing.T) {
+			t.Parallel()
+
+			root := models.Path(t.TempDir())
+			modulePath := root.SJoin("module")
+			err := test_env.CreateLocalRepository(modulePath, "first")
+			if err != nil {
+				t.Fatalf("error creating module repository: %s", err)
+			}
+
+			out, err := utils.RunCommandCombinedOutput(modulePath, "git", "remote", "add", "origin", testRepoUrl.String())
+			if err != nil {
+				t.Fatalf("error adding remote: %s; %s", err, out)
+			}
+
+			context := models.NestContext{ProjectRoot: root, IsGitInstalled: true, Policy: tc.policy}
+			context.Config.Submodules = []models.Submodule{{Path: "module", Url: &testRepoUrl, Ref: test_env.RepoBranchDefault}}
+
+			syncFunc := actions.SynchronizeConfigAndModules
+			if tc.fromConfig {
+				syncFunc = actions.ApplyConfigToModules
+			}
+
+			_, err = syncFunc(&context)
+			if tc.err && err == nil {
+				t.Fatalf("no error, but expected one")
+			}
+			if !tc.err && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if tc.err && !strings.Contains(err.Error(), "nested module module") {
+				t.Fatalf("violation does not reference the nested module: %s", err)
+			}
+		})
+	}
+}
+
+func TestSynchronizeConfigAndModulesRecordIntegrity(t *testing.T) {
+	testRepoUrl, err := urls.HttpUrlFromString(test_env.RepoUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for index, recordIntegrity := range []bool{false, true} {
+		t.Run(fmt.Sprintf("TestSynchronizeConfigAndModulesRecordIntegrity-%d", index+1), func(t *testing.T) {
+			t.Parallel()
+
+			root := models.Path(t.TempDir())
+			modulePath := root.SJoin("module")
+			err := test_env.CreateLocalRepository(modulePath, "first")
+			if err != nil {
+				t.Fatalf("error creating module repository: %s", err)
+			}
+
+			out, err := utils.RunCommandCombinedOutput(modulePath, "git", "remote", "add", "origin", testRepoUrl.String())
+			if err != nil {
+				t.Fatalf("error adding remote: %s; %s", err, out)
+			}
+
+			context := models.NestContext{ProjectRoot: root, IsGitInstalled: true}
+			context.Config.Config.RecordIntegrity = recordIntegrity
+			context.Config.Submodules = []models.Submodule{{Path: "module", Url: &testRepoUrl, Ref: test_env.RepoBranchDefault}}
+
+			migrationArr, err := actions.SynchronizeConfigAndModules(&context)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			err = migrations.RunMigrations(migrationArr...)
+			if err != nil {
+				t.Fatalf("unexpected error running migrations: %s", err)
+			}
+
+			// an existing worktree is neither cloned nor checked out, so its hashes are never recorded
+			submodule := context.Config.Submodules[0]
+			if submodule.TreeHash != "" || submodule.WorktreeHash != "" {
+				t.Fatalf("unexpected integrity hashes: %s, %s", submodule.TreeHash, submodule.WorktreeHash)
+			}
+		})
+	}
+}
+
+func TestApplyConfigToModulesRecordIntegrity(t *testing.T) {
+	testRepoUrl, err := urls.HttpUrlFromString(test_env.RepoUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		recordIntegrity bool
+		checkout        bool
+		expected        bool
+	}{
+		{false, false, false},
+		{false, true, false},
+		{true, false, false},
+		{true, true, true},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestApplyConfigToModulesRecordIntegrity-%d", index+1), func(t *testing.T) {
+			t.Parallel()
+
+			root := models.Path(t.TempDir())
+			modulePath := root.SJoin("module")
+			err := test_env.CreateLocalRepository(modulePath, "first", "second")
+			if err != nil {
+				t.Fatalf("error creating module repository: %s", err)
+			}
+
+			out, err := utils.RunCommandCombinedOutput(modulePath, "git", "remote", "add", "origin", testRepoUrl.String())
+			if err != nil {
+				t.Fatalf("error adding remote: %s; %s", err, out)
+			}
+
+			ref := test_env.RepoBranchDefault
+			if tc.checkout {
+				out, err = utils.RunCommandCombinedOutput(modulePath, "git", "rev-parse", "HEAD~1")
+				if err != nil {
+					t.Fatalf("error getting commit: %s; %s", err, out)
+				}
+				ref = strings.TrimSpace(out)
+			}
+
+			context := models.NestContext{ProjectRoot: root, IsGitInstalled: true}
+			context.Config.Config.RecordIntegrity = tc.recordIntegrity
+			context.Config.Submodules = []models.Submodule{{Path: "module", Url: &testRepoUrl, Ref: ref}}
+
+			migrationArr, err := actions.ApplyConfigToModules(&context)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			err = migrations.RunMigrations(migrationArr...)
+			if err != nil {
+				t.Fatalf("unexpected error running migrations: %s", err)
+			}
+
+			submodule := context.Config.Submodules[0]
+			if (submodule.TreeHash != "" && submodule.WorktreeHash != "") != tc.expected {
+				t.Fatalf("unexpected integrity hashes: %s, %s", submodule.TreeHash, submodule.WorktreeHash)
+			}
+
+			if tc.expected {
+				report := internal.VerifySubmoduleIntegrity(submodule, root)
+				if report.Error != nil || report.Modified() {
+					t.Fatalf("recorded hashes do not match: %s", report)
+				}
+			}
+		})
+	}
+}
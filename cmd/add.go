@@ -34,6 +34,11 @@ func wrapAddSubmodule(cmd *cobra.Command, args []string) error {
 		cloneDir models.Path
 	)
 
+	// credentials would be written into the configuration file
+	if urls.ContainsCredentials(args[0]) {
+		return errors.New("urls with embedded credentials are not supported, configure credentials for this host in [[config.credentials]] instead")
+	}
+
 	// validate url
 	u, err := urls.HttpUrlFromString(args[0])
 	if err != nil {
@@ -70,7 +75,7 @@ func addSubmodule(url urls.HttpUrl, ref string, cloneDir models.Path) error {
 	}
 
 	// run migrations
-	actionMigrations = append(actionMigrations, mcontext.WriteConfigFiles{Context: &context})
+	actionMigrations = append(actionMigrations, mcontext.WriteConfigFiles{Context: &context}, mcontext.RecordState{Context: &context, Command: "add"})
 	migrationError := migrations.RunMigrations(actionMigrations...)
 	if migrationError != nil {
 		return migrationError
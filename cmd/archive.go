@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/actions"
+	cmdInternal "github.com/jeftadlvw/git-nest/cmd/internal"
+	"github.com/jeftadlvw/git-nest/internal"
+	"github.com/jeftadlvw/git-nest/migrations"
+	"github.com/spf13/cobra"
+)
+
+func createArchiveCmd() *cobra.Command {
+	var archiveCmd = &cobra.Command{
+		Use:   "archive",
+		Short: "Export the project and all nested modules as plain files",
+		Long: `Export the committed state of the project and every nested module at its pinned ref into
+a .tar.gz, .tar or .zip archive, or into a directory. A manifest describing the included
+nested modules is added to the archive's root. Uncommitted changes are not included.
+Pass --group to only include nested modules of these groups. Nested modules with
+sparse_paths only include these paths.`,
+		RunE: cmdInternal.RunWrapper(wrapArchiveProject, cmdInternal.ArgNone()),
+	}
+
+	archiveCmd.Flags().StringP("output", "o", "", "archive file or directory to write to")
+	archiveCmd.Flags().String("format", "", "archive format: tar.gz, tar, zip or dir (default inferred from the output path)")
+	archiveCmd.Flags().StringSlice("group", nil, "only include nested modules of this group (can be repeated)")
+	_ = archiveCmd.MarkFlagRequired("output")
+
+	return archiveCmd
+}
+
+func wrapArchiveProject(cmd *cobra.Command, args []string) error {
+	output, _ := cmd.Flags().GetString("output")
+	format, _ := cmd.Flags().GetString("format")
+	groups, _ := cmd.Flags().GetStringSlice("group")
+	return archiveProject(output, format, groups)
+}
+
+func archiveProject(output string, format string, groups []string) error {
+	// read context
+	context, err := cmdInternal.ErrorWrappedEvaluateContext()
+	if err != nil {
+		return err
+	}
+
+	outputPath := absoluteFromWorkingDirectory(context, output)
+	actionMigrations, err := actions.ArchiveProject(&context, outputPath, format, groups)
+	if err != nil {
+		return err
+	}
+
+	err = migrations.RunMigrations(actionMigrations...)
+	if err != nil {
+		return err
+	}
+
+	archived, _ := internal.SubmodulesInGroups(context.Config.Submodules, groups)
+	fmt.Printf("archived project with %d nested modules to %s\n", len(archived), outputPath.String())
+	return nil
+}
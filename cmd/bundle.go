@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/actions"
+	cmdInternal "github.com/jeftadlvw/git-nest/cmd/internal"
+	"github.com/jeftadlvw/git-nest/internal"
+	"github.com/jeftadlvw/git-nest/internal/constants"
+	"github.com/jeftadlvw/git-nest/migrations"
+	mcontext "github.com/jeftadlvw/git-nest/migrations/context"
+	"github.com/jeftadlvw/git-nest/migrations/fs"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/utils"
+	"github.com/spf13/cobra"
+	"os"
+	"path/filepath"
+)
+
+func createBundleCmd() *cobra.Command {
+	var bundleCmd = &cobra.Command{
+		Use:   "bundle",
+		Short: "Carry nested modules into offline environments",
+		RunE:  cmdInternal.PrintUsage,
+	}
+
+	bundleCmd.AddCommand(&cobra.Command{
+		Use:   "create [file]",
+		Short: "Write the configuration and a git bundle of every nested module into a file",
+		RunE:  cmdInternal.RunWrapper(wrapBundleCreate, cmdInternal.ArgExactN(1)),
+	})
+	bundleCmd.AddCommand(&cobra.Command{
+		Use:   "restore [file]",
+		Short: "Clone missing nested modules from a bundle and synchronize them with the configuration",
+		RunE:  cmdInternal.RunWrapper(wrapBundleRestore, cmdInternal.ArgExactN(1)),
+	})
+
+	return bundleCmd
+}
+
+func wrapBundleCreate(cmd *cobra.Command, args []string) error {
+	return bundleCreate(args[0])
+}
+
+func wrapBundleRestore(cmd *cobra.Command, args []string) error {
+	return bundleRestore(args[0])
+}
+
+func bundleCreate(output string) error {
+	// read context
+	context, err := cmdInternal.ErrorWrappedEvaluateContext()
+	if err != nil {
+		return err
+	}
+
+	outputPath := absoluteFromWorkingDirectory(context, output)
+	actionMigrations, err := actions.CreateBundle(&context, outputPath)
+	if err != nil {
+		return err
+	}
+
+	err = migrations.RunMigrations(actionMigrations...)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("bundled %d nested modules into %s\n", len(context.Config.Submodules), outputPath.String())
+	return nil
+}
+
+func bundleRestore(bundle string) error {
+	// read context
+	context, err := cmdInternal.ErrorWrappedEvaluateContext()
+	if err != nil {
+		return err
+	}
+
+	tempDir, err := os.MkdirTemp("", constants.ApplicationName+"-bundle-")
+	if err != nil {
+		return fmt.Errorf("could not create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	dir := models.Path(tempDir)
+	manifest, err := internal.ExtractBundle(absoluteFromWorkingDirectory(context, bundle), dir)
+	if err != nil {
+		return err
+	}
+
+	// projects without configuration use the bundled one
+	if !context.ConfigFileExists {
+		config, err := utils.ReadFileToStr(dir.SJoin(constants.ConfigFileName))
+		if err != nil {
+			return fmt.Errorf("could not read bundled configuration: %w", err)
+		}
+
+		err = migrations.RunMigrations(fs.WriteFile{Path: context.ConfigFile, Content: config})
+		if err != nil {
+			return err
+		}
+
+		context, err = cmdInternal.ErrorWrappedEvaluateContext()
+		if err != nil {
+			return err
+		}
+	}
+
+	actionMigrations, err := actions.RestoreBundle(&context, dir, manifest)
+	if err != nil {
+		return err
+	}
+
+	err = migrations.RunMigrations(actionMigrations...)
+	if err != nil {
+		return err
+	}
+
+	// refs, additional remotes and signatures are applied like 'sync --from-config', but nothing is fetched
+	actionMigrations, err = actions.ApplyConfigToModulesOffline(&context)
+	if err != nil {
+		return fmt.Errorf("could not apply configuration to bundled modules: %w", err)
+	}
+
+	var changes []internal.StateChange
+	actionMigrations = append(actionMigrations, mcontext.WriteConfigFiles{Context: &context}, mcontext.RecordState{Context: &context, Command: "bundle restore", Changes: &changes})
+	err = migrations.RunMigrations(actionMigrations...)
+	if err != nil {
+		return err
+	}
+
+	printStateChanges(changes)
+	return nil
+}
+
+/*
+absoluteFromWorkingDirectory resolves a path argument relative to the working directory.
+*/
+func absoluteFromWorkingDirectory(context models.NestContext, p string) models.Path {
+	if filepath.IsAbs(p) {
+		return models.Path(p)
+	}
+
+	return context.WorkingDirectory.SJoin(p)
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	cmdInternal "github.com/jeftadlvw/git-nest/cmd/internal"
+	"github.com/jeftadlvw/git-nest/internal"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/spf13/cobra"
+	"os"
+	"path/filepath"
+)
+
+func createCheckStagedCmd() *cobra.Command {
+	var checkStagedCmd = &cobra.Command{
+		Use:   "check-staged",
+		Short: "Check that no nested module contents are staged in the parent repository",
+		Long: `Check that no nested module contents are staged in the parent repository.
+
+Fails if any staged path lies within a nested module, or if the configuration
+and the ignore files are out of sync. Intended to be run as pre-commit hook,
+see 'git nest hooks install --pre-commit'.`,
+		RunE: cmdInternal.RunWrapper(wrapCheckStaged, cmdInternal.ArgNone()),
+	}
+
+	return checkStagedCmd
+}
+
+func wrapCheckStaged(cmd *cobra.Command, args []string) error {
+	return checkStaged()
+}
+
+func checkStaged() error {
+	// read context
+	context, err := cmdInternal.ErrorWrappedEvaluateContext()
+	if err != nil {
+		return err
+	}
+
+	// git passes a temporary index to hooks of e.g. 'git commit -a'
+	indexFile := models.Path(os.Getenv("GIT_INDEX_FILE"))
+	if !indexFile.Empty() && !filepath.IsAbs(indexFile.String()) {
+		indexFile = context.WorkingDirectory.Join(indexFile)
+	}
+
+	problemCount := 0
+
+	stagedNestedPaths, err := internal.FindStagedNestedPaths(context, indexFile)
+	if err != nil {
+		return err
+	}
+
+	for _, stagedNestedPath := range stagedNestedPaths {
+		fmt.Printf("staged path %s lies within nested module %s\n", stagedNestedPath.Path, stagedNestedPath.Submodule.UnixString())
+		problemCount++
+	}
+	if len(stagedNestedPaths) != 0 {
+		fmt.Println("unstage with: git rm -r --cached <path>")
+	}
+
+	missing, superfluous, err := internal.SubmoduleIgnoreConfigDiff(context)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range missing {
+		fmt.Printf("nested module %s is not ignored by the parent repository\n", entry)
+		problemCount++
+	}
+	for _, entry := range superfluous {
+		fmt.Printf("ignore files contain %s, which is not a configured nested module\n", entry)
+		problemCount++
+	}
+	if len(missing)+len(superfluous) != 0 {
+		fmt.Println("update the ignore files with: git nest sync")
+	}
+
+	if problemCount != 0 {
+		return fmt.Errorf("%d problem(s) found", problemCount)
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	cmdInternal "github.com/jeftadlvw/git-nest/cmd/internal"
+	"github.com/jeftadlvw/git-nest/internal"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/spf13/cobra"
+	"time"
+)
+
+func createHistoryCmd() *cobra.Command {
+	var historyCmd = &cobra.Command{
+		Use:   "history [path]",
+		Short: "Show how nested modules changed over time",
+		RunE:  cmdInternal.RunWrapper(wrapHistory, cmdInternal.ArgMaxN(1)),
+	}
+
+	historyCmd.Flags().IntP("limit", "n", 20, "maximum number of entries to show, 0 shows all")
+
+	return historyCmd
+}
+
+func wrapHistory(cmd *cobra.Command, args []string) error {
+	limit, _ := cmd.Flags().GetInt("limit")
+
+	var p models.Path
+	if len(args) == 1 {
+		p = models.Path(args[0])
+	}
+
+	return history(p, limit)
+}
+
+func history(p models.Path, limit int) error {
+	// read context
+	context, err := cmdInternal.ErrorWrappedEvaluateContext()
+	if err != nil {
+		return err
+	}
+
+	stateFile := internal.StateFileFromContext(context)
+	if stateFile.Empty() {
+		return fmt.Errorf("history is only recorded in git repositories")
+	}
+
+	state, err := internal.ReadState(stateFile)
+	if err != nil {
+		return err
+	}
+
+	filter := ""
+	if !p.Empty() {
+		relativeToRoot, err := internal.PathRelativeToRootWithJoinedOriginIfNotAbs(context.ProjectRoot, context.WorkingDirectory, p)
+		if err != nil {
+			return fmt.Errorf("internal error: could not find relative to project root: %w", err)
+		}
+		filter = relativeToRoot.UnixString()
+	}
+
+	printed := 0
+	for index := len(state.History) - 1; index >= 0 && (limit <= 0 || printed < limit); index-- {
+		entry := state.History[index]
+
+		var changes []internal.StateChange
+		for _, change := range entry.Changes {
+			if filter == "" || change.Path == filter {
+				changes = append(changes, change)
+			}
+		}
+
+		if len(changes) == 0 {
+			continue
+		}
+
+		fmt.Printf("%s  %s\n", entry.Time.Local().Format(time.DateTime), entry.Command)
+		for _, change := range changes {
+			fmt.Printf("  %s\n", change)
+		}
+		printed++
+	}
+
+	if printed == 0 {
+		fmt.Println("no recorded changes")
+	}
+
+	return nil
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/jeftadlvw/git-nest/actions"
+	cmdInternal "github.com/jeftadlvw/git-nest/cmd/internal"
+	"github.com/jeftadlvw/git-nest/interfaces"
+	"github.com/jeftadlvw/git-nest/internal"
+	"github.com/jeftadlvw/git-nest/migrations"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/spf13/cobra"
+	"slices"
+	"text/tabwriter"
+)
+
+func createHooksCmd() *cobra.Command {
+	var hooksCmd = &cobra.Command{
+		Use:   "hooks",
+		Short: "Manage git hooks that synchronize nested modules after checkouts and merges",
+		RunE:  cmdInternal.PrintUsage,
+	}
+
+	installCmd := &cobra.Command{
+		Use:   "install",
+		Short: "Install hooks, chaining existing ones",
+		RunE:  cmdInternal.RunWrapper(wrapHooksInstall, cmdInternal.ArgNone()),
+	}
+	installCmd.Flags().Bool("pre-commit", false, "also install a pre-commit hook that runs check-staged")
+	hooksCmd.AddCommand(installCmd)
+
+	hooksCmd.AddCommand(&cobra.Command{
+		Use:   "uninstall",
+		Short: "Remove hooks and restore chained ones",
+		RunE:  cmdInternal.RunWrapper(wrapHooksUninstall, cmdInternal.ArgNone()),
+	})
+	hooksCmd.AddCommand(&cobra.Command{
+		Use:   "status",
+		Short: "Show the installation state of each hook",
+		RunE:  cmdInternal.RunWrapper(wrapHooksStatus, cmdInternal.ArgNone()),
+	})
+
+	return hooksCmd
+}
+
+func wrapHooksInstall(cmd *cobra.Command, args []string) error {
+	preCommit, _ := cmd.Flags().GetBool("pre-commit")
+
+	hooks := slices.Clone(internal.SyncHooks)
+	if preCommit {
+		hooks = append(hooks, internal.PreCommitHook)
+	}
+
+	return changeHooks(func(context *models.NestContext) ([]interfaces.Migration, error) {
+		return actions.InstallHooks(context, hooks)
+	}, "installed git-nest hooks")
+}
+
+func wrapHooksUninstall(cmd *cobra.Command, args []string) error {
+	return changeHooks(actions.UninstallHooks, "removed git-nest hooks")
+}
+
+func wrapHooksStatus(cmd *cobra.Command, args []string) error {
+	return printHooksStatus()
+}
+
+func changeHooks(action func(*models.NestContext) ([]interfaces.Migration, error), successMsg string) error {
+	// read context
+	context, err := cmdInternal.ErrorWrappedEvaluateContext()
+	if err != nil {
+		return err
+	}
+
+	actionMigrations, err := action(&context)
+	if err != nil {
+		return err
+	}
+
+	migrationError := migrations.RunMigrations(actionMigrations...)
+	if migrationError != nil {
+		return migrationError
+	}
+
+	fmt.Println(successMsg)
+	return nil
+}
+
+func printHooksStatus() error {
+	// read context
+	context, err := cmdInternal.ErrorWrappedEvaluateContext()
+	if err != nil {
+		return err
+	}
+
+	if !context.IsGitInstalled || context.GitRepositoryRoot.Empty() {
+		return fmt.Errorf("project is not located within a git repository")
+	}
+
+	hooksDir, err := internal.HooksDirectory(context.ProjectRoot)
+	if err != nil {
+		return err
+	}
+
+	buffer := bytes.NewBufferString("")
+	tabWriter := tabwriter.NewWriter(buffer, 5, 0, 1, ' ', tabwriter.TabIndent)
+
+	_, _ = fmt.Fprintf(tabWriter, "hook\tstatus\n")
+	for _, hook := range internal.ManagedHooks {
+		status, err := internal.HookStatus(hooksDir, hook)
+
+		statusStr := ""
+		switch {
+		case err != nil:
+			statusStr = "error: " + err.Error()
+		case status == internal.HOOK_STATUS_INSTALLED:
+			statusStr = "installed"
+		case status == internal.HOOK_STATUS_INSTALLED_CHAINED:
+			statusStr = "installed (chains existing hook)"
+		case status == internal.HOOK_STATUS_FOREIGN:
+			statusStr = "not installed (foreign hook present)"
+		default:
+			statusStr = "not installed"
+		}
+
+		_, _ = fmt.Fprintf(tabWriter, "%s\t%s\n", hook, statusStr)
+	}
+	_ = tabWriter.Flush()
+
+	fmt.Printf("hooks directory: %s\n\n", hooksDir)
+	fmt.Print(buffer.String())
+	return nil
+}
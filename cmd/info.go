@@ -5,6 +5,7 @@ import (
 	cmdInternal "github.com/jeftadlvw/git-nest/cmd/internal"
 	"github.com/jeftadlvw/git-nest/internal"
 	"github.com/jeftadlvw/git-nest/internal/constants"
+	"github.com/jeftadlvw/git-nest/models/urls"
 	"github.com/jeftadlvw/git-nest/utils"
 	"github.com/spf13/cobra"
 	"path/filepath"
@@ -38,7 +39,8 @@ func printDebugInformation(redact bool) error {
 	workingDir := context.WorkingDirectory.String()
 	rootDir := context.ProjectRoot.String()
 	repositoryRoot := context.GitRepositoryRoot.String()
-	validNestedModules := internal.ValidSubmodulesCount(context.Config.Submodules, context.ProjectRoot)
+	gitDir := context.GitCommonDir.String()
+	validNestedModules := internal.ValidSubmodulesCount(context.Config.Submodules, context.ProjectRoot, context.Config.Config.SshHttpsEquivalent)
 
 	// beautify compilation time output
 	compilationTime := "unknown"
@@ -81,9 +83,41 @@ func printDebugInformation(redact bool) error {
 			repositoryRoot = "error"
 		}
 
+		gitDir, err = filepath.Rel(workingDir, gitDir)
+		if err != nil {
+			gitDir = "error"
+		}
+
 		workingDir = "."
 	}
 
+	// credentials are described without their secrets
+	var credentialsInfo interface{} = "none"
+	if len(context.Config.Config.Credentials) != 0 {
+		if redact {
+			credentialsInfo = fmt.Sprintf("%d configured", len(context.Config.Config.Credentials))
+		} else {
+			var credentialNodes []utils.Node
+			for _, credential := range context.Config.Config.Credentials {
+				credentialNodes = append(credentialNodes, utils.Node{Key: credential.Host, Value: internal.CredentialDescription(credential)})
+			}
+			credentialsInfo = credentialNodes
+		}
+	}
+
+	var urlRewritesInfo interface{} = "none"
+	if len(context.UrlRewrites) != 0 {
+		if redact {
+			urlRewritesInfo = fmt.Sprintf("%d configured", len(context.UrlRewrites))
+		} else {
+			var urlRewriteNodes []utils.Node
+			for _, rewrite := range context.UrlRewrites {
+				urlRewriteNodes = append(urlRewriteNodes, utils.Node{Key: urls.RedactCredentials(rewrite.Prefix), Value: urls.RedactCredentials(rewrite.Replacement)})
+			}
+			urlRewritesInfo = urlRewriteNodes
+		}
+	}
+
 	infoMap := []utils.Node{
 		{"Binary", []utils.Node{
 			{"Version", constants.Version()},
@@ -99,8 +133,12 @@ func printDebugInformation(redact bool) error {
 			{"Git installed", gitInstalledString},
 			{"Git repository", context.IsGitRepository},
 			{"Repository root", repositoryRoot},
+			{"Git directory", gitDir},
 		}},
 
+		{"Credentials", credentialsInfo},
+		{"URL rewrites", urlRewritesInfo},
+
 		{"Valid modules", fmt.Sprintf("%d/%d", validNestedModules, len(context.Config.Submodules))},
 	}
 
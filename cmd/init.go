@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/actions"
+	cmdInternal "github.com/jeftadlvw/git-nest/cmd/internal"
+	"github.com/jeftadlvw/git-nest/internal"
+	"github.com/jeftadlvw/git-nest/migrations"
+	mcontext "github.com/jeftadlvw/git-nest/migrations/context"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/utils"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+func createInitCmd() *cobra.Command {
+	var initCmd = &cobra.Command{
+		Use:   "init",
+		Short: "Initialize a git-nest project in the current directory",
+		RunE:  cmdInternal.RunWrapper(wrapInitProject, cmdInternal.ArgNone()),
+	}
+
+	initCmd.Flags().Bool("config-dir", false, "create configuration file in .config subdirectory")
+	initCmd.Flags().Bool("allow-duplicate-origins", false, "allow nested modules with the same remote origin")
+	initCmd.Flags().Bool("allow-unequal-roots", false, "allow project and git repository roots to differ")
+	initCmd.Flags().String("ignore-mode", "", "where nested modules are ignored (exclude, gitignore, both)")
+	initCmd.Flags().String("gitignore-location", "", "which .gitignore files nested modules are ignored in (root, directory)")
+	initCmd.Flags().Bool("hooks", false, "install git hooks that synchronize nested modules after checkouts and merges")
+
+	return initCmd
+}
+
+func wrapInitProject(cmd *cobra.Command, args []string) error {
+	useConfigDir, _ := cmd.Flags().GetBool("config-dir")
+	allowDuplicateOrigins, _ := cmd.Flags().GetBool("allow-duplicate-origins")
+	allowUnequalRoots, _ := cmd.Flags().GetBool("allow-unequal-roots")
+	ignoreMode, _ := cmd.Flags().GetString("ignore-mode")
+	gitignoreLocation, _ := cmd.Flags().GetString("gitignore-location")
+	installHooks, _ := cmd.Flags().GetBool("hooks")
+
+	return initProject(useConfigDir, models.Config{
+		AllowDuplicateOrigins: allowDuplicateOrigins,
+		AllowUnequalRoots:     allowUnequalRoots,
+		IgnoreMode:            ignoreMode,
+		GitignoreLocation:     gitignoreLocation,
+	}, installHooks)
+}
+
+func initProject(useConfigDir bool, config models.Config, installHooks bool) error {
+	// read context; the configuration of an enclosing project is of no interest
+	context, err := internal.CreateContextFromCurrentWorkingDir()
+	if err != nil {
+		return fmt.Errorf("internal context error: %w", err)
+	}
+
+	actionMigrations, warnings, err := actions.InitializeProject(&context, useConfigDir, config)
+	if err != nil {
+		return err
+	}
+
+	for _, warning := range warnings {
+		utils.Logger().Info("initialization warning", "warning", warning)
+		if !cmdInternal.Quiet() {
+			_, _ = fmt.Fprintf(os.Stderr, "warning: %s\n", warning)
+		}
+	}
+
+	if context.ConfigFileExists && !cmdInternal.Quiet() {
+		_, _ = fmt.Fprintf(os.Stderr, "note: creating a project within existing project %s\n", context.ProjectRoot)
+	}
+
+	actionMigrations = append(actionMigrations, mcontext.WriteConfigFiles{Context: &context})
+	migrationError := migrations.RunMigrations(actionMigrations...)
+	if migrationError != nil {
+		return migrationError
+	}
+
+	fmt.Printf("initialized git-nest project at %s\n", context.ConfigFile)
+
+	if !installHooks {
+		return nil
+	}
+
+	// hooks are evaluated against the freshly initialized context
+	hookMigrations, err := actions.InstallHooks(&context, internal.SyncHooks)
+	if err != nil {
+		return fmt.Errorf("could not install hooks: %w", err)
+	}
+
+	migrationError = migrations.RunMigrations(hookMigrations...)
+	if migrationError != nil {
+		return fmt.Errorf("could not install hooks: %w", migrationError)
+	}
+
+	fmt.Println("installed git-nest hooks")
+	return nil
+}
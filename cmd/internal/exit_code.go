@@ -0,0 +1,75 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/utils"
+)
+
+/*
+Exit codes of git-nest. Failed git operations are mapped to distinct exit codes, so that scripts can react to them.
+*/
+const (
+	ExitCodeSuccess             = 0
+	ExitCodeError               = 1
+	ExitCodeNotARepository      = 3
+	ExitCodeRefNotFound         = 4
+	ExitCodeRepositoryNotFound  = 5
+	ExitCodeAuthRequired        = 6
+	ExitCodeMergeConflict       = 7
+	ExitCodeLocalChanges        = 8
+	ExitCodeDivergedBranches    = 9
+	ExitCodeNoUpstream          = 10
+	ExitCodeDestinationExists   = 11
+	ExitCodeSignatureUnverified = 12
+	ExitCodeNetwork             = 13
+	ExitCodeTimeout             = 124
+	ExitCodeCancelled           = 130
+)
+
+/*
+exitCodes maps the sentinel errors of failed git operations to their exit code.
+*/
+var exitCodes = []struct {
+	err      error
+	exitCode int
+}{
+	{context.Canceled, ExitCodeCancelled},
+	{context.DeadlineExceeded, ExitCodeTimeout},
+	{utils.ErrNotARepository, ExitCodeNotARepository},
+	{utils.ErrRefNotFound, ExitCodeRefNotFound},
+	{utils.ErrRepositoryNotFound, ExitCodeRepositoryNotFound},
+	{utils.ErrAuthRequired, ExitCodeAuthRequired},
+	{utils.ErrMergeConflict, ExitCodeMergeConflict},
+	{utils.ErrLocalChanges, ExitCodeLocalChanges},
+	{utils.ErrDivergedBranches, ExitCodeDivergedBranches},
+	{utils.ErrNoUpstream, ExitCodeNoUpstream},
+	{utils.ErrDestinationExists, ExitCodeDestinationExists},
+	{utils.ErrGitSignatureUnverified, ExitCodeSignatureUnverified},
+}
+
+/*
+ExitCode returns the exit code for an error returned by a command. Errors of unknown kind result in ExitCodeError.
+*/
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitCodeSuccess
+	}
+
+	for _, entry := range exitCodes {
+		if errors.Is(err, entry.err) {
+			return entry.exitCode
+		}
+	}
+
+	var gitErr *utils.GitError
+	if errors.As(err, &gitErr) {
+		switch utils.ClassifyGitError(gitErr.Err, gitErr.Stderr) {
+		case models.GitErrorClassNetwork, models.GitErrorClassServer:
+			return ExitCodeNetwork
+		}
+	}
+
+	return ExitCodeError
+}
@@ -5,9 +5,15 @@ import (
 	"github.com/jeftadlvw/git-nest/internal"
 	application_internal "github.com/jeftadlvw/git-nest/internal"
 	"github.com/jeftadlvw/git-nest/internal/constants"
+	"github.com/jeftadlvw/git-nest/migrations"
 	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/utils"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang.org/x/term"
 	"os"
+	"slices"
+	"strings"
 )
 
 /*
@@ -56,6 +62,62 @@ func GetProjectRootFromCwd() (models.Path, error) {
 	return projectRoot, nil
 }
 
+/*
+policyFile contains the path to a policy file that overrides the project's policy.
+*/
+var policyFile models.Path
+
+/*
+SetPolicyFile sets a policy file that overrides the project's policy in every evaluated context.
+*/
+func SetPolicyFile(p string) {
+	policyFile = models.Path(strings.TrimSpace(p))
+}
+
+/*
+commandArgs contains the arguments of the running command, see SetCommandArgs.
+*/
+var commandArgs []string
+
+/*
+SetCommandArgs sets the arguments of the running command that are journaled, see CommandArgs.
+*/
+func SetCommandArgs(cmd *cobra.Command, args []string) {
+	commandArgs = CommandArgs(cmd, args)
+}
+
+/*
+CommandArgs returns the arguments that run a command again: its path below the root command, all flags that were set
+and its positional arguments. In contrast to os.Args, they describe the command that is actually executed,
+e.g. the interrupted command that recover runs again.
+*/
+func CommandArgs(cmd *cobra.Command, args []string) []string {
+	var commandArgs []string
+
+	path := strings.Fields(cmd.CommandPath())
+	if len(path) > 1 {
+		commandArgs = append(commandArgs, path[1:]...)
+	}
+
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		if slice, ok := f.Value.(pflag.SliceValue); ok {
+			for _, value := range slice.GetSlice() {
+				commandArgs = append(commandArgs, fmt.Sprintf("--%s=%s", f.Name, value))
+			}
+			return
+		}
+
+		commandArgs = append(commandArgs, fmt.Sprintf("--%s=%s", f.Name, f.Value.String()))
+	})
+
+	// positional arguments that look like flags are separated
+	if slices.ContainsFunc(args, func(arg string) bool { return strings.HasPrefix(arg, "-") }) {
+		commandArgs = append(commandArgs, "--")
+	}
+
+	return append(commandArgs, args...)
+}
+
 /*
 ErrorWrappedEvaluateContext is a wrapper for the cmd package to remove repetitive boilerplate code.
 It returns the evaluated context or a preformatted error.
@@ -66,6 +128,37 @@ func ErrorWrappedEvaluateContext() (models.NestContext, error) {
 		return models.NestContext{}, fmt.Errorf("internal context error: %w.\nPlease fix any configuration errors to proceed", err)
 	}
 
+	if !policyFile.Empty() {
+		policy, err := internal.ReadPolicyFile(policyFile)
+		if err != nil {
+			return models.NestContext{}, err
+		}
+
+		context.Policy = policy
+		context.PolicyFile = policyFile
+	}
+
+	// configured credentials and url rewrites are used by every git command that contacts a remote
+	utils.SetGitConfigParameters(internal.CredentialGitConfigParameters(context.Config.Config.Credentials)...)
+	utils.SetGitUrlRewrites(context.UrlRewrites...)
+	utils.SetGitTimeouts(context.Config.Config.Timeouts.GitTimeouts())
+	utils.SetGitRetryPolicy(context.Config.Config.Retry.RetryPolicy())
+	utils.SetGitRetryNotifier(func(message string) {
+		utils.Logger().Info("retrying git operation", "reason", message)
+		if !quiet {
+			application_internal.ProgressReporter().Message(fmt.Sprintf("warning: %s", message))
+		}
+	})
+
+	// migrations are journaled, so that interrupted runs can be recovered
+	journalFile := internal.JournalFileFromContext(context)
+	migrations.SetJournal(journalFile, commandArgs, internal.JournalBackupFiles(context))
+
+	journal, err := internal.ReadJournal(journalFile)
+	if err == nil && journal != nil && !quiet {
+		_, _ = fmt.Fprintf(os.Stderr, "warning: %s.\nRun '%s recover' to resume or roll it back.\n", journal, constants.ApplicationName)
+	}
+
 	return context, nil
 }
 
@@ -88,3 +181,40 @@ func GetApplicationMutex() (internal.LockFile, error) {
 
 	return lf, nil
 }
+
+/*
+SetNonInteractiveGitEnvironment configures the process environment so that git and ssh,
+which are started as child processes, fail instead of prompting for input.
+*/
+func SetNonInteractiveGitEnvironment() error {
+	err := os.Setenv("GIT_TERMINAL_PROMPT", "0")
+	if err != nil {
+		return fmt.Errorf("could not set environment: %w", err)
+	}
+
+	if _, ok := os.LookupEnv("GIT_SSH_COMMAND"); !ok {
+		err = os.Setenv("GIT_SSH_COMMAND", "ssh -o BatchMode=yes")
+		if err != nil {
+			return fmt.Errorf("could not set environment: %w", err)
+		}
+	}
+
+	return nil
+}
+
+/*
+ConfigureInteractivity switches git to non-interactive mode if requested or if standard input is not a terminal,
+so that missing credentials result in an error instead of a prompt that cannot be answered.
+*/
+func ConfigureInteractivity(nonInteractive bool) error {
+	interactive = !nonInteractive && term.IsTerminal(int(os.Stdin.Fd()))
+
+	// child processes that do not prompt can be terminated together with their own children
+	utils.SetIsolateProcessGroups(!interactive)
+
+	if interactive {
+		return nil
+	}
+
+	return SetNonInteractiveGitEnvironment()
+}
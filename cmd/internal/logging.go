@@ -0,0 +1,70 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	application_internal "github.com/jeftadlvw/git-nest/internal"
+	"github.com/jeftadlvw/git-nest/internal/constants"
+	"github.com/jeftadlvw/git-nest/models/urls"
+	"github.com/jeftadlvw/git-nest/utils"
+	"io"
+	"log/slog"
+	"os"
+	"runtime"
+	"strings"
+)
+
+/*
+quiet defines whether warnings and other informational output are suppressed, see ConfigureLogging.
+*/
+var quiet bool
+
+/*
+Quiet returns whether warnings and other informational output are suppressed.
+*/
+func Quiet() bool {
+	return quiet
+}
+
+/*
+ConfigureLogging configures the logger of git-nest. Records are written to stderr, their level depends on
+verbosity and quiet, see application_internal.LogLevel. If traceFile is not empty, all records are additionally
+written to it in json format, e.g. for bug reports.
+*/
+func ConfigureLogging(verbosity int, quietFlag bool, format string, traceFile string) error {
+	if quietFlag && verbosity > 0 {
+		return errors.New("--quiet cannot be combined with --verbose")
+	}
+
+	logFormat, err := application_internal.ParseLogFormat(format)
+	if err != nil {
+		return err
+	}
+
+	var trace io.Writer
+	if traceFile = strings.TrimSpace(traceFile); traceFile != "" {
+		f, err := os.OpenFile(traceFile, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+		if err != nil {
+			return fmt.Errorf("could not open trace file: %w", err)
+		}
+		application_internal.AddCleanup(f.Close)
+		trace = f
+	}
+
+	quiet = quietFlag
+	utils.SetLogger(application_internal.NewLogger(os.Stderr, application_internal.LogLevel(verbosity, quietFlag), logFormat, trace))
+
+	args := make([]string, 0, len(os.Args))
+	for _, arg := range os.Args[1:] {
+		args = append(args, urls.RedactCredentials(arg))
+	}
+
+	utils.Logger().Debug("starting",
+		slog.String("version", constants.Version()),
+		slog.Any("args", args),
+		slog.String("os", runtime.GOOS),
+		slog.String("arch", runtime.GOARCH),
+	)
+
+	return nil
+}
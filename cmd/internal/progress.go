@@ -0,0 +1,47 @@
+package internal
+
+import (
+	application_internal "github.com/jeftadlvw/git-nest/internal"
+	"golang.org/x/term"
+	"os"
+)
+
+/*
+ConfigureProgress configures how the progress of clones and pulls is reported. In auto mode, progress bars are rendered
+if stderr is a terminal and plain lines are printed otherwise. No progress is reported if output is quiet.
+*/
+func ConfigureProgress(mode string) error {
+	progressMode, err := application_internal.ParseProgressMode(mode)
+	if err != nil {
+		return err
+	}
+
+	stderrFd := int(os.Stderr.Fd())
+	if progressMode == application_internal.ProgressModeAuto {
+		progressMode = application_internal.ProgressModePlain
+		if term.IsTerminal(stderrFd) && os.Getenv("TERM") != "dumb" {
+			progressMode = application_internal.ProgressModeTty
+		}
+	}
+
+	if quiet {
+		progressMode = application_internal.ProgressModeNone
+	}
+
+	switch progressMode {
+	case application_internal.ProgressModeTty:
+		application_internal.SetProgressReporter(application_internal.NewTtyProgressReporter(os.Stderr, func() int {
+			width, _, err := term.GetSize(stderrFd)
+			if err != nil {
+				return 0
+			}
+			return width
+		}))
+	case application_internal.ProgressModePlain:
+		application_internal.SetProgressReporter(application_internal.NewPlainProgressReporter(os.Stderr))
+	default:
+		application_internal.SetProgressReporter(application_internal.NewSilentProgressReporter(os.Stderr))
+	}
+
+	return nil
+}
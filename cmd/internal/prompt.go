@@ -0,0 +1,38 @@
+package internal
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+/*
+interactive defines whether the user can be prompted for input, see ConfigureInteractivity.
+*/
+var interactive bool
+
+/*
+IsInteractive returns whether the user can be prompted for input.
+*/
+func IsInteractive() bool {
+	return interactive
+}
+
+/*
+Confirm asks the user a yes/no question on standard input. Returns false if the user cannot be prompted.
+*/
+func Confirm(question string) bool {
+	if !interactive {
+		return false
+	}
+
+	fmt.Printf("%s [y/N] ", question)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
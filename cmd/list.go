@@ -34,7 +34,7 @@ func printSubmodules() error {
 		return nil
 	}
 
-	submodulesExist := internal.SubmodulesExist(context.Config.Submodules, context.ProjectRoot)
+	submodulesExist := internal.SubmodulesExist(context.Config.Submodules, context.ProjectRoot, context.Config.Config.SshHttpsEquivalent)
 
 	buffer := bytes.NewBufferString("")
 	tabWriter := tabwriter.NewWriter(buffer, 5, 0, 1, ' ', tabwriter.TabIndent)
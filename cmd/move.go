@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"github.com/jeftadlvw/git-nest/actions"
+	"github.com/jeftadlvw/git-nest/cmd/internal"
+	"github.com/jeftadlvw/git-nest/migrations"
+	mcontext "github.com/jeftadlvw/git-nest/migrations/context"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/spf13/cobra"
+)
+
+func createMoveCommand() *cobra.Command {
+	var mvCmd = &cobra.Command{
+		Use:     "move [old] [new]",
+		Aliases: []string{"mv"},
+		Short:   "Move or rename a submodule within this project",
+		RunE:    internal.RunWrapper(wrapMoveSubmodule, internal.ArgExactN(2)),
+	}
+
+	return mvCmd
+}
+
+func wrapMoveSubmodule(cmd *cobra.Command, args []string) error {
+	return moveSubmodule(models.Path(args[0]), models.Path(args[1]))
+}
+
+func moveSubmodule(oldPath models.Path, newPath models.Path) error {
+	// read context
+	context, err := internal.ErrorWrappedEvaluateContext()
+	if err != nil {
+		return err
+	}
+
+	actionMigrations, err := actions.MoveSubmoduleInContext(&context, oldPath, newPath)
+	if err != nil {
+		return err
+	}
+
+	actionMigrations = append(actionMigrations, mcontext.WriteConfigFiles{Context: &context}, mcontext.RecordState{Context: &context, Command: "move"})
+	migrationError := migrations.RunMigrations(actionMigrations...)
+	if migrationError != nil {
+		return migrationError
+	}
+
+	return nil
+}
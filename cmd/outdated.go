@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	cmdInternal "github.com/jeftadlvw/git-nest/cmd/internal"
+	"github.com/jeftadlvw/git-nest/internal"
+	"github.com/spf13/cobra"
+	"strings"
+	"text/tabwriter"
+)
+
+func createOutdatedCmd() *cobra.Command {
+	var outdatedCmd = &cobra.Command{
+		Use:   "outdated",
+		Short: "Report nested modules that lag behind their remotes",
+		RunE:  cmdInternal.RunWrapper(wrapOutdated, cmdInternal.ArgNone()),
+	}
+
+	outdatedCmd.Flags().StringP("format", "f", "table", "output format (table, json)")
+	outdatedCmd.Flags().Bool("exit-code", false, "exit with a non-zero code if any module is outdated")
+
+	return outdatedCmd
+}
+
+func wrapOutdated(cmd *cobra.Command, args []string) error {
+	format, _ := cmd.Flags().GetString("format")
+	exitCode, _ := cmd.Flags().GetBool("exit-code")
+
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format != "table" && format != "json" {
+		return fmt.Errorf("unsupported output format '%s'", format)
+	}
+
+	return printOutdated(format, exitCode)
+}
+
+func printOutdated(format string, exitCode bool) error {
+	// read context
+	context, err := cmdInternal.ErrorWrappedEvaluateContext()
+	if err != nil {
+		return err
+	}
+
+	if !context.IsGitInstalled {
+		return errors.New("please install git in order to query remotes")
+	}
+
+	if len(context.Config.Submodules) == 0 && format == "table" {
+		fmt.Println(cmdInternal.NoNestedModulesMsg)
+		return nil
+	}
+
+	reports := internal.SubmodulesOutdated(context.Config.Submodules, context.ProjectRoot)
+
+	switch format {
+	case "json":
+		if reports == nil {
+			reports = []internal.SubmoduleOutdatedReport{}
+		}
+
+		out, err := json.MarshalIndent(reports, "", "  ")
+		if err != nil {
+			return fmt.Errorf("internal error: could not format json: %w", err)
+		}
+		fmt.Println(string(out))
+	default:
+		fmt.Println(fmtOutdatedTable(reports))
+	}
+
+	outdatedCount := 0
+	for _, report := range reports {
+		if report.Outdated || report.Error != "" {
+			outdatedCount++
+		}
+	}
+
+	if exitCode && outdatedCount != 0 {
+		return fmt.Errorf("%d nested module(s) outdated or not checkable", outdatedCount)
+	}
+
+	return nil
+}
+
+func fmtOutdatedTable(reports []internal.SubmoduleOutdatedReport) string {
+	buffer := bytes.NewBufferString("")
+	tabWriter := tabwriter.NewWriter(buffer, 5, 0, 1, ' ', tabwriter.TabIndent)
+
+	_, _ = fmt.Fprintf(tabWriter, "i\tpath\tcurrent\tlatest tag\tbranch tip\tbehind\tstatus\n")
+	for index, report := range reports {
+		current := report.CurrentRef
+		if report.CurrentSha != "" {
+			current = strings.TrimSpace(current + " " + shortSha(report.CurrentSha))
+		}
+		if current == "" {
+			current = "-"
+		}
+
+		latestTag := report.LatestTag
+		if latestTag == "" {
+			latestTag = "-"
+		}
+
+		branchTip := "-"
+		if report.BranchTip != "" {
+			branchTip = report.Branch + " " + shortSha(report.BranchTip)
+		}
+
+		behind := "?"
+		if report.Behind >= 0 {
+			behind = fmt.Sprintf("%d", report.Behind)
+		}
+
+		_, _ = fmt.Fprintf(tabWriter, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n", index+1, report.Path, current, latestTag, branchTip, behind, report.Status())
+	}
+	_ = tabWriter.Flush()
+
+	return buffer.String()
+}
+
+func shortSha(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/actions"
+	cmdInternal "github.com/jeftadlvw/git-nest/cmd/internal"
+	"github.com/jeftadlvw/git-nest/internal"
+	"github.com/jeftadlvw/git-nest/migrations"
+	mcontext "github.com/jeftadlvw/git-nest/migrations/context"
+	"github.com/spf13/cobra"
+)
+
+func createPruneCmd() *cobra.Command {
+	var pruneCmd = &cobra.Command{
+		Use:   "prune",
+		Short: "Delete directories of nested modules that were removed from the configuration",
+		RunE:  cmdInternal.RunWrapper(wrapPrune, cmdInternal.ArgNone()),
+	}
+
+	pruneCmd.Flags().BoolP("yes", "y", false, "delete without asking for confirmation")
+	pruneCmd.Flags().BoolP("force", "f", false, "also delete directories with uncommitted changes or unpushed commits")
+	pruneCmd.Flags().Bool("dry-run", false, "only list orphaned directories")
+
+	return pruneCmd
+}
+
+func wrapPrune(cmd *cobra.Command, args []string) error {
+	yes, _ := cmd.Flags().GetBool("yes")
+	force, _ := cmd.Flags().GetBool("force")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	return prune(yes, force, dryRun)
+}
+
+func prune(yes bool, force bool, dryRun bool) error {
+	// read context
+	context, err := cmdInternal.ErrorWrappedEvaluateContext()
+	if err != nil {
+		return err
+	}
+
+	orphans, err := internal.FindOrphanedModules(context)
+	if err != nil {
+		return err
+	}
+
+	if len(orphans) == 0 {
+		fmt.Println("no orphaned nested modules found")
+		return nil
+	}
+
+	fmt.Println("orphaned nested modules:")
+	for _, orphan := range orphans {
+		fmt.Printf("  %s (from %s)\n", orphan.Path.UnixString(), orphan.Source)
+	}
+
+	if dryRun {
+		return nil
+	}
+
+	if !yes && !cmdInternal.Confirm(fmt.Sprintf("delete %d directories?", len(orphans))) {
+		if !cmdInternal.IsInteractive() {
+			fmt.Println("use --yes to delete them without confirmation")
+		}
+		return nil
+	}
+
+	actionMigrations, err := actions.PruneOrphanedModules(&context, orphans, force)
+	if err != nil {
+		return err
+	}
+
+	// ignore entries of pruned modules are removed as well
+	actionMigrations = append(actionMigrations, mcontext.WriteConfigFiles{Context: &context}, mcontext.RecordState{Context: &context, Command: "prune"})
+	migrationError := migrations.RunMigrations(actionMigrations...)
+	if migrationError != nil {
+		return migrationError
+	}
+
+	return nil
+}
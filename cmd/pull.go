@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"github.com/jeftadlvw/git-nest/actions"
 	cmdInternal "github.com/jeftadlvw/git-nest/cmd/internal"
+	"github.com/jeftadlvw/git-nest/internal"
 	"github.com/jeftadlvw/git-nest/migrations"
+	mcontext "github.com/jeftadlvw/git-nest/migrations/context"
 	"github.com/spf13/cobra"
 )
 
@@ -39,10 +41,18 @@ func gitPullModules() error {
 		return err
 	}
 
+	// pulled modules change their recorded integrity hashes
+	if context.Config.Config.RecordIntegrity {
+		actionMigrations = append(actionMigrations, mcontext.WriteConfigFiles{Context: &context})
+	}
+
+	var changes []internal.StateChange
+	actionMigrations = append(actionMigrations, mcontext.RecordState{Context: &context, Command: "pull", Changes: &changes})
 	migrationError := migrations.RunMigrations(actionMigrations...)
 	if migrationError != nil {
 		return migrationError
 	}
 
+	printStateChanges(changes)
 	return nil
 }
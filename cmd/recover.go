@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+	cmdInternal "github.com/jeftadlvw/git-nest/cmd/internal"
+	"github.com/jeftadlvw/git-nest/internal"
+	"github.com/jeftadlvw/git-nest/internal/constants"
+	"github.com/spf13/cobra"
+	"strings"
+)
+
+func createRecoverCmd() *cobra.Command {
+	var recoverCmd = &cobra.Command{
+		Use:   "recover",
+		Short: "Resume or roll back an interrupted run",
+		Long: `Roll back what an interrupted run changed and run the interrupted command again.
+Directories and files created by the interrupted run are deleted and the configuration
+files are restored to their state before the run. Other changes, e.g. checked out refs,
+are not undone.`,
+		RunE: cmdInternal.RunWrapper(wrapRecover, cmdInternal.ArgNone()),
+	}
+
+	recoverCmd.Flags().Bool("rollback", false, "only roll back the interrupted run, without running it again")
+
+	return recoverCmd
+}
+
+func wrapRecover(cmd *cobra.Command, args []string) error {
+	rollbackOnly, _ := cmd.Flags().GetBool("rollback")
+
+	command, err := recoverInterruptedRun()
+	if err != nil || command == nil || rollbackOnly {
+		return err
+	}
+
+	fmt.Printf("running '%s %s' again\n", constants.ApplicationName, strings.Join(command, " "))
+	root := cmd.Root()
+	root.SetArgs(command)
+	return root.Execute()
+}
+
+/*
+recoverInterruptedRun rolls back an interrupted run and returns its command. Returns nil if no run has been interrupted.
+*/
+func recoverInterruptedRun() ([]string, error) {
+	// the journal is not read by ErrorWrappedEvaluateContext, which warns about it
+	context, err := internal.EvaluateContext()
+	if err != nil {
+		return nil, fmt.Errorf("internal context error: %w.\nPlease fix any configuration errors to proceed", err)
+	}
+
+	journal, err := internal.ReadJournal(internal.JournalFileFromContext(context))
+	if err != nil {
+		return nil, err
+	}
+
+	if journal == nil {
+		fmt.Println("no interrupted run found")
+		return nil, nil
+	}
+
+	fmt.Println(journal)
+	err = journal.Rollback()
+	if err != nil {
+		return nil, fmt.Errorf("could not roll back interrupted run: %w", err)
+	}
+
+	fmt.Println("rolled back interrupted run")
+	return journal.Command, nil
+}
@@ -19,6 +19,7 @@ func createRemoveCommand() *cobra.Command {
 
 	rmCmd.Flags().BoolP("delete", "d", false, "delete existing directory")
 	rmCmd.Flags().BoolP("force", "f", false, "force delete existing directory")
+	rmCmd.Flags().BoolP("keep", "k", false, "keep existing directory, so that it is not pruned")
 
 	return rmCmd
 }
@@ -26,22 +27,23 @@ func createRemoveCommand() *cobra.Command {
 func wrapRemoveSubmodule(cmd *cobra.Command, args []string) error {
 	deleteDirectory, _ := cmd.Flags().GetBool("delete")
 	forceDelete, _ := cmd.Flags().GetBool("force")
-	return removeSubmodule(models.Path(args[0]), deleteDirectory, forceDelete)
+	keepDirectory, _ := cmd.Flags().GetBool("keep")
+	return removeSubmodule(models.Path(args[0]), deleteDirectory, forceDelete, keepDirectory)
 }
 
-func removeSubmodule(p models.Path, deleteDirectory bool, forceDelete bool) error {
+func removeSubmodule(p models.Path, deleteDirectory bool, forceDelete bool, keepDirectory bool) error {
 	// read context
 	context, err := internal.ErrorWrappedEvaluateContext()
 	if err != nil {
 		return err
 	}
 
-	actionMigrations, err := actions.RemoveSubmoduleFromContext(&context, p, deleteDirectory, forceDelete)
+	actionMigrations, err := actions.RemoveSubmoduleFromContext(&context, p, deleteDirectory, forceDelete, keepDirectory)
 	if err != nil {
 		return err
 	}
 
-	actionMigrations = append(actionMigrations, mcontext.WriteConfigFiles{Context: &context})
+	actionMigrations = append(actionMigrations, mcontext.WriteConfigFiles{Context: &context}, mcontext.RecordState{Context: &context, Command: "remove"})
 	migrationError := migrations.RunMigrations(actionMigrations...)
 	if migrationError != nil {
 		return migrationError
@@ -4,7 +4,9 @@ import (
 	"github.com/jeftadlvw/git-nest/cmd/internal"
 	application_internal "github.com/jeftadlvw/git-nest/internal"
 	"github.com/jeftadlvw/git-nest/internal/constants"
+	"github.com/jeftadlvw/git-nest/utils"
 	"github.com/spf13/cobra"
+	"log/slog"
 )
 
 func Execute() (int, error) {
@@ -18,7 +20,32 @@ func Execute() (int, error) {
 		Long: `git-nest is a git command line extension for nesting external repositories
 in your project without your parent repository noticing, using native features
 and configurations files.`,
-		RunE:          internal.PrintUsage,
+		RunE: internal.PrintUsage,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			verbosity, _ := cmd.Flags().GetCount("verbose")
+			quiet, _ := cmd.Flags().GetBool("quiet")
+			logFormat, _ := cmd.Flags().GetString("log-format")
+			traceFile, _ := cmd.Flags().GetString("trace-file")
+			err := internal.ConfigureLogging(verbosity, quiet, logFormat, traceFile)
+			if err != nil {
+				return err
+			}
+
+			progress, _ := cmd.Flags().GetString("progress")
+			err = internal.ConfigureProgress(progress)
+			if err != nil {
+				return err
+			}
+
+			// recover executes the interrupted command again, so that os.Args does not describe the running command
+			internal.SetCommandArgs(cmd, args)
+
+			policyFile, _ := cmd.Flags().GetString("policy-file")
+			internal.SetPolicyFile(policyFile)
+
+			nonInteractive, _ := cmd.Flags().GetBool("non-interactive")
+			return internal.ConfigureInteractivity(nonInteractive)
+		},
 		SilenceErrors: true,
 		SilenceUsage:  true,
 	}
@@ -34,11 +61,14 @@ and configurations files.`,
 
 	// execute command handler
 	err = rootCmd.Execute()
+	exitCode := internal.ExitCode(err)
 	if err != nil {
-		return 1, err
+		utils.Logger().Debug("finished", slog.Int("exit_code", exitCode), slog.String("error", err.Error()))
+	} else {
+		utils.Logger().Debug("finished", slog.Int("exit_code", exitCode))
 	}
 
-	return 0, nil
+	return exitCode, err
 }
 
 func configureRootCommand(rootCmd *cobra.Command) {
@@ -47,15 +77,37 @@ func configureRootCommand(rootCmd *cobra.Command) {
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(createInfoCmd())
 	rootCmd.AddCommand(createVerifyCmd())
+	rootCmd.AddCommand(createOutdatedCmd())
+	rootCmd.AddCommand(createHistoryCmd())
 
 	// manage modules
+	rootCmd.AddCommand(createInitCmd())
 	rootCmd.AddCommand(createAddCmd())
 	rootCmd.AddCommand(createRemoveCommand())
+	rootCmd.AddCommand(createMoveCommand())
 	rootCmd.AddCommand(createListCmd())
+	rootCmd.AddCommand(createArchiveCmd())
+	rootCmd.AddCommand(createBundleCmd())
 
 	// housekeeping
 	rootCmd.AddCommand(createSyncCommand())
 	rootCmd.AddCommand(createPullCommand())
+	rootCmd.AddCommand(createPruneCmd())
+	rootCmd.AddCommand(createRecoverCmd())
+	rootCmd.AddCommand(createHooksCmd())
+	rootCmd.AddCommand(createCheckStagedCmd())
+
+	// global flags
+	rootCmd.PersistentFlags().Bool("non-interactive", false, "never prompt for credentials or other input")
+	rootCmd.PersistentFlags().String("policy-file", "", "policy file that overrides the project's [config.policy]")
+	rootCmd.PersistentFlags().CountP("verbose", "v", "log migrations (-v) and executed git commands (-vv) to stderr")
+	rootCmd.PersistentFlags().BoolP("quiet", "q", false, "only print errors")
+	rootCmd.PersistentFlags().String("log-format", string(application_internal.LogFormatText), "format of log output: text or json")
+	rootCmd.PersistentFlags().String("trace-file", "", "write a json log of all migrations and git commands to this file, e.g. for bug reports")
+	rootCmd.PersistentFlags().String("progress", string(application_internal.ProgressModeAuto), "how progress of clones and pulls is reported: auto, tty, plain or none")
+
+	// -v is used for verbosity
+	rootCmd.Flags().Bool("version", false, "version for "+constants.ApplicationName)
 
 	// miscellaneous configuration
 	rootCmd.CompletionOptions.DisableDefaultCmd = true
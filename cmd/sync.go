@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/jeftadlvw/git-nest/actions"
 	cmdInternal "github.com/jeftadlvw/git-nest/cmd/internal"
+	"github.com/jeftadlvw/git-nest/internal"
 	"github.com/jeftadlvw/git-nest/migrations"
 	mcontext "github.com/jeftadlvw/git-nest/migrations/context"
 	"github.com/spf13/cobra"
@@ -15,14 +16,18 @@ func createSyncCommand() *cobra.Command {
 		Short: fmt.Sprintf("Update and apply state changes"),
 		RunE:  cmdInternal.RunWrapper(wrapSync),
 	}
+
+	syncCmd.Flags().Bool("from-config", false, "treat the configuration as truth and change nested modules to match it")
+
 	return syncCmd
 }
 
 func wrapSync(cmd *cobra.Command, args []string) error {
-	return sync()
+	fromConfig, _ := cmd.Flags().GetBool("from-config")
+	return sync(fromConfig)
 }
 
-func sync() error {
+func sync(fromConfig bool) error {
 	// read context
 	context, err := cmdInternal.ErrorWrappedEvaluateContext()
 	if err != nil {
@@ -31,19 +36,46 @@ func sync() error {
 
 	if len(context.Config.Submodules) == 0 {
 		fmt.Println(cmdInternal.NoNestedModulesMsg)
-		return nil
+
+		// modules that have been removed from the configuration are still recorded
+		changes, err := internal.RecordState(context, "sync")
+		printStateChanges(changes)
+		return err
+	}
+
+	syncFunc := actions.SynchronizeConfigAndModules
+	command := "sync"
+	if fromConfig {
+		syncFunc = actions.ApplyConfigToModules
+		command = "sync --from-config"
 	}
 
-	actionMigrations, err := actions.SynchronizeConfigAndModules(&context)
+	actionMigrations, err := syncFunc(&context)
 	if err != nil {
 		return err
 	}
 
-	actionMigrations = append(actionMigrations, mcontext.WriteConfigFiles{Context: &context})
+	var changes []internal.StateChange
+	actionMigrations = append(actionMigrations, mcontext.WriteConfigFiles{Context: &context}, mcontext.RecordState{Context: &context, Command: command, Changes: &changes})
 	migrationError := migrations.RunMigrations(actionMigrations...)
 	if migrationError != nil {
 		return migrationError
 	}
 
+	printStateChanges(changes)
 	return nil
 }
+
+/*
+printStateChanges prints a summary of the changes a command applied to nested modules.
+*/
+func printStateChanges(changes []internal.StateChange) {
+	if len(changes) == 0 {
+		return
+	}
+
+	fmt.Println("changes:")
+	for _, change := range changes {
+		fmt.Printf("  %s\n", change)
+	}
+}
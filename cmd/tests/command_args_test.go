@@ -0,0 +1,58 @@
+package tests
+
+import (
+	"fmt"
+	cmdInternal "github.com/jeftadlvw/git-nest/cmd/internal"
+	"github.com/spf13/cobra"
+	"slices"
+	"testing"
+)
+
+func TestCommandArgs(t *testing.T) {
+	cases := []struct {
+		args     []string
+		expected []string
+	}{
+		{[]string{"sync"}, []string{"sync"}},
+		{[]string{"sync", "--from-config"}, []string{"sync", "--from-config=true"}},
+		{[]string{"--non-interactive", "sync", "--from-config"}, []string{"sync", "--from-config=true", "--non-interactive=true"}},
+		{[]string{"-vv", "archive", "--group", "web", "--group=server", "out.tar"}, []string{"archive", "--group=web", "--group=server", "--verbose=2", "out.tar"}},
+		{[]string{"add", "--path", "lib", "https://example.com/repository.git"}, []string{"add", "--path=lib", "https://example.com/repository.git"}},
+		{[]string{"add", "--", "-lib"}, []string{"add", "--", "-lib"}},
+		{[]string{"recover"}, []string{"recover"}},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestCommandArgs-%d", index+1), func(t *testing.T) {
+			var actual []string
+			run := func(cmd *cobra.Command, args []string) {
+				actual = cmdInternal.CommandArgs(cmd, args)
+			}
+
+			root := &cobra.Command{Use: "git-nest"}
+			root.PersistentFlags().Bool("non-interactive", false, "")
+			root.PersistentFlags().CountP("verbose", "v", "")
+
+			syncCmd := &cobra.Command{Use: "sync", Run: run}
+			syncCmd.Flags().Bool("from-config", false, "")
+
+			archiveCmd := &cobra.Command{Use: "archive", Run: run}
+			archiveCmd.Flags().StringSlice("group", nil, "")
+
+			addCmd := &cobra.Command{Use: "add", Run: run}
+			addCmd.Flags().StringP("path", "p", "", "")
+
+			root.AddCommand(syncCmd, archiveCmd, addCmd, &cobra.Command{Use: "recover", Run: run})
+			root.SetArgs(tc.args)
+
+			err := root.Execute()
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !slices.Equal(actual, tc.expected) {
+				t.Fatalf("unexpected args: %q, expected %q", actual, tc.expected)
+			}
+		})
+	}
+}
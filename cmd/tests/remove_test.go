@@ -0,0 +1,53 @@
+package tests
+
+import (
+	"fmt"
+	cmdInternal "github.com/jeftadlvw/git-nest/cmd/internal"
+	"github.com/jeftadlvw/git-nest/internal"
+	"github.com/jeftadlvw/git-nest/models"
+	"testing"
+)
+
+func TestRemoveKeep(t *testing.T) {
+	cases := []struct {
+		args     []string
+		expected int
+		exists   bool
+	}{
+		{[]string{"remove", "--keep", "module"}, cmdInternal.ExitCodeSuccess, true},
+		{[]string{"remove", "--keep", "--delete", "module"}, cmdInternal.ExitCodeError, true},
+		{[]string{"remove", "--delete", "--force", "module"}, cmdInternal.ExitCodeSuccess, false},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestRemoveKeep-%d", index+1), func(t *testing.T) {
+			root, submodule := createVerifyProject(t)
+			modulePath := root.SJoin("module")
+			writeVerifyConfig(t, root, models.Config{}, submodule)
+
+			context, err := internal.CreateContext(root)
+			if err != nil {
+				t.Fatalf("error creating context: %s", err)
+			}
+			_, err = internal.RecordState(context, "test")
+			if err != nil {
+				t.Fatalf("error recording state: %s", err)
+			}
+
+			exitCode := execute(t, root, tc.args...)
+			if exitCode != tc.expected {
+				t.Fatalf("unexpected exit code: %d, expected %d", exitCode, tc.expected)
+			}
+
+			// kept directories must survive pruning
+			exitCode = execute(t, root, "prune", "--yes")
+			if exitCode != cmdInternal.ExitCodeSuccess {
+				t.Fatalf("unexpected prune exit code: %d", exitCode)
+			}
+
+			if modulePath.IsDir() != tc.exists {
+				t.Fatalf("unexpected existence of module directory: %t, expected %t", modulePath.IsDir(), tc.exists)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/cmd"
+	cmdInternal "github.com/jeftadlvw/git-nest/cmd/internal"
+	"github.com/jeftadlvw/git-nest/internal"
+	"github.com/jeftadlvw/git-nest/internal/constants"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/models/urls"
+	"github.com/jeftadlvw/git-nest/test_env"
+	"github.com/jeftadlvw/git-nest/utils"
+	"os"
+	"os/exec"
+	"testing"
+)
+
+/*
+execute runs git-nest with the passed arguments within root and returns its exit code.
+*/
+func execute(t *testing.T, root models.Path, args ...string) int {
+	t.Helper()
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("could not get working directory: %s", err)
+	}
+	osArgs := os.Args
+
+	err = os.Chdir(root.String())
+	if err != nil {
+		t.Fatalf("could not change working directory: %s", err)
+	}
+	defer func() {
+		_ = os.Chdir(wd)
+		os.Args = osArgs
+		internal.Cleanup()
+	}()
+
+	os.Args = append([]string{constants.ApplicationName}, args...)
+	exitCode, err := cmd.Execute()
+	if err != nil {
+		t.Logf("git-nest %v: %s", args, err)
+	}
+
+	return exitCode
+}
+
+/*
+createVerifyProject creates a project with a single nested module and returns the project root and the module.
+*/
+func createVerifyProject(t *testing.T) (models.Path, models.Submodule) {
+	t.Helper()
+
+	testRepoUrl, err := urls.HttpUrlFromString(test_env.RepoUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root := models.Path(t.TempDir())
+	err = test_env.CreateLocalRepository(root, "project")
+	if err != nil {
+		t.Fatalf("error creating project repository: %s", err)
+	}
+
+	modulePath := root.SJoin("module")
+	err = test_env.CreateLocalRepository(modulePath, "first")
+	if err != nil {
+		t.Fatalf("error creating module repository: %s", err)
+	}
+
+	out, err := utils.RunCommandCombinedOutput(modulePath, "git", "remote", "add", "origin", testRepoUrl.String())
+	if err != nil {
+		t.Fatalf("error adding remote: %s; %s", err, out)
+	}
+
+	return root, models.Submodule{Path: "module", Url: &testRepoUrl, Ref: test_env.RepoBranchDefault}
+}
+
+/*
+writeVerifyConfig writes the configuration of a project with the passed nested module.
+*/
+func writeVerifyConfig(t *testing.T, root models.Path, config models.Config, submodule models.Submodule) {
+	t.Helper()
+
+	content := internal.ConfigToTomlConfig(config, "") + "\n" + internal.SubmoduleToTomlConfig(submodule, "")
+	configFile := root.SJoin(constants.ConfigFileName)
+	err := os.WriteFile(configFile.String(), []byte(content), 0o644)
+	if err != nil {
+		t.Fatalf("error writing configuration: %s", err)
+	}
+}
+
+func TestVerifyIntegrity(t *testing.T) {
+	cases := []struct {
+		record   bool
+		modify   bool
+		expected int
+	}{
+		{false, false, cmdInternal.ExitCodeSuccess},
+		{false, true, cmdInternal.ExitCodeSuccess},
+		{true, false, cmdInternal.ExitCodeSuccess},
+		{true, true, cmdInternal.ExitCodeError},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestVerifyIntegrity-%d", index+1), func(t *testing.T) {
+			root, submodule := createVerifyProject(t)
+			modulePath := root.SJoin("module")
+
+			if tc.record {
+				treeHash, worktreeHash, err := internal.SubmoduleIntegrityHashes(modulePath)
+				if err != nil {
+					t.Fatalf("error computing integrity hashes: %s", err)
+				}
+				submodule.TreeHash = treeHash
+				submodule.WorktreeHash = worktreeHash
+			}
+			writeVerifyConfig(t, root, models.Config{}, submodule)
+
+			if tc.modify {
+				modifiedFile := modulePath.SJoin("modified.txt")
+				err := os.WriteFile(modifiedFile.String(), []byte("modified"), 0o644)
+				if err != nil {
+					t.Fatalf("error modifying module: %s", err)
+				}
+			}
+
+			exitCode := execute(t, root, "verify", "--integrity")
+			if exitCode != tc.expected {
+				t.Fatalf("unexpected exit code: %d, expected %d", exitCode, tc.expected)
+			}
+		})
+	}
+}
+
+func TestVerifyPolicy(t *testing.T) {
+	cases := []struct {
+		policy   models.Policy
+		expected int
+	}{
+		{models.Policy{}, cmdInternal.ExitCodeSuccess},
+		{models.Policy{AllowedHosts: []string{"github.com"}}, cmdInternal.ExitCodeSuccess},
+		{models.Policy{AllowedHosts: []string{"example.com"}}, cmdInternal.ExitCodeError},
+		{models.Policy{ForbiddenPaths: []string{"module"}}, cmdInternal.ExitCodeError},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestVerifyPolicy-%d", index+1), func(t *testing.T) {
+			root, submodule := createVerifyProject(t)
+			writeVerifyConfig(t, root, models.Config{Policy: tc.policy}, submodule)
+
+			exitCode := execute(t, root, "verify")
+			if exitCode != tc.expected {
+				t.Fatalf("unexpected exit code: %d, expected %d", exitCode, tc.expected)
+			}
+		})
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen is not installed")
+	}
+
+	cases := []struct {
+		signed   bool
+		trusted  bool
+		expected int
+	}{
+		{true, true, cmdInternal.ExitCodeSuccess},
+		{true, false, cmdInternal.ExitCodeSignatureUnverified},
+		{false, true, cmdInternal.ExitCodeSignatureUnverified},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestVerifySignature-%d", index+1), func(t *testing.T) {
+			root, submodule := createVerifyProject(t)
+			modulePath := root.SJoin("module")
+
+			key, trustedSigners, err := test_env.CreateSshSigningKey(root, "trusted", "foo@email.com")
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, untrustedSigners, err := test_env.CreateSshSigningKey(root, "untrusted", "foo@email.com")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = test_env.ConfigureSshSigning(modulePath, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			commitArgs := []string{"commit", "--allow-empty", "-m", "commit"}
+			if tc.signed {
+				commitArgs = append(commitArgs, "-S")
+			}
+			out, err := utils.RunCommandCombinedOutput(modulePath, "git", commitArgs...)
+			if err != nil {
+				t.Fatalf("error committing: %s; %s", err, out)
+			}
+
+			allowedSigners := trustedSigners
+			if !tc.trusted {
+				allowedSigners = untrustedSigners
+			}
+			writeVerifyConfig(t, root, models.Config{VerifySignature: true, AllowedSigners: allowedSigners.Base()}, submodule)
+
+			exitCode := execute(t, root, "verify")
+			if exitCode != tc.expected {
+				t.Fatalf("unexpected exit code: %d, expected %d", exitCode, tc.expected)
+			}
+		})
+	}
+}
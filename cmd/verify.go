@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	cmdInternal "github.com/jeftadlvw/git-nest/cmd/internal"
 	"github.com/jeftadlvw/git-nest/internal"
@@ -13,21 +14,27 @@ func createVerifyCmd() *cobra.Command {
 		Aliases: []string{"v"},
 		Short:   "Verify configuration and nested modules",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return verifyConfigAndSubmodules()
+			integrity, _ := cmd.Flags().GetBool("integrity")
+			return verifyConfigAndSubmodules(integrity)
 		},
 	}
 
+	listCmd.Flags().Bool("integrity", false, "compare nested modules with their recorded tree and worktree hashes")
+
 	return listCmd
 }
 
-func verifyConfigAndSubmodules() error {
+func verifyConfigAndSubmodules(integrity bool) error {
 	// read context
 	context, err := cmdInternal.ErrorWrappedEvaluateContext()
 	if err != nil {
 		return err
 	}
 
-	submodulesExist := internal.SubmodulesExist(context.Config.Submodules, context.ProjectRoot)
+	// failed verifications are collected, so that all nested modules are verified
+	var errs []error
+
+	submodulesExist := internal.SubmodulesExist(context.Config.Submodules, context.ProjectRoot, context.Config.Config.SshHttpsEquivalent)
 
 	for index := range len(context.Config.Submodules) {
 		submoduleExists := submodulesExist[index]
@@ -38,8 +45,30 @@ func verifyConfigAndSubmodules() error {
 
 		if !internal.SubmoduleStatusValid(submoduleExists.Status) {
 			fmt.Printf("error for nested module at index %d: %s\n", index, existStr)
+			continue
+		}
+
+		submodule := context.Config.Submodules[index]
+		verification := context.Config.Config.SignatureVerification(submodule, context.ProjectRoot)
+		err = internal.VerifySubmoduleSignature(submodule, context.ProjectRoot.Join(submodule.Path), verification)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("nested module %s: %w", submodule.Path, err))
 		}
+
+		if integrity {
+			report := internal.VerifySubmoduleIntegrity(submodule, context.ProjectRoot)
+			if report.Modified() || report.Error != nil {
+				errs = append(errs, fmt.Errorf("integrity of nested module %s: %s", submodule.Path, report))
+			} else if !report.Recorded {
+				fmt.Printf("integrity of nested module %s: %s\n", submodule.Path, report)
+			}
+		}
+	}
+
+	err = internal.PolicyError(internal.PolicyViolations(context.Policy, context.Config.Submodules, context.ProjectRoot))
+	if err != nil {
+		errs = append(errs, err)
 	}
 
-	return nil
+	return errors.Join(errs...)
 }
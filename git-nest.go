@@ -1,21 +1,33 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"github.com/jeftadlvw/git-nest/cmd"
 	"github.com/jeftadlvw/git-nest/internal"
+	"github.com/jeftadlvw/git-nest/utils"
 	"os"
 	"os/signal"
 	"sync"
+	"sync/atomic"
+	"syscall"
 )
 
 func main() {
-	c := make(chan os.Signal)
-	signal.Notify(c, os.Interrupt, os.Kill)
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+
+	// cancelling the context terminates all running git commands
+	ctx, cancel := context.WithCancel(context.Background())
+	utils.SetCommandContext(ctx)
 
 	var execOnce sync.Once
+	var received atomic.Value
 
-	go internal.HandleOsTerminationSignals(c, func() {
+	go internal.HandleOsTerminationSignals(c, func(sig os.Signal) {
+		received.Store(sig)
+		cancel()
+	}, func() {
 		execOnce.Do(internal.Cleanup)
 	})
 
@@ -24,6 +36,10 @@ func main() {
 		fmt.Printf("error: %s\n", err)
 	}
 
+	if sig, ok := received.Load().(os.Signal); ok {
+		exitCode = internal.SignalExitCode(sig)
+	}
+
 	execOnce.Do(internal.Cleanup)
 	os.Exit(exitCode)
 }
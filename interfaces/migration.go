@@ -1,5 +1,7 @@
 package interfaces
 
+import "context"
+
 /*
 Migration defines an interface for performing migration on
 nested modules.
@@ -11,3 +13,26 @@ type Migration interface {
 	*/
 	Migrate() error
 }
+
+/*
+ContextMigration is implemented by migrations that run long-running commands, which are aborted once a context is done.
+*/
+type ContextMigration interface {
+
+	/*
+		MigrateContext migrates the module, aborting if ctx is done.
+	*/
+	MigrateContext(ctx context.Context) error
+}
+
+/*
+PathCreator is implemented by migrations that create files or directories.
+*/
+type PathCreator interface {
+
+	/*
+		CreatedPaths returns the paths the migration creates. Paths that did not exist before the migration
+		started are deleted if an interrupted run is rolled back.
+	*/
+	CreatedPaths() []string
+}
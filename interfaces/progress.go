@@ -0,0 +1,36 @@
+package interfaces
+
+import "github.com/jeftadlvw/git-nest/models"
+
+/*
+ProgressReporter reports the progress of long-running operations, e.g. clones and pulls. Implementations must be
+safe for concurrent use, so that operations running in parallel can report their progress at the same time.
+*/
+type ProgressReporter interface {
+
+	/*
+		Start starts reporting the progress of an operation, e.g. for a single nested module.
+	*/
+	Start(name string) ProgressTask
+
+	/*
+		Message prints a message without disturbing the progress of running operations.
+	*/
+	Message(message string)
+}
+
+/*
+ProgressTask reports the progress of a single operation that was started by a ProgressReporter.
+*/
+type ProgressTask interface {
+
+	/*
+		Update reports the current progress of the operation.
+	*/
+	Update(progress models.Progress)
+
+	/*
+		Done finishes the operation. A non-nil error marks it as failed.
+	*/
+	Done(err error)
+}
@@ -0,0 +1,430 @@
+package internal
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jeftadlvw/git-nest/internal/constants"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/utils"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	ArchiveFormatTarGz     = "tar.gz"
+	ArchiveFormatTar       = "tar"
+	ArchiveFormatZip       = "zip"
+	ArchiveFormatDirectory = "dir"
+)
+
+/*
+ArchiveSource is a revision of a repository whose files are added to an archive below a path prefix.
+*/
+type ArchiveSource struct {
+	/*
+		Repository contains the Path to the repository or to a subdirectory of it.
+	*/
+	Repository models.Path
+
+	/*
+		Revision contains the revision that is archived.
+	*/
+	Revision string
+
+	/*
+		Prefix contains the directory within the archive in unix format. It is empty for the archive's root.
+	*/
+	Prefix string
+
+	/*
+		Paths contains the paths within the repository that are archived. Everything is archived if empty.
+	*/
+	Paths []string
+}
+
+/*
+ArchiveManifest describes the project and the nested modules that were included in an archive.
+*/
+type ArchiveManifest struct {
+	/*
+		GeneratedBy contains the application and version that created the archive.
+	*/
+	GeneratedBy string `json:"generated_by"`
+
+	/*
+		Commit contains the archived commit of the project.
+	*/
+	Commit string `json:"commit"`
+
+	/*
+		Groups contains the groups nested modules were selected by. All nested modules were included if empty.
+	*/
+	Groups []string `json:"groups,omitempty"`
+
+	/*
+		Modules contains the included nested modules.
+	*/
+	Modules []ArchiveManifestModule `json:"modules"`
+}
+
+/*
+ArchiveManifestModule describes a nested module that was included in an archive.
+*/
+type ArchiveManifestModule struct {
+	Path        string   `json:"path"`
+	Url         string   `json:"url"`
+	Ref         string   `json:"ref,omitempty"`
+	RefKind     string   `json:"ref_kind,omitempty"`
+	Commit      string   `json:"commit"`
+	TreeHash    string   `json:"tree_hash"`
+	SparsePaths []string `json:"sparse_paths,omitempty"`
+}
+
+/*
+ArchiveFormatFromPath infers the archive format from an output path's extension.
+Paths without a known archive extension are treated as directories.
+*/
+func ArchiveFormatFromPath(p string) string {
+	lower := strings.ToLower(p)
+	switch {
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return ArchiveFormatTarGz
+	case strings.HasSuffix(lower, ".tar"):
+		return ArchiveFormatTar
+	case strings.HasSuffix(lower, ".zip"):
+		return ArchiveFormatZip
+	default:
+		return ArchiveFormatDirectory
+	}
+}
+
+/*
+ValidateArchiveFormat returns an error if the passed format is not supported.
+*/
+func ValidateArchiveFormat(format string) error {
+	switch format {
+	case ArchiveFormatTarGz, ArchiveFormatTar, ArchiveFormatZip, ArchiveFormatDirectory:
+		return nil
+	default:
+		return fmt.Errorf("unsupported archive format '%s', expected one of %s, %s, %s, %s", format, ArchiveFormatTarGz, ArchiveFormatTar, ArchiveFormatZip, ArchiveFormatDirectory)
+	}
+}
+
+/*
+SubmoduleArchiveCommit resolves the commit a nested module is archived at: the commit of its pinned ref or,
+if no ref is pinned, its HEAD. Branches that only exist as remote-tracking branches are resolved as well.
+*/
+func SubmoduleArchiveCommit(s models.Submodule, repository models.Path) (string, error) {
+	if s.Ref == "" {
+		return utils.GetGitRefCommit(repository, "HEAD")
+	}
+
+	switch SubmoduleRefKind(s, repository) {
+	case models.RefKindTag:
+		return utils.GetGitRefCommit(repository, "refs/tags/"+s.Ref)
+	case models.RefKindBranch:
+		if commit, err := utils.GetGitRefCommit(repository, "refs/heads/"+s.Ref); err == nil {
+			return commit, nil
+		}
+		return utils.GetGitRefCommit(repository, "refs/remotes/"+s.RemoteName()+"/"+s.Ref)
+	default:
+		return utils.GetGitRefCommit(repository, s.Ref)
+	}
+}
+
+/*
+WriteArchive writes the files of all sources and the manifest into an archive of the passed format.
+Directory outputs may not exist or must be empty. A partially written archive file is removed on errors.
+*/
+func WriteArchive(output models.Path, format string, sources []ArchiveSource, manifest ArchiveManifest) error {
+	err := ValidateArchiveFormat(format)
+	if err != nil {
+		return err
+	}
+
+	writer, err := newArchiveWriter(output, format)
+	if err != nil {
+		return err
+	}
+
+	err = writeArchiveEntries(writer, sources, manifest)
+	closeErr := writer.Close()
+	if err == nil {
+		err = closeErr
+	}
+
+	if err != nil && format != ArchiveFormatDirectory {
+		_ = os.Remove(output.String())
+	}
+
+	return err
+}
+
+/*
+writeArchiveEntries streams the entries of all sources into an archiveWriter and adds the manifest.
+*/
+func writeArchiveEntries(writer archiveWriter, sources []ArchiveSource, manifest ArchiveManifest) error {
+	// the manifest uses the newest modification time of all entries, so that archives are reproducible
+	var latest time.Time
+
+	for _, source := range sources {
+		err := utils.GitArchive(source.Repository, source.Revision, func(r io.Reader) error {
+			tr := tar.NewReader(r)
+			for {
+				header, err := tr.Next()
+				if errors.Is(err, io.EOF) {
+					return nil
+				}
+				if err != nil {
+					return fmt.Errorf("could not read archive of %s: %w", source.Repository, err)
+				}
+
+				// git stores the commit id in a global pax header
+				if header.Typeflag == tar.TypeXGlobalHeader {
+					continue
+				}
+
+				name, err := archiveEntryName(source.Prefix, header.Name, header.Typeflag == tar.TypeDir)
+				if err != nil {
+					return err
+				}
+				header.Name = name
+
+				if header.ModTime.After(latest) {
+					latest = header.ModTime
+				}
+
+				err = writer.WriteEntry(header, tr)
+				if err != nil {
+					return fmt.Errorf("could not write %s: %w", name, err)
+				}
+			}
+		}, source.Paths...)
+		if err != nil {
+			return err
+		}
+	}
+
+	manifestBytes, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not create manifest: %w", err)
+	}
+	manifestBytes = append(manifestBytes, '\n')
+
+	return writer.WriteEntry(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     constants.ArchiveManifestFileName,
+		Mode:     0644,
+		Size:     int64(len(manifestBytes)),
+		ModTime:  latest,
+	}, bytes.NewReader(manifestBytes))
+}
+
+/*
+archiveEntryName joins a prefix and an entry name and rejects names that would escape the archive's root.
+*/
+func archiveEntryName(prefix string, name string, dir bool) (string, error) {
+	joined := path.Clean(path.Join(prefix, name))
+	if path.IsAbs(joined) || joined == ".." || strings.HasPrefix(joined, "../") {
+		return "", fmt.Errorf("archive entry %s escapes the archive root", name)
+	}
+
+	if dir {
+		joined += "/"
+	}
+
+	return joined, nil
+}
+
+/*
+archiveWriter writes tar entries into an archive format.
+*/
+type archiveWriter interface {
+	WriteEntry(header *tar.Header, r io.Reader) error
+	Close() error
+}
+
+/*
+newArchiveWriter creates an archiveWriter for a format that writes to output.
+*/
+func newArchiveWriter(output models.Path, format string) (archiveWriter, error) {
+	if format == ArchiveFormatDirectory {
+		if output.Exists() {
+			entries, err := os.ReadDir(output.String())
+			if err != nil || len(entries) != 0 {
+				return nil, fmt.Errorf("%s already exists and is not an empty directory", output)
+			}
+		}
+
+		err := os.MkdirAll(output.String(), 0755)
+		if err != nil {
+			return nil, fmt.Errorf("could not create directory %s: %w", output, err)
+		}
+
+		return &directoryArchiveWriter{root: output}, nil
+	}
+
+	if output.Exists() {
+		return nil, fmt.Errorf("%s already exists", output)
+	}
+
+	f, err := os.OpenFile(output.String(), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("could not create %s: %w", output, err)
+	}
+
+	switch format {
+	case ArchiveFormatZip:
+		return &zipArchiveWriter{file: f, writer: zip.NewWriter(f)}, nil
+	case ArchiveFormatTarGz:
+		gz := gzip.NewWriter(f)
+		return &tarArchiveWriter{file: f, gzip: gz, writer: tar.NewWriter(gz)}, nil
+	default:
+		return &tarArchiveWriter{file: f, writer: tar.NewWriter(f)}, nil
+	}
+}
+
+/*
+tarArchiveWriter writes entries into an optionally gzip-compressed tar archive.
+*/
+type tarArchiveWriter struct {
+	file   *os.File
+	gzip   *gzip.Writer
+	writer *tar.Writer
+}
+
+func (w *tarArchiveWriter) WriteEntry(header *tar.Header, r io.Reader) error {
+	err := w.writer.WriteHeader(header)
+	if err != nil {
+		return err
+	}
+
+	if header.Typeflag == tar.TypeReg {
+		_, err = io.Copy(w.writer, r)
+	}
+
+	return err
+}
+
+func (w *tarArchiveWriter) Close() error {
+	err := w.writer.Close()
+	if w.gzip != nil {
+		err = errors.Join(err, w.gzip.Close())
+	}
+
+	return errors.Join(err, w.file.Close())
+}
+
+/*
+zipArchiveWriter writes entries into a zip archive.
+*/
+type zipArchiveWriter struct {
+	file   *os.File
+	writer *zip.Writer
+}
+
+func (w *zipArchiveWriter) WriteEntry(header *tar.Header, r io.Reader) error {
+	fileHeader, err := zip.FileInfoHeader(header.FileInfo())
+	if err != nil {
+		return err
+	}
+	fileHeader.Name = header.Name
+	fileHeader.Modified = header.ModTime
+	if header.Typeflag == tar.TypeReg {
+		fileHeader.Method = zip.Deflate
+	}
+
+	entryWriter, err := w.writer.CreateHeader(fileHeader)
+	if err != nil {
+		return err
+	}
+
+	switch header.Typeflag {
+	case tar.TypeReg:
+		_, err = io.Copy(entryWriter, r)
+	case tar.TypeSymlink:
+		_, err = entryWriter.Write([]byte(header.Linkname))
+	}
+
+	return err
+}
+
+func (w *zipArchiveWriter) Close() error {
+	return errors.Join(w.writer.Close(), w.file.Close())
+}
+
+/*
+directoryArchiveWriter writes entries as plain files into a directory.
+*/
+type directoryArchiveWriter struct {
+	root models.Path
+}
+
+func (w *directoryArchiveWriter) WriteEntry(header *tar.Header, r io.Reader) error {
+	target := filepath.Join(w.root.String(), filepath.FromSlash(strings.TrimSuffix(header.Name, "/")))
+
+	if header.Typeflag == tar.TypeDir {
+		return os.MkdirAll(target, 0755)
+	}
+
+	err := os.MkdirAll(filepath.Dir(target), 0755)
+	if err != nil {
+		return err
+	}
+
+	// symbolic links of previous entries may not redirect files outside the output directory
+	err = w.ensureWithinRoot(filepath.Dir(target))
+	if err != nil {
+		return err
+	}
+
+	switch header.Typeflag {
+	case tar.TypeSymlink:
+		return os.Symlink(header.Linkname, target)
+	case tar.TypeReg:
+		f, err := os.OpenFile(target, os.O_CREATE|os.O_EXCL|os.O_WRONLY, header.FileInfo().Mode().Perm())
+		if err != nil {
+			return err
+		}
+
+		_, err = io.Copy(f, r)
+		return errors.Join(err, f.Close())
+	default:
+		return nil
+	}
+}
+
+/*
+ensureWithinRoot returns an error if a directory resolves to a location outside the output directory.
+*/
+func (w *directoryArchiveWriter) ensureWithinRoot(dir string) error {
+	resolvedRoot, err := filepath.EvalSymlinks(w.root.String())
+	if err != nil {
+		return err
+	}
+
+	resolvedDir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return err
+	}
+
+	relative, err := filepath.Rel(resolvedRoot, resolvedDir)
+	if err != nil || relative == ".." || strings.HasPrefix(relative, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("%s escapes the output directory", dir)
+	}
+
+	return nil
+}
+
+func (w *directoryArchiveWriter) Close() error {
+	return nil
+}
@@ -0,0 +1,250 @@
+package internal
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jeftadlvw/git-nest/internal/constants"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/utils"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+/*
+BundleManifest describes the nested modules contained in an offline bundle.
+*/
+type BundleManifest struct {
+	/*
+		GeneratedBy contains the application and version that created the bundle.
+	*/
+	GeneratedBy string `json:"generated_by"`
+
+	/*
+		Modules contains the bundled nested modules.
+	*/
+	Modules []BundleModule `json:"modules"`
+}
+
+/*
+BundleModule describes a nested module contained in an offline bundle.
+*/
+type BundleModule struct {
+	Path    string `json:"path"`
+	Url     string `json:"url"`
+	Remote  string `json:"remote"`
+	Ref     string `json:"ref,omitempty"`
+	RefKind string `json:"ref_kind,omitempty"`
+	Commit  string `json:"commit"`
+
+	/*
+		Bundle contains the path of the module's git bundle within the offline bundle in unix format.
+	*/
+	Bundle string `json:"bundle"`
+}
+
+/*
+Module returns the bundled nested module at the passed path.
+*/
+func (m BundleManifest) Module(p models.Path) (BundleModule, bool) {
+	unixPath := p.UnixString()
+	for _, module := range m.Modules {
+		if module.Path == unixPath {
+			return module, true
+		}
+	}
+
+	return BundleModule{}, false
+}
+
+/*
+BundleFileName returns the path of the git bundle of the nested module at the passed index within an offline bundle.
+*/
+func BundleFileName(index int) string {
+	return fmt.Sprintf("%s/%03d.bundle", constants.BundleDirName, index+1)
+}
+
+/*
+WriteBundle writes an offline bundle to output: a gzip-compressed tar archive that contains the manifest,
+the configuration file and a git bundle of every nested module in the manifest. Nested modules are located relative
+to root. A partially written bundle is removed on errors.
+*/
+func WriteBundle(output models.Path, root models.Path, configFile models.Path, manifest BundleManifest) error {
+	if output.Exists() {
+		return fmt.Errorf("%s already exists", output)
+	}
+
+	tempDir, err := os.MkdirTemp("", constants.ApplicationName+"-bundle-")
+	if err != nil {
+		return fmt.Errorf("could not create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	for _, module := range manifest.Modules {
+		bundle := models.Path(filepath.Join(tempDir, filepath.FromSlash(module.Bundle)))
+		err = os.MkdirAll(filepath.Dir(bundle.String()), 0755)
+		if err != nil {
+			return fmt.Errorf("could not create temporary directory: %w", err)
+		}
+
+		err = utils.GitBundleCreate(root.Join(models.Path(module.Path)), bundle)
+		if err != nil {
+			return fmt.Errorf("could not bundle nested module %s: %w", module.Path, err)
+		}
+	}
+
+	writer, err := newArchiveWriter(output, ArchiveFormatTarGz)
+	if err != nil {
+		return err
+	}
+
+	err = writeBundleEntries(writer, tempDir, configFile, manifest)
+	closeErr := writer.Close()
+	if err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		_ = os.Remove(output.String())
+	}
+
+	return err
+}
+
+/*
+writeBundleEntries adds the manifest, the configuration file and all git bundles within tempDir to an archiveWriter.
+*/
+func writeBundleEntries(writer archiveWriter, tempDir string, configFile models.Path, manifest BundleManifest) error {
+	manifestBytes, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not create manifest: %w", err)
+	}
+	manifestBytes = append(manifestBytes, '\n')
+
+	err = writer.WriteEntry(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     constants.BundleManifestFileName,
+		Mode:     0644,
+		Size:     int64(len(manifestBytes)),
+	}, bytes.NewReader(manifestBytes))
+	if err != nil {
+		return fmt.Errorf("could not write manifest: %w", err)
+	}
+
+	err = writeArchiveFile(writer, configFile.String(), constants.ConfigFileName)
+	if err != nil {
+		return err
+	}
+
+	for _, module := range manifest.Modules {
+		err = writeArchiveFile(writer, filepath.Join(tempDir, filepath.FromSlash(module.Bundle)), module.Bundle)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+/*
+writeArchiveFile adds a regular file to an archiveWriter under the passed name.
+*/
+func writeArchiveFile(writer archiveWriter, source string, name string) error {
+	f, err := os.Open(source)
+	if err != nil {
+		return fmt.Errorf("could not open %s: %w", source, err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("could not stat %s: %w", source, err)
+	}
+
+	err = writer.WriteEntry(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     0644,
+		Size:     info.Size(),
+		ModTime:  info.ModTime(),
+	}, f)
+	if err != nil {
+		return fmt.Errorf("could not write %s: %w", name, err)
+	}
+
+	return nil
+}
+
+/*
+ExtractBundle extracts an offline bundle into an empty or non-existing directory and returns its manifest.
+Returns an error if a nested module's git bundle is missing.
+*/
+func ExtractBundle(bundle models.Path, dir models.Path) (BundleManifest, error) {
+	f, err := os.Open(bundle.String())
+	if err != nil {
+		return BundleManifest{}, fmt.Errorf("could not open bundle: %w", err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return BundleManifest{}, fmt.Errorf("%s is not a %s bundle: %w", bundle, constants.ApplicationName, err)
+	}
+
+	writer, err := newArchiveWriter(dir, ArchiveFormatDirectory)
+	if err != nil {
+		return BundleManifest{}, err
+	}
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return BundleManifest{}, fmt.Errorf("could not read bundle: %w", err)
+		}
+
+		// bundles only contain regular files
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		header.Name, err = archiveEntryName("", header.Name, false)
+		if err != nil {
+			return BundleManifest{}, err
+		}
+
+		err = writer.WriteEntry(header, tr)
+		if err != nil {
+			return BundleManifest{}, fmt.Errorf("could not extract %s: %w", header.Name, err)
+		}
+	}
+
+	manifestStr, err := utils.ReadFileToStr(dir.SJoin(constants.BundleManifestFileName))
+	if err != nil {
+		return BundleManifest{}, fmt.Errorf("%s is not a %s bundle: missing manifest", bundle, constants.ApplicationName)
+	}
+
+	manifest := BundleManifest{}
+	err = json.Unmarshal([]byte(manifestStr), &manifest)
+	if err != nil {
+		return BundleManifest{}, fmt.Errorf("could not parse bundle manifest: %w", err)
+	}
+
+	for _, module := range manifest.Modules {
+		name, err := archiveEntryName("", module.Bundle, false)
+		bundleFile := dir.SJoin(filepath.FromSlash(name))
+		if err != nil || path.Dir(name) != constants.BundleDirName || !bundleFile.IsFile() {
+			return BundleManifest{}, fmt.Errorf("bundle of nested module %s is missing", module.Path)
+		}
+	}
+
+	return manifest, nil
+}
@@ -0,0 +1,173 @@
+package internal
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/utils"
+	"path"
+	"slices"
+	"strings"
+)
+
+/*
+StagedNestedPath describes a staged path that lies within a nested module.
+*/
+type StagedNestedPath struct {
+	/*
+		Path contains the staged path relative to the git repository root.
+	*/
+	Path string
+
+	/*
+		Submodule contains the path of the nested module the staged path lies in, relative to the project root.
+	*/
+	Submodule models.Path
+}
+
+/*
+FindStagedNestedPaths returns all staged paths of the parent repository that lie within a nested module.
+indexFile is passed on to utils.GetGitStagedPaths.
+*/
+func FindStagedNestedPaths(context models.NestContext, indexFile models.Path) ([]StagedNestedPath, error) {
+	if !context.IsGitInstalled || context.GitRepositoryRoot.Empty() {
+		return nil, fmt.Errorf("project is not located within a git repository")
+	}
+
+	stagedPaths, err := utils.GetGitStagedPaths(context.GitRepositoryRoot, indexFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not read staged paths: %w", err)
+	}
+
+	var nestedPaths []StagedNestedPath
+	for _, stagedPath := range stagedPaths {
+		// staged paths are relative to the repository root, submodule paths to the project root
+		absoluteStagedPath := context.GitRepositoryRoot.SJoin(stagedPath)
+		relativeToProject, err := context.ProjectRoot.Relative(absoluteStagedPath)
+		if err != nil || PathContainsUp(relativeToProject) {
+			continue
+		}
+
+		for _, submodule := range context.Config.Submodules {
+			if !PathOutsideRoot(submodule.Path, relativeToProject) {
+				nestedPaths = append(nestedPaths, StagedNestedPath{Path: stagedPath, Submodule: submodule.Path})
+				break
+			}
+		}
+	}
+
+	return nestedPaths, nil
+}
+
+/*
+ReadSubmoduleIgnoreConfig reads the entries of the git-nest block within a git exclude file.
+The returned boolean defines whether such a block exists.
+*/
+func ReadSubmoduleIgnoreConfig(p models.Path) ([]string, bool, error) {
+	if !p.IsFile() {
+		return nil, false, nil
+	}
+
+	content, err := utils.ReadFileToStr(p)
+	if err != nil {
+		return nil, false, err
+	}
+
+	_, afterPrefix, found := strings.Cut(content, gitExcludePrefix)
+	if !found {
+		return nil, false, nil
+	}
+
+	block, _, found := strings.Cut(afterPrefix, gitExcludeSuffix)
+	if !found {
+		return nil, false, nil
+	}
+
+	var entries []string
+	for _, line := range strings.Split(block, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		entries = append(entries, line)
+	}
+
+	return entries, true, nil
+}
+
+/*
+SubmoduleIgnoreConfigDiff compares the configured nested modules with the git-nest blocks in the ignore files the
+configured ignore mode uses. It returns the module paths that are missing in and the paths that are superfluous in
+these files. The git exclude file is only maintained if the project root is also the git repository root.
+*/
+func SubmoduleIgnoreConfigDiff(context models.NestContext) ([]string, []string, error) {
+	var configEntries []string
+	for _, submodule := range context.Config.Submodules {
+		configEntries = append(configEntries, submodule.Path.UnixString())
+	}
+
+	var missing, superfluous []string
+	config := context.Config.Config
+
+	if config.UsesGitExclude() && context.IsGitInstalled && context.IsGitRepository {
+		gitDir := GitCommonDirFromContext(context)
+		excludeEntries, _, err := ReadSubmoduleIgnoreConfig(gitDir.SJoin(gitExcludeFile))
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not read git exclude file: %w", err)
+		}
+
+		localMissing, localSuperfluous := diffIgnoreEntries(configEntries, excludeEntries)
+		missing = append(missing, localMissing...)
+		superfluous = append(superfluous, localSuperfluous...)
+	}
+
+	if config.UsesGitignore() {
+		gitignoreFiles, err := FindManagedGitignoreFiles(context.ProjectRoot)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not find .gitignore files: %w", err)
+		}
+
+		// gitignore entries are anchored relative to their file
+		var gitignoreEntries []string
+		for _, gitignoreFile := range gitignoreFiles {
+			entries, _, err := ReadSubmoduleIgnoreConfig(context.ProjectRoot.SJoin(gitignoreFile))
+			if err != nil {
+				return nil, nil, fmt.Errorf("could not read %s: %w", gitignoreFile, err)
+			}
+
+			for _, entry := range entries {
+				gitignoreEntries = append(gitignoreEntries, path.Join(path.Dir(gitignoreFile), strings.TrimPrefix(entry, "/")))
+			}
+		}
+
+		// modules must be ignored in the configured location
+		expectedEntries := FmtSubmodulesGitignoreFiles(context.Config.Submodules, config.GitignorePerDirectory())
+		var expectedGitignoreEntries []string
+		for gitignoreFile, entries := range expectedEntries {
+			for _, entry := range strings.Split(entries, "\n") {
+				expectedGitignoreEntries = append(expectedGitignoreEntries, path.Join(path.Dir(gitignoreFile), strings.TrimPrefix(entry, "/")))
+			}
+		}
+
+		localMissing, localSuperfluous := diffIgnoreEntries(expectedGitignoreEntries, gitignoreEntries)
+		missing = append(missing, localMissing...)
+		superfluous = append(superfluous, localSuperfluous...)
+	}
+
+	return missing, superfluous, nil
+}
+
+func diffIgnoreEntries(expected []string, actual []string) ([]string, []string) {
+	var missing, superfluous []string
+	for _, entry := range expected {
+		if !slices.Contains(actual, entry) {
+			missing = append(missing, entry)
+		}
+	}
+	for _, entry := range actual {
+		if !slices.Contains(expected, entry) {
+			superfluous = append(superfluous, entry)
+		}
+	}
+
+	return missing, superfluous
+}
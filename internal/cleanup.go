@@ -11,7 +11,7 @@ var cleanupStack []func() error
 
 /*
 Cleanup is responsible for cleaning up any left-over files and things.
-Gets called by the main function on exiting.
+Gets called by the main function on exiting. Every function on the cleanup stack is only called once.
 */
 func Cleanup() {
 	cleanupMutex.Lock()
@@ -23,6 +23,7 @@ func Cleanup() {
 			_, _ = fmt.Fprintf(os.Stderr, "error while cleaning up: %s", err)
 		}
 	}
+	cleanupStack = nil
 
 	cleanupMutex.Unlock()
 }
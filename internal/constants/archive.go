@@ -0,0 +1,16 @@
+package constants
+
+/*
+ArchiveManifestFileName contains the name of the manifest at the root of an archive that describes the included nested modules.
+*/
+const ArchiveManifestFileName = "git-nest-manifest.json"
+
+/*
+BundleManifestFileName contains the name of the manifest at the root of an offline bundle that describes the bundled nested modules.
+*/
+const BundleManifestFileName = "git-nest-bundle.json"
+
+/*
+BundleDirName contains the name of the directory within an offline bundle that contains the git bundles of all nested modules.
+*/
+const BundleDirName = "bundles"
@@ -9,3 +9,42 @@ const ConfigFileName = "nestmodules.toml"
 ConfigSubDirFileName contains the path string to the project-local git-nest configuration file in a .config subdirectory.
 */
 const ConfigSubDirFileName = ".config/nestmodules.toml"
+
+/*
+LocalConfigFileName contains the name of the clone-local git-nest configuration file within the repository's common git directory.
+It is never tracked and overrides settings of the project configuration for the current clone only.
+*/
+const LocalConfigFileName = "git-nest.toml"
+
+/*
+UrlRewriteEnvVariable contains the name of the environment variable that defines additional url rewrites,
+formatted as 'prefix=replacement' and separated by ';'.
+*/
+const UrlRewriteEnvVariable = "GIT_NEST_URL_REWRITE"
+
+/*
+StateDirName contains the name of the directory within the repository's common git directory that contains git-nest's state.
+*/
+const StateDirName = "git-nest"
+
+/*
+StateFileName contains the name of the file within StateDirName that records what git-nest last applied.
+*/
+const StateFileName = "state.json"
+
+/*
+StateHistoryLimit contains the maximum number of history entries kept in the state file.
+*/
+const StateHistoryLimit = 200
+
+/*
+JournalFileName contains the name of the file within StateDirName that records the progress of a running command.
+It only remains if a command has been interrupted.
+*/
+const JournalFileName = "journal.json"
+
+/*
+JournalBackupDirName contains the name of the directory within StateDirName that contains the configuration files
+as they were before a journaled command started.
+*/
+const JournalBackupDirName = "journal-backup"
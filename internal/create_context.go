@@ -21,6 +21,9 @@ func CreateContext(p models.Path) (models.NestContext, error) {
 		configFilePath   models.Path
 		nestConfig       models.NestConfig
 		gitRoot          models.Path
+		gitCommonDir     models.Path
+		localUrlRewrites []models.UrlRewrite
+		envUrlRewrites   []models.UrlRewrite
 		IsGitInstalled   bool
 		isGitProject     bool
 		err              error
@@ -64,7 +67,7 @@ func CreateContext(p models.Path) (models.NestContext, error) {
 	}
 
 	// check if project root is also a git repository
-	gitRootStr, err := utils.GetGitRootDirectory(projectRoot)
+	gitRoot, gitCommonDir, err = utils.GetGitRepositoryDirectories(projectRoot)
 	IsGitInstalled = false
 	isGitProject = false
 	if err != nil {
@@ -72,7 +75,6 @@ func CreateContext(p models.Path) (models.NestContext, error) {
 	} else {
 		IsGitInstalled = true
 
-		gitRoot = models.Path(gitRootStr)
 		if gitRoot.Equals(projectRoot) {
 			isGitProject = true
 		} else if !nestConfig.Config.AllowUnequalRoots {
@@ -80,6 +82,19 @@ func CreateContext(p models.Path) (models.NestContext, error) {
 		}
 	}
 
+	// read url rewrites from the clone-local configuration file and the environment
+	if !gitCommonDir.Empty() {
+		localUrlRewrites, err = ReadLocalUrlRewrites(gitCommonDir.SJoin(constants.LocalConfigFileName))
+		if err != nil {
+			return nestContext, err
+		}
+	}
+
+	envUrlRewrites, err = ParseUrlRewrites(os.Getenv(constants.UrlRewriteEnvVariable))
+	if err != nil {
+		return nestContext, fmt.Errorf("invalid %s: %w", constants.UrlRewriteEnvVariable, err)
+	}
+
 	// calculate checksum of configuration file content
 	configFileChecksum := utils.CalculateChecksumS(configStr)
 
@@ -88,9 +103,12 @@ func CreateContext(p models.Path) (models.NestContext, error) {
 	nestContext.ConfigFileExists = configFileExists
 	nestContext.ConfigFile = configFilePath
 	nestContext.Config = nestConfig
+	nestContext.UrlRewrites = MergeUrlRewrites(envUrlRewrites, localUrlRewrites, nestConfig.Config.UrlRewrites)
+	nestContext.Policy = nestConfig.Config.Policy
 	nestContext.IsGitInstalled = IsGitInstalled
 	nestContext.IsGitRepository = isGitProject
 	nestContext.GitRepositoryRoot = gitRoot
+	nestContext.GitCommonDir = gitCommonDir
 	nestContext.Checksums.ConfigurationFile = configFileChecksum
 
 	return nestContext, nil
@@ -0,0 +1,58 @@
+package internal
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/models"
+	"strings"
+)
+
+/*
+DefaultTokenUsername is the username that is sent alongside an access token if no username is configured.
+Most git hosting providers accept any non-empty username for token-based authentication.
+*/
+const DefaultTokenUsername = "git"
+
+/*
+CredentialGitConfigParameters returns git configuration parameters in 'key=value' format that configure
+credential helpers for the passed credentials. Previously configured helpers for the same host are reset,
+so that git-nest's configuration takes precedence.
+*/
+func CredentialGitConfigParameters(credentials []models.Credential) []string {
+	var parameters []string
+
+	for _, credential := range credentials {
+		key := fmt.Sprintf("credential.%s.helper", credential.CredentialUrl())
+		parameters = append(parameters, key+"=", key+"="+CredentialHelper(credential))
+	}
+
+	return parameters
+}
+
+/*
+CredentialHelper returns the git credential helper for a models.Credential. Tokens are read from the configured
+environment variable by the helper itself, so that they never appear in command arguments or output.
+*/
+func CredentialHelper(c models.Credential) string {
+	if helper := strings.TrimSpace(c.Helper); helper != "" {
+		return helper
+	}
+
+	username := c.Username
+	if username == "" {
+		username = DefaultTokenUsername
+	}
+
+	return fmt.Sprintf(`!f() { test "$1" = get || return 0; test -n "${%[1]s}" || return 0; echo username=%[2]s; echo "password=${%[1]s}"; }; f`, strings.TrimSpace(c.TokenEnv), shellQuote(username))
+}
+
+/*
+CredentialDescription returns a human-readable description of how a models.Credential obtains credentials
+that does not contain any secrets.
+*/
+func CredentialDescription(c models.Credential) string {
+	if helper := strings.TrimSpace(c.Helper); helper != "" {
+		return fmt.Sprintf("helper %s", helper)
+	}
+
+	return fmt.Sprintf("token from $%s", strings.TrimSpace(c.TokenEnv))
+}
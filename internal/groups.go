@@ -0,0 +1,31 @@
+package internal
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/models"
+	"slices"
+	"strings"
+)
+
+/*
+SubmodulesInGroups returns the submodules that belong to at least one of the passed groups, see
+models.Submodule.InGroups. All submodules are returned if no groups are passed. Groups that no submodule
+belongs to are rejected, so that typos do not silently select nothing.
+*/
+func SubmodulesInGroups(submodules []models.Submodule, groups []string) ([]models.Submodule, error) {
+	for _, group := range groups {
+		group = strings.TrimSpace(group)
+		if !slices.ContainsFunc(submodules, func(s models.Submodule) bool { return slices.Contains(s.Groups, group) }) {
+			return nil, fmt.Errorf("no nested module belongs to group '%s'", group)
+		}
+	}
+
+	var selected []models.Submodule
+	for _, submodule := range submodules {
+		if submodule.InGroups(groups) {
+			selected = append(selected, submodule)
+		}
+	}
+
+	return selected, nil
+}
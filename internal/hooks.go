@@ -0,0 +1,177 @@
+package internal
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/utils"
+	"strings"
+)
+
+const (
+	HOOK_STATUS_NOT_INSTALLED = iota
+	HOOK_STATUS_INSTALLED
+	HOOK_STATUS_INSTALLED_CHAINED
+	HOOK_STATUS_FOREIGN
+)
+
+/*
+HookMarker is contained in every hook written by git-nest. It is used to tell managed hooks apart from user hooks.
+*/
+const HookMarker = "# git-nest managed hook"
+
+/*
+ChainedHookSuffix is appended to existing user hooks, which are then called by the managed hook.
+*/
+const ChainedHookSuffix = ".git-nest-chained"
+
+/*
+ManagedHooks contains the names of all git hooks that are managed by git-nest.
+*/
+var ManagedHooks = []string{"post-checkout", "post-merge", "post-rewrite", PreCommitHook}
+
+/*
+SyncHooks contains the names of the git hooks that synchronize nested modules. They are installed by default.
+*/
+var SyncHooks = []string{"post-checkout", "post-merge", "post-rewrite"}
+
+/*
+PreCommitHook contains the name of the git hook that guards against committing nested module contents.
+*/
+const PreCommitHook = "pre-commit"
+
+/*
+HooksDirectory returns the directory git reads hooks from, respecting core.hooksPath.
+*/
+func HooksDirectory(projectRoot models.Path) (models.Path, error) {
+	hooksDir, err := utils.GetGitPath(projectRoot, "hooks")
+	if err != nil {
+		return "", fmt.Errorf("could not evaluate hooks directory: %w", err)
+	}
+
+	return hooksDir, nil
+}
+
+/*
+HookScript returns the content of a managed hook. projectDir is the project root relative to the git repository
+root, which is the working directory git runs hooks in.
+*/
+func HookScript(hook string, projectDir models.Path) string {
+	builder := strings.Builder{}
+
+	builder.WriteString("#!/bin/sh\n")
+	builder.WriteString(HookMarker + "\n")
+	builder.WriteString("# changes are overwritten by 'git nest hooks install', remove with 'git nest hooks uninstall'\n\n")
+
+	// chained user hook runs first and keeps its exit code
+	builder.WriteString(fmt.Sprintf("if [ -x \"$0%s\" ]; then\n", ChainedHookSuffix))
+	builder.WriteString(fmt.Sprintf("\t\"$0%s\" \"$@\" || exit $?\n", ChainedHookSuffix))
+	builder.WriteString("fi\n\n")
+
+	if hook == PreCommitHook {
+		writePreCommitHookBody(&builder, projectDir)
+	} else {
+		writeSyncHookBody(&builder, hook, projectDir)
+	}
+
+	return builder.String()
+}
+
+/*
+HookStatus returns in what state a hook exists in the passed hooks directory.
+*/
+func HookStatus(hooksDir models.Path, hook string) (int, error) {
+	hookFile := hooksDir.SJoin(hook)
+
+	if !hookFile.Exists() {
+		return HOOK_STATUS_NOT_INSTALLED, nil
+	}
+
+	managed, err := IsManagedHook(hookFile)
+	if err != nil {
+		return HOOK_STATUS_NOT_INSTALLED, err
+	}
+
+	if !managed {
+		return HOOK_STATUS_FOREIGN, nil
+	}
+
+	chainedHookFile := hooksDir.SJoin(hook + ChainedHookSuffix)
+	if chainedHookFile.Exists() {
+		return HOOK_STATUS_INSTALLED_CHAINED, nil
+	}
+
+	return HOOK_STATUS_INSTALLED, nil
+}
+
+/*
+IsManagedHook returns whether a hook file was written by git-nest.
+*/
+func IsManagedHook(hookFile models.Path) (bool, error) {
+	if hookFile.IsDir() {
+		return false, fmt.Errorf("%s is a directory", hookFile)
+	}
+
+	content, err := utils.ReadFileToStr(hookFile)
+	if err != nil {
+		return false, fmt.Errorf("could not read hook %s: %w", hookFile, err)
+	}
+
+	return strings.Contains(content, HookMarker), nil
+}
+
+/*
+HookProjectDirectory returns the project root relative to the git repository root.
+*/
+func HookProjectDirectory(context models.NestContext) (models.Path, error) {
+	rel, err := context.GitRepositoryRoot.Relative(context.ProjectRoot)
+	if err != nil {
+		return "", fmt.Errorf("could not evaluate project root relative to git repository: %w", err)
+	}
+
+	return rel, nil
+}
+
+func writeSyncHookBody(builder *strings.Builder, hook string, projectDir models.Path) {
+	// post-checkout is also called on file checkouts, which don't change the configuration
+	if hook == "post-checkout" {
+		builder.WriteString("[ \"$3\" = \"1\" ] || exit 0\n\n")
+	}
+
+	builder.WriteString("command -v git-nest >/dev/null 2>&1 || exit 0\n\n")
+
+	// nested modules are independent repositories and must not inherit the parent's repository
+	builder.WriteString("unset GIT_DIR GIT_WORK_TREE GIT_INDEX_FILE\n")
+	writeChangeToProjectDir(builder, projectDir)
+
+	builder.WriteString("\ngit-nest sync --from-config --non-interactive || echo \"git-nest: could not synchronize nested modules, run 'git nest sync --from-config' manually\" >&2\n")
+	builder.WriteString("exit 0\n")
+}
+
+func writePreCommitHookBody(builder *strings.Builder, projectDir models.Path) {
+	builder.WriteString("if ! command -v git-nest >/dev/null 2>&1; then\n")
+	builder.WriteString("\techo \"git-nest: not installed, skipping check of staged paths\" >&2\n")
+	builder.WriteString("\texit 0\n")
+	builder.WriteString("fi\n\n")
+
+	// paths git passes to the hook are relative to the repository root, which is left next
+	for _, variable := range []string{"GIT_DIR", "GIT_WORK_TREE", "GIT_INDEX_FILE"} {
+		builder.WriteString(fmt.Sprintf("case \"$%s\" in\n", variable))
+		builder.WriteString("\t\"\" | /*) ;;\n")
+		builder.WriteString(fmt.Sprintf("\t*) %s=\"$PWD/$%s\"; export %s ;;\n", variable, variable, variable))
+		builder.WriteString("esac\n")
+	}
+	writeChangeToProjectDir(builder, projectDir)
+
+	builder.WriteString("\nexec git-nest check-staged\n")
+}
+
+func writeChangeToProjectDir(builder *strings.Builder, projectDir models.Path) {
+	projectDirStr := projectDir.UnixString()
+	if projectDirStr != "" && projectDirStr != "." {
+		builder.WriteString(fmt.Sprintf("cd %s 2>/dev/null || exit 0\n", shellQuote(projectDirStr)))
+	}
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "'\\''") + "'"
+}
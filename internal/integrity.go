@@ -0,0 +1,109 @@
+package internal
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/utils"
+	"strings"
+)
+
+/*
+SubmoduleIntegrityReport contains the result of comparing a nested module with its recorded integrity hashes.
+*/
+type SubmoduleIntegrityReport struct {
+	/*
+		Recorded defines whether any integrity hash is recorded for the nested module.
+	*/
+	Recorded bool
+
+	/*
+		TreeModified defines whether the tree of the nested module's HEAD differs from the recorded tree hash.
+	*/
+	TreeModified bool
+
+	/*
+		WorktreeModified defines whether the nested module's working tree differs from the recorded worktree hash.
+	*/
+	WorktreeModified bool
+
+	/*
+		Error contains an error that occurred while computing the nested module's hashes.
+	*/
+	Error error
+}
+
+/*
+Modified returns whether the nested module differs from its recorded integrity hashes.
+*/
+func (r SubmoduleIntegrityReport) Modified() bool {
+	return r.TreeModified || r.WorktreeModified
+}
+
+/*
+String returns a string representation of this SubmoduleIntegrityReport.
+*/
+func (r SubmoduleIntegrityReport) String() string {
+	switch {
+	case r.Error != nil:
+		return "error: " + r.Error.Error()
+	case !r.Recorded:
+		return "no integrity hashes recorded"
+	case !r.Modified():
+		return "ok"
+	}
+
+	var changes []string
+	if r.TreeModified {
+		changes = append(changes, "tree hash differs")
+	}
+	if r.WorktreeModified {
+		changes = append(changes, "working tree differs")
+	}
+
+	return "modified: " + strings.Join(changes, ", ")
+}
+
+/*
+SubmoduleIntegrityHashes computes the tree hash of a nested module's HEAD and the checksum of its working tree.
+The working tree contains all tracked files and all untracked files that are not ignored.
+*/
+func SubmoduleIntegrityHashes(repository models.Path) (string, string, error) {
+	treeHash, err := utils.GetGitTreeHash(repository, "HEAD")
+	if err != nil {
+		return "", "", fmt.Errorf("could not get tree hash: %w", err)
+	}
+
+	files, err := utils.GetGitWorktreeFiles(repository)
+	if err != nil {
+		return "", "", fmt.Errorf("could not list files: %w", err)
+	}
+
+	worktreeHash, err := utils.CalculateChecksumFiles(repository, files...)
+	if err != nil {
+		return "", "", fmt.Errorf("could not calculate worktree hash: %w", err)
+	}
+
+	return treeHash, worktreeHash, nil
+}
+
+/*
+VerifySubmoduleIntegrity recomputes a nested module's integrity hashes and compares them with the recorded ones.
+Hashes that are not recorded are not compared.
+*/
+func VerifySubmoduleIntegrity(s models.Submodule, root models.Path) SubmoduleIntegrityReport {
+	report := SubmoduleIntegrityReport{Recorded: s.TreeHash != "" || s.WorktreeHash != ""}
+	if !report.Recorded {
+		return report
+	}
+
+	treeHash, worktreeHash, err := SubmoduleIntegrityHashes(root.Join(s.Path))
+	if err != nil {
+		report.Error = err
+		return report
+	}
+
+	report.TreeModified = s.TreeHash != "" && s.TreeHash != treeHash
+	report.WorktreeModified = s.WorktreeHash != "" && s.WorktreeHash != worktreeHash
+
+	return report
+}
@@ -0,0 +1,294 @@
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jeftadlvw/git-nest/internal/constants"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/utils"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+/*
+Journal records the progress of a command's migrations, so that an interrupted run can be detected and rolled back
+by the next invocation. It is removed once all migrations have run.
+*/
+type Journal struct {
+	/*
+		Command contains the arguments of the journaled command.
+	*/
+	Command []string `json:"command"`
+
+	/*
+		StartedAt contains the time the command started.
+	*/
+	StartedAt time.Time `json:"started_at"`
+
+	/*
+		Backups maps configuration files to their backup within the journal's backup directory.
+		Files that did not exist when the command started are mapped to an empty string.
+	*/
+	Backups map[string]string `json:"backups"`
+
+	/*
+		Steps contains the started migrations.
+	*/
+	Steps []JournalStep `json:"steps"`
+
+	file models.Path
+}
+
+/*
+JournalStep records a single migration.
+*/
+type JournalStep struct {
+	Description string `json:"description"`
+	Done        bool   `json:"done"`
+
+	/*
+		CreatedPaths contains the paths the migration creates that did not exist before it started.
+	*/
+	CreatedPaths []string `json:"created_paths,omitempty"`
+}
+
+/*
+InterruptedStep returns the first step that has not finished, or nil.
+*/
+func (j *Journal) InterruptedStep() *JournalStep {
+	for index := range j.Steps {
+		if !j.Steps[index].Done {
+			return &j.Steps[index]
+		}
+	}
+
+	return nil
+}
+
+/*
+String returns a human-readable summary of this Journal.
+*/
+func (j *Journal) String() string {
+	done := 0
+	for _, step := range j.Steps {
+		if step.Done {
+			done++
+		}
+	}
+
+	s := fmt.Sprintf("'%s %s' started at %s was interrupted after %d of %d started steps", constants.ApplicationName, strings.Join(j.Command, " "), j.StartedAt.Local().Format(time.DateTime), done, len(j.Steps))
+	if step := j.InterruptedStep(); step != nil {
+		s += fmt.Sprintf(" (during %s)", step.Description)
+	}
+
+	return s
+}
+
+/*
+JournalFileFromContext returns the Path to the journal file of a models.NestContext. Returns an empty Path if
+the project is not a git repository.
+*/
+func JournalFileFromContext(c models.NestContext) models.Path {
+	if !c.IsGitInstalled || !c.IsGitRepository {
+		return ""
+	}
+
+	gitDir := GitCommonDirFromContext(c)
+	return gitDir.SJoin(constants.StateDirName, constants.JournalFileName)
+}
+
+/*
+JournalBackupFiles returns the files that are restored if an interrupted run of a models.NestContext is rolled back:
+the configuration file, the git exclude file, managed .gitignore files and the state file.
+*/
+func JournalBackupFiles(c models.NestContext) []models.Path {
+	files := []models.Path{c.ConfigFile}
+
+	if c.IsGitInstalled && c.IsGitRepository {
+		gitDir := GitCommonDirFromContext(c)
+		files = append(files, gitDir.SJoin(gitExcludeFile), StateFileFromContext(c))
+	}
+
+	gitignoreFiles, _ := FindManagedGitignoreFiles(c.ProjectRoot)
+	for _, gitignoreFile := range gitignoreFiles {
+		files = append(files, c.ProjectRoot.SJoin(gitignoreFile))
+	}
+
+	return files
+}
+
+/*
+ReadJournal reads the Journal of an interrupted run. Returns nil if no journal exists.
+*/
+func ReadJournal(p models.Path) (*Journal, error) {
+	if p.Empty() || !p.IsFile() {
+		return nil, nil
+	}
+
+	content, err := utils.ReadFileToStr(p)
+	if err != nil {
+		return nil, fmt.Errorf("could not read journal %s: %w", p, err)
+	}
+
+	journal := &Journal{file: p}
+	err = json.Unmarshal([]byte(content), journal)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse journal %s: %w", p, err)
+	}
+
+	return journal, nil
+}
+
+/*
+StartJournal creates a new Journal for a command and backs up the passed configuration files.
+Returns an error if the journal of an interrupted run exists.
+*/
+func StartJournal(p models.Path, command []string, backupFiles []models.Path) (*Journal, error) {
+	existing, err := ReadJournal(p)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("a previous run was interrupted, run '%s recover' to resume or roll it back first", constants.ApplicationName)
+	}
+
+	backupDir := journalBackupDir(p)
+	err = os.RemoveAll(backupDir.String())
+	if err == nil {
+		err = os.MkdirAll(backupDir.String(), os.ModePerm)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not create journal backup directory: %w", err)
+	}
+
+	journal := &Journal{Command: command, StartedAt: time.Now().UTC().Truncate(time.Second), Backups: map[string]string{}, Steps: []JournalStep{}, file: p}
+	for index, file := range backupFiles {
+		if file.Empty() {
+			continue
+		}
+
+		if !file.IsFile() {
+			journal.Backups[file.String()] = ""
+			continue
+		}
+
+		content, err := utils.ReadFileToStr(file)
+		if err != nil {
+			return nil, fmt.Errorf("could not back up %s: %w", file, err)
+		}
+
+		backup := backupDir.SJoin(fmt.Sprintf("%03d-%s", index+1, file.Base()))
+		err = utils.WriteStrToFileAtomic(backup, content)
+		if err != nil {
+			return nil, fmt.Errorf("could not back up %s: %w", file, err)
+		}
+
+		journal.Backups[file.String()] = backup.String()
+	}
+
+	return journal, journal.write()
+}
+
+/*
+StartStep records the start of a migration. Created paths that already exist are not recorded.
+*/
+func (j *Journal) StartStep(description string, createdPaths []string) error {
+	step := JournalStep{Description: description}
+	for _, createdPath := range createdPaths {
+		if _, err := os.Lstat(createdPath); errors.Is(err, os.ErrNotExist) {
+			step.CreatedPaths = append(step.CreatedPaths, createdPath)
+		}
+	}
+
+	j.Steps = append(j.Steps, step)
+	return j.write()
+}
+
+/*
+FinishStep records that the last started migration has finished.
+*/
+func (j *Journal) FinishStep() error {
+	if len(j.Steps) == 0 {
+		return errors.New("no journal step has been started")
+	}
+
+	j.Steps[len(j.Steps)-1].Done = true
+	return j.write()
+}
+
+/*
+Finish removes the journal and its backups after all migrations have run.
+*/
+func (j *Journal) Finish() error {
+	return errors.Join(os.RemoveAll(string(journalBackupDir(j.file))), os.Remove(j.file.String()))
+}
+
+/*
+Rollback undoes what can be undone of an interrupted run: paths created by its migrations are deleted, newest first,
+and configuration files are restored from their backups. Finally, the journal is removed.
+*/
+func (j *Journal) Rollback() error {
+	var errs []error
+
+	for index := len(j.Steps) - 1; index >= 0; index-- {
+		for _, createdPath := range j.Steps[index].CreatedPaths {
+			err := os.RemoveAll(createdPath)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("could not delete %s: %w", createdPath, err))
+			}
+		}
+	}
+
+	for file, backup := range j.Backups {
+		var err error
+		if backup == "" {
+			err = os.Remove(file)
+			if errors.Is(err, os.ErrNotExist) {
+				err = nil
+			}
+		} else {
+			var content string
+			content, err = utils.ReadFileToStr(models.Path(backup))
+			if err == nil {
+				err = utils.WriteStrToFileAtomic(models.Path(file), content)
+			}
+		}
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("could not restore %s: %w", file, err))
+		}
+	}
+
+	if len(errs) != 0 {
+		return errors.Join(errs...)
+	}
+
+	return j.Finish()
+}
+
+/*
+write persists this Journal.
+*/
+func (j *Journal) write() error {
+	content, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not serialize journal: %w", err)
+	}
+
+	err = utils.WriteStrToFileAtomic(j.file, string(content)+"\n")
+	if err != nil {
+		return fmt.Errorf("could not write journal: %w", err)
+	}
+
+	return nil
+}
+
+/*
+journalBackupDir returns the backup directory next to a journal file.
+*/
+func journalBackupDir(journalFile models.Path) models.Path {
+	return models.Path(filepath.Join(filepath.Dir(journalFile.String()), constants.JournalBackupDirName))
+}
@@ -0,0 +1,116 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+/*
+LogFormat defines how log records are written.
+*/
+type LogFormat string
+
+const (
+	LogFormatText LogFormat = "text"
+	LogFormatJson LogFormat = "json"
+)
+
+/*
+ParseLogFormat parses a log format. An empty string results in LogFormatText.
+*/
+func ParseLogFormat(s string) (LogFormat, error) {
+	switch format := LogFormat(strings.ToLower(strings.TrimSpace(s))); format {
+	case "", LogFormatText:
+		return LogFormatText, nil
+	case LogFormatJson:
+		return format, nil
+	}
+
+	return "", fmt.Errorf("invalid log format '%s', expected one of %s, %s", s, LogFormatText, LogFormatJson)
+}
+
+/*
+LogLevel returns the log level for the passed verbosity, which is the number of times the verbose flag was passed.
+By default, only warnings and errors are logged, quiet restricts logging to errors.
+*/
+func LogLevel(verbosity int, quiet bool) slog.Level {
+	switch {
+	case quiet:
+		return slog.LevelError
+	case verbosity >= 2:
+		return slog.LevelDebug
+	case verbosity == 1:
+		return slog.LevelInfo
+	}
+
+	return slog.LevelWarn
+}
+
+/*
+NewLogger returns a logger that writes records of at least the passed level to w in the passed format.
+If trace is not nil, all records are additionally written to it in json format, regardless of their level.
+*/
+func NewLogger(w io.Writer, level slog.Level, format LogFormat, trace io.Writer) *slog.Logger {
+	options := &slog.HandlerOptions{Level: level}
+
+	var handler slog.Handler
+	if format == LogFormatJson {
+		handler = slog.NewJSONHandler(w, options)
+	} else {
+		handler = slog.NewTextHandler(w, options)
+	}
+
+	if trace == nil {
+		return slog.New(handler)
+	}
+
+	return slog.New(multiHandler{handler, slog.NewJSONHandler(trace, &slog.HandlerOptions{Level: slog.LevelDebug})})
+}
+
+/*
+multiHandler is a slog.Handler that passes records to multiple handlers.
+*/
+type multiHandler []slog.Handler
+
+func (h multiHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, handler := range h {
+		if handler.Enabled(ctx, level) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (h multiHandler) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+	for _, handler := range h {
+		if handler.Enabled(ctx, r.Level) {
+			errs = append(errs, handler.Handle(ctx, r.Clone()))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (h multiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make(multiHandler, 0, len(h))
+	for _, handler := range h {
+		handlers = append(handlers, handler.WithAttrs(attrs))
+	}
+
+	return handlers
+}
+
+func (h multiHandler) WithGroup(name string) slog.Handler {
+	handlers := make(multiHandler, 0, len(h))
+	for _, handler := range h {
+		handlers = append(handlers, handler.WithGroup(name))
+	}
+
+	return handlers
+}
@@ -0,0 +1,180 @@
+package internal
+
+import (
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/utils"
+)
+
+/*
+SubmoduleOutdatedReport contains information on how far a nested module lags behind its remote.
+*/
+type SubmoduleOutdatedReport struct {
+	/*
+		Path contains the nested module's path relative to the project root.
+	*/
+	Path string `json:"path"`
+
+	/*
+		Url contains the nested module's remote url.
+	*/
+	Url string `json:"url"`
+
+	/*
+		Ref contains the configured reference.
+	*/
+	Ref string `json:"ref"`
+
+	/*
+		CurrentRef contains the local branch or tag the nested module is checked out at.
+	*/
+	CurrentRef string `json:"current_ref"`
+
+	/*
+		CurrentSha contains the local commit hash the nested module is checked out at.
+	*/
+	CurrentSha string `json:"current_sha"`
+
+	/*
+		LatestTag contains the newest tag the remote advertises.
+	*/
+	LatestTag string `json:"latest_tag"`
+
+	/*
+		Branch contains the remote branch the nested module is compared against.
+	*/
+	Branch string `json:"branch"`
+
+	/*
+		BranchTip contains the commit hash of the remote branch's tip.
+	*/
+	BranchTip string `json:"branch_tip"`
+
+	/*
+		Behind contains how many commits the nested module is behind the compared remote commit.
+		-1 if that can't be computed without fetching.
+	*/
+	Behind int `json:"behind"`
+
+	/*
+		Outdated defines whether the nested module lags behind its remote.
+	*/
+	Outdated bool `json:"outdated"`
+
+	/*
+		Error contains a description of an error that occurred while checking the nested module.
+	*/
+	Error string `json:"error,omitempty"`
+}
+
+/*
+Status returns a short description of the report, e.g. for tables.
+*/
+func (r SubmoduleOutdatedReport) Status() string {
+	switch {
+	case r.Error != "":
+		return "error: " + r.Error
+	case r.CurrentSha == "":
+		return "does not exist"
+	case r.Outdated:
+		return "outdated"
+	}
+
+	return "up to date"
+}
+
+/*
+SubmoduleOutdated queries a nested module's remote and compares it to the local state.
+
+Modules that are checked out at a branch are compared against the remote tip of that branch.
+Modules that are checked out at a tag are compared against the newest remote tag. Any other module
+is compared against the tip of the remote's default branch.
+*/
+func SubmoduleOutdated(s models.Submodule, root models.Path) SubmoduleOutdatedReport {
+	report := SubmoduleOutdatedReport{
+		Path:   s.Path.UnixString(),
+		Ref:    s.Ref,
+		Behind: -1,
+	}
+
+	if s.Url != nil {
+		report.Url = s.Url.String()
+	}
+
+	remoteRefs, err := utils.GetGitRemoteRefs(report.Url)
+	if err != nil {
+		report.Error = err.Error()
+		return report
+	}
+
+	if len(remoteRefs.TagOrder) != 0 {
+		report.LatestTag = remoteRefs.TagOrder[len(remoteRefs.TagOrder)-1]
+	}
+
+	// evaluate local state, if the module exists
+	var currentTag string
+	submodulePath := root.Join(s.Path)
+	if submodulePath.IsDir() {
+		headLong, headAbbrev, err := utils.GetGitFetchHead(submodulePath)
+		if err != nil {
+			report.Error = err.Error()
+		} else {
+			report.CurrentSha = headLong
+			report.CurrentRef = headAbbrev
+		}
+
+		if headAbbrev == "" {
+			currentTag, _ = utils.GetGitExactTag(submodulePath)
+			report.CurrentRef = currentTag
+		}
+	}
+
+	// evaluate branch to compare against
+	report.Branch = remoteRefs.Head
+	if _, ok := remoteRefs.Branches[report.CurrentRef]; ok && currentTag == "" {
+		report.Branch = report.CurrentRef
+	} else if _, ok := remoteRefs.Branches[s.Ref]; ok && report.CurrentSha == "" {
+		report.Branch = s.Ref
+	}
+	report.BranchTip = remoteRefs.Branches[report.Branch]
+
+	// nothing to compare against if module does not exist locally
+	if report.CurrentSha == "" {
+		return report
+	}
+
+	compareSha := report.BranchTip
+	if currentTag != "" && report.LatestTag != "" {
+		compareSha = remoteRefs.Tags[report.LatestTag]
+	}
+
+	if compareSha == "" {
+		return report
+	}
+
+	report.Outdated = compareSha != report.CurrentSha
+
+	behind, err := utils.GetGitCommitsBehind(submodulePath, compareSha)
+	if err == nil {
+		report.Behind = behind
+
+		// remote moved, but local contains it already (e.g. local commits on top)
+		if behind == 0 {
+			report.Outdated = false
+		}
+	}
+
+	return report
+}
+
+/*
+SubmodulesOutdated takes multiple submodules and creates an outdated report for each of them.
+*/
+func SubmodulesOutdated(submodules []models.Submodule, root models.Path) []SubmoduleOutdatedReport {
+	var reports []SubmoduleOutdatedReport
+
+	for _, submodule := range submodules {
+		reports = append(reports, SubmoduleOutdated(submodule, root))
+	}
+
+	return reports
+}
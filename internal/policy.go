@@ -0,0 +1,137 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/utils"
+	"strings"
+)
+
+/*
+policyFile represents a standalone policy file, e.g. an organisation-wide policy.
+*/
+type policyFile struct {
+	Policy models.Policy `toml:"policy"`
+}
+
+/*
+ReadPolicyFile reads a models.Policy from the [policy] table of a standalone policy file.
+*/
+func ReadPolicyFile(p models.Path) (models.Policy, error) {
+	if !p.IsFile() {
+		return models.Policy{}, fmt.Errorf("policy file %s does not exist", p)
+	}
+
+	content, err := utils.ReadFileToStr(p)
+	if err != nil {
+		return models.Policy{}, fmt.Errorf("could not read policy file %s: %w", p, err)
+	}
+
+	file := policyFile{}
+	md, err := toml.Decode(content, &file)
+	if err != nil {
+		return models.Policy{}, fmt.Errorf("could not parse policy file %s: %w", p, err)
+	}
+
+	undecoded := md.Undecoded()
+	if len(undecoded) != 0 {
+		return models.Policy{}, fmt.Errorf("policy file %s contains unsupported keys: %q", p, undecoded)
+	}
+
+	err = file.Policy.Validate()
+	if err != nil {
+		return models.Policy{}, fmt.Errorf("policy file %s: %w", p, err)
+	}
+
+	return file.Policy, nil
+}
+
+/*
+SubmodulePolicyViolations returns all violations of a models.Policy by a nested module.
+If ref kinds are required and the kind is not declared, it is detected from the nested module's repository or,
+if the module does not exist yet, from its remote.
+*/
+func SubmodulePolicyViolations(policy models.Policy, s models.Submodule, root models.Path) []models.PolicyViolation {
+	return MovedSubmodulePolicyViolations(policy, s, s.Path, root)
+}
+
+/*
+MovedSubmodulePolicyViolations returns all violations of a models.Policy by a nested module once it is moved to
+newPath. Like SubmodulePolicyViolations, the ref kind is detected from the nested module at its current path.
+*/
+func MovedSubmodulePolicyViolations(policy models.Policy, s models.Submodule, newPath models.Path, root models.Path) []models.PolicyViolation {
+	kind := models.RefKindAuto
+	if len(policy.RequiredRefKinds) != 0 && s.Ref != "" {
+		kind = policyRefKind(s, root.Join(s.Path))
+	}
+
+	s.Path = newPath
+	return policy.Violations(s, kind)
+}
+
+/*
+PolicyViolations returns all violations of a models.Policy by multiple nested modules.
+*/
+func PolicyViolations(policy models.Policy, submodules []models.Submodule, root models.Path) []models.PolicyViolation {
+	var violations []models.PolicyViolation
+	for _, submodule := range submodules {
+		violations = append(violations, SubmodulePolicyViolations(policy, submodule, root)...)
+	}
+
+	return violations
+}
+
+/*
+PolicyError combines policy violations into a single error. Returns nil if there are no violations.
+*/
+func PolicyError(violations []models.PolicyViolation) error {
+	if len(violations) == 0 {
+		return nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("policy violations:")
+	for _, violation := range violations {
+		sb.WriteString("\n  - ")
+		sb.WriteString(violation.String())
+	}
+
+	return errors.New(sb.String())
+}
+
+/*
+policyRefKind returns the kind of a Submodule's ref for policy evaluation. In contrast to SubmoduleRefKind,
+refs of nested modules that do not exist locally are looked up at the remote. Returns models.RefKindAuto
+if the kind cannot be determined.
+*/
+func policyRefKind(s models.Submodule, repository models.Path) models.RefKind {
+	if s.RefKind != models.RefKindAuto {
+		return s.RefKind
+	}
+
+	if repository.IsDir() {
+		if kind, err := utils.GetGitRefKind(repository, s.Ref, s.RemoteName()); err == nil {
+			return kind
+		}
+	}
+
+	if s.Url != nil {
+		remoteRefs, err := utils.GetGitRemoteRefs(s.Url.String())
+		if err == nil {
+			if _, ok := remoteRefs.Branches[s.Ref]; ok {
+				return models.RefKindBranch
+			}
+			if _, ok := remoteRefs.Tags[s.Ref]; ok {
+				return models.RefKindTag
+			}
+		}
+	}
+
+	if models.IsCommitHash(s.Ref) {
+		return models.RefKindCommit
+	}
+
+	return models.RefKindAuto
+}
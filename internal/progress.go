@@ -0,0 +1,306 @@
+package internal
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/interfaces"
+	"github.com/jeftadlvw/git-nest/models"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+)
+
+/*
+ProgressMode defines how the progress of long-running operations is reported.
+*/
+type ProgressMode string
+
+const (
+	/*
+		ProgressModeAuto renders progress bars if stderr is a terminal and plain lines otherwise.
+	*/
+	ProgressModeAuto ProgressMode = "auto"
+
+	/*
+		ProgressModeTty renders a progress bar for every running operation, which are redrawn in place.
+	*/
+	ProgressModeTty ProgressMode = "tty"
+
+	/*
+		ProgressModePlain prints progress as plain lines, e.g. for CI logs.
+	*/
+	ProgressModePlain ProgressMode = "plain"
+
+	/*
+		ProgressModeNone does not report progress.
+	*/
+	ProgressModeNone ProgressMode = "none"
+)
+
+/*
+ParseProgressMode parses a progress mode. An empty string results in ProgressModeAuto.
+*/
+func ParseProgressMode(s string) (ProgressMode, error) {
+	switch mode := ProgressMode(strings.ToLower(strings.TrimSpace(s))); mode {
+	case "":
+		return ProgressModeAuto, nil
+	case ProgressModeAuto, ProgressModeTty, ProgressModePlain, ProgressModeNone:
+		return mode, nil
+	}
+
+	return "", fmt.Errorf("invalid progress mode '%s', expected one of %s, %s, %s, %s", s, ProgressModeAuto, ProgressModeTty, ProgressModePlain, ProgressModeNone)
+}
+
+/*
+progressBarWidth defines the number of characters within a progress bar, excluding its brackets.
+*/
+const progressBarWidth = 20
+
+/*
+plainProgressStep defines the percentage after which a plain progress reporter prints another line for the same phase.
+*/
+const plainProgressStep = 25
+
+/*
+progressReporter contains the reporter of all long-running operations, see SetProgressReporter.
+*/
+var progressReporter interfaces.ProgressReporter = NewSilentProgressReporter(os.Stderr)
+
+/*
+SetProgressReporter sets the reporter of all long-running operations. Passing nil disables progress reporting.
+*/
+func SetProgressReporter(r interfaces.ProgressReporter) {
+	if r == nil {
+		r = NewSilentProgressReporter(io.Discard)
+	}
+
+	progressReporter = r
+}
+
+/*
+ProgressReporter returns the reporter set by SetProgressReporter.
+*/
+func ProgressReporter() interfaces.ProgressReporter {
+	return progressReporter
+}
+
+/*
+progressSummary returns the line that is printed once an operation finished.
+*/
+func progressSummary(name string, err error) string {
+	if err != nil {
+		return fmt.Sprintf("%s: failed.", name)
+	}
+
+	return fmt.Sprintf("%s: done.", name)
+}
+
+/*
+NewTtyProgressReporter returns a reporter that renders a progress bar for every running operation on its own line,
+which are redrawn in place using ANSI escape sequences. Lines are shortened to the width returned by width, so that
+they do not wrap. Finished operations are replaced by a summary line.
+*/
+func NewTtyProgressReporter(w io.Writer, width func() int) interfaces.ProgressReporter {
+	return &ttyProgressReporter{w: w, width: width}
+}
+
+type ttyProgressReporter struct {
+	mutex sync.Mutex
+	w     io.Writer
+	width func() int
+	tasks []*ttyProgressTask
+	lines int
+}
+
+type ttyProgressTask struct {
+	reporter *ttyProgressReporter
+	name     string
+	progress *models.Progress
+}
+
+func (r *ttyProgressReporter) Start(name string) interfaces.ProgressTask {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	task := &ttyProgressTask{reporter: r, name: name}
+	r.tasks = append(r.tasks, task)
+	r.redraw()
+
+	return task
+}
+
+func (r *ttyProgressReporter) Message(message string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.redraw(message)
+}
+
+func (t *ttyProgressTask) Update(progress models.Progress) {
+	t.reporter.mutex.Lock()
+	defer t.reporter.mutex.Unlock()
+
+	// git also reports throughput, which is not rendered
+	if t.progress != nil && *t.progress == progress {
+		return
+	}
+
+	t.progress = &progress
+	t.reporter.redraw()
+}
+
+func (t *ttyProgressTask) Done(err error) {
+	t.reporter.mutex.Lock()
+	defer t.reporter.mutex.Unlock()
+
+	index := slices.Index(t.reporter.tasks, t)
+	if index < 0 {
+		return
+	}
+
+	t.reporter.tasks = slices.Delete(t.reporter.tasks, index, index+1)
+	t.reporter.redraw(progressSummary(t.name, err))
+}
+
+/*
+redraw replaces the lines of running operations. The passed lines are printed above them and are not redrawn again.
+*/
+func (r *ttyProgressReporter) redraw(printed ...string) {
+	width := 80
+	if r.width != nil {
+		if w := r.width(); w > 0 {
+			width = w
+		}
+	}
+
+	var b strings.Builder
+
+	// move to the first line of running operations and clear everything below
+	if r.lines > 0 {
+		_, _ = fmt.Fprintf(&b, "\x1b[%dA", r.lines)
+	}
+	b.WriteString("\r\x1b[J")
+
+	for _, line := range printed {
+		b.WriteString(line + "\n")
+	}
+
+	for _, task := range r.tasks {
+		b.WriteString(shortenLine(task.line(), width-1) + "\n")
+	}
+	r.lines = len(r.tasks)
+
+	_, _ = io.WriteString(r.w, b.String())
+}
+
+/*
+line returns the progress bar of this task, e.g. 'lib [#########...........]  45% Receiving objects (450/1000)'.
+*/
+func (t *ttyProgressTask) line() string {
+	if t.progress == nil {
+		return fmt.Sprintf("%s: busy", t.name)
+	}
+
+	filled := t.progress.Percent * progressBarWidth / 100
+	bar := strings.Repeat("#", filled) + strings.Repeat(".", progressBarWidth-filled)
+
+	return fmt.Sprintf("%s [%s] %3d%% %s (%d/%d)", t.name, bar, t.progress.Percent, t.progress.Phase, t.progress.Current, t.progress.Total)
+}
+
+/*
+shortenLine shortens a line to width characters, adding an ellipsis if it was shortened.
+*/
+func shortenLine(line string, width int) string {
+	runes := []rune(line)
+	if len(runes) <= width {
+		return line
+	}
+
+	if width <= 3 {
+		return string(runes[:max(0, width)])
+	}
+
+	return string(runes[:width-3]) + "..."
+}
+
+/*
+NewPlainProgressReporter returns a reporter that prints progress as plain lines, e.g. for CI logs. A line is printed
+whenever an operation starts or finishes, enters another phase or progressed by another 25 percent.
+*/
+func NewPlainProgressReporter(w io.Writer) interfaces.ProgressReporter {
+	return &plainProgressReporter{w: w}
+}
+
+type plainProgressReporter struct {
+	mutex sync.Mutex
+	w     io.Writer
+}
+
+type plainProgressTask struct {
+	reporter *plainProgressReporter
+	name     string
+	phase    string
+	percent  int
+}
+
+func (r *plainProgressReporter) Start(name string) interfaces.ProgressTask {
+	r.println(fmt.Sprintf("%s: busy", name))
+	return &plainProgressTask{reporter: r, name: name}
+}
+
+func (r *plainProgressReporter) Message(message string) {
+	r.println(message)
+}
+
+func (r *plainProgressReporter) println(line string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	_, _ = fmt.Fprintln(r.w, line)
+}
+
+func (t *plainProgressTask) Update(progress models.Progress) {
+	t.reporter.mutex.Lock()
+	if progress.Phase == t.phase && progress.Percent < t.percent+plainProgressStep && (progress.Percent < 100 || t.percent == 100) {
+		t.reporter.mutex.Unlock()
+		return
+	}
+
+	t.phase = progress.Phase
+	t.percent = progress.Percent - progress.Percent%plainProgressStep
+	if progress.Percent == 100 {
+		t.percent = 100
+	}
+	t.reporter.mutex.Unlock()
+
+	t.reporter.println(fmt.Sprintf("%s: %s %d%% (%d/%d)", t.name, progress.Phase, progress.Percent, progress.Current, progress.Total))
+}
+
+func (t *plainProgressTask) Done(err error) {
+	t.reporter.println(progressSummary(t.name, err))
+}
+
+/*
+NewSilentProgressReporter returns a reporter that does not report any progress. Messages are still printed to w.
+*/
+func NewSilentProgressReporter(w io.Writer) interfaces.ProgressReporter {
+	return silentProgressReporter{w: w}
+}
+
+type silentProgressReporter struct {
+	w io.Writer
+}
+
+type silentProgressTask struct{}
+
+func (r silentProgressReporter) Start(string) interfaces.ProgressTask {
+	return silentProgressTask{}
+}
+
+func (r silentProgressReporter) Message(message string) {
+	_, _ = fmt.Fprintln(r.w, message)
+}
+
+func (silentProgressTask) Update(models.Progress) {}
+func (silentProgressTask) Done(error)             {}
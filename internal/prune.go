@@ -0,0 +1,108 @@
+package internal
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/models"
+	"path"
+	"slices"
+	"strings"
+)
+
+/*
+OrphanedModule is a directory that looks like a previously nested module, but is no longer part of the configuration.
+*/
+type OrphanedModule struct {
+	/*
+		Path contains the Path to the directory, relative to the project root.
+	*/
+	Path models.Path
+
+	/*
+		Source describes where the directory was last recorded as nested module, e.g. the git exclude file.
+	*/
+	Source string
+}
+
+/*
+FindOrphanedModules returns directories of previously nested modules that are no longer configured, e.g. because
+another contributor removed them from the configuration. Candidates are the modules recorded in the state file and
+the entries of the git-nest blocks in the git exclude file and in managed .gitignore files, which are only updated
+on the next write. Only directories that are repository roots are returned, except for those that were kept on purpose.
+*/
+func FindOrphanedModules(context models.NestContext) ([]OrphanedModule, error) {
+	type candidate struct {
+		path   string
+		source string
+	}
+	var candidates []candidate
+
+	state, err := ReadState(StateFileFromContext(context))
+	if err != nil {
+		return nil, err
+	}
+
+	// modules whose directory was kept on purpose are never orphaned
+	var kept []string
+	for _, module := range state.Modules {
+		if module.Kept {
+			kept = append(kept, path.Clean(module.Path))
+			continue
+		}
+		candidates = append(candidates, candidate{module.Path, "state file"})
+	}
+
+	if context.IsGitInstalled && context.IsGitRepository {
+		gitDir := GitCommonDirFromContext(context)
+		entries, _, err := ReadSubmoduleIgnoreConfig(gitDir.SJoin(gitExcludeFile))
+		if err != nil {
+			return nil, fmt.Errorf("could not read git exclude file: %w", err)
+		}
+
+		for _, entry := range entries {
+			candidates = append(candidates, candidate{entry, "git exclude file"})
+		}
+	}
+
+	gitignoreFiles, err := FindManagedGitignoreFiles(context.ProjectRoot)
+	if err != nil {
+		return nil, fmt.Errorf("could not find .gitignore files: %w", err)
+	}
+
+	for _, gitignoreFile := range gitignoreFiles {
+		entries, _, err := ReadSubmoduleIgnoreConfig(context.ProjectRoot.SJoin(gitignoreFile))
+		if err != nil {
+			return nil, fmt.Errorf("could not read %s: %w", gitignoreFile, err)
+		}
+
+		// gitignore entries are anchored relative to their file
+		for _, entry := range entries {
+			candidates = append(candidates, candidate{path.Join(path.Dir(gitignoreFile), strings.TrimPrefix(entry, "/")), gitignoreFile})
+		}
+	}
+
+	configured := make([]string, len(context.Config.Submodules))
+	for index, submodule := range context.Config.Submodules {
+		configured[index] = submodule.Path.UnixString()
+	}
+
+	var orphans []OrphanedModule
+	var seen []string
+	for _, c := range candidates {
+		p := path.Clean(c.path)
+		if p == "." || path.IsAbs(p) || p == ".." || strings.HasPrefix(p, "../") || slices.Contains(configured, p) || slices.Contains(kept, p) || slices.Contains(seen, p) {
+			continue
+		}
+		seen = append(seen, p)
+
+		modulePath := models.Path(p)
+		absolutePath := context.ProjectRoot.Join(modulePath)
+		gitPath := absolutePath.SJoin(gitDirectory)
+		if !absolutePath.IsDir() || !gitPath.Exists() {
+			continue
+		}
+
+		orphans = append(orphans, OrphanedModule{Path: modulePath, Source: c.source})
+	}
+
+	return orphans, nil
+}
@@ -4,32 +4,54 @@ import (
 	"fmt"
 	"os"
 	"syscall"
+	"time"
 )
 
 /*
-HandleOsTerminationSignals handles received system signals, calls a callback and exists.
+terminationGracePeriod defines how long the application may take to shut down after it was cancelled by a signal.
+It exceeds the time that cancelled commands have to exit.
 */
-func HandleOsTerminationSignals(c <-chan os.Signal, callback func()) {
-	for {
-		// waiting for signal to arrive
-		sig := <-c
+const terminationGracePeriod = 15 * time.Second
 
-		// call callback
-		callback()
+/*
+HandleOsTerminationSignals handles received system signals. The first signal is passed to cancel, which is expected
+to abort running commands, so that the application can shut down gracefully. If another signal arrives or the application
+does not exit within a grace period, the callback is called and the application exits.
+*/
+func HandleOsTerminationSignals(c <-chan os.Signal, cancel func(os.Signal), callback func()) {
+	// waiting for signal to arrive
+	sig := <-c
+	cancel(sig)
+
+	_, _ = fmt.Fprintf(os.Stderr, "\n%s\n", fmt.Sprintf("Aborting because of %s signal.", sig))
+
+	select {
+	case sig = <-c:
+	case <-time.After(terminationGracePeriod):
+	}
 
-		// print exit information to stderr
-		_, _ = fmt.Fprintf(os.Stderr, "\n%s\n", fmt.Sprintf("Exiting because of %s signal.", sig))
+	// call callback
+	callback()
 
-		// type assert signal to be of type syscall.Signal
-		sysSig, ok := sig.(syscall.Signal)
+	// print exit information to stderr
+	_, _ = fmt.Fprintf(os.Stderr, "\n%s\n", fmt.Sprintf("Exiting because of %s signal.", sig))
 
-		// apply value of type cast to exitCode
-		exitCode := 1
-		if ok {
-			exitCode = int(sysSig)
-		}
+	// terminate application
+	os.Exit(SignalExitCode(sig))
+}
 
-		// terminate application
-		os.Exit(exitCode)
+/*
+SignalExitCode returns the exit code of an application that terminates because of a signal.
+*/
+func SignalExitCode(sig os.Signal) int {
+	// type assert signal to be of type syscall.Signal
+	sysSig, ok := sig.(syscall.Signal)
+
+	// apply value of type cast to exitCode
+	exitCode := 1
+	if ok {
+		exitCode = int(sysSig)
 	}
+
+	return exitCode
 }
@@ -0,0 +1,22 @@
+package internal
+
+import (
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/utils"
+)
+
+/*
+VerifySubmoduleSignature verifies the signature of a nested module's checked out ref, if verification is enabled.
+Tags are verified using their tag signature, any other ref by the signature of the commit HEAD points to.
+*/
+func VerifySubmoduleSignature(s models.Submodule, repository models.Path, v models.SignatureVerification) error {
+	if !v.Enabled {
+		return nil
+	}
+
+	if s.Ref != "" && SubmoduleRefKind(s, repository) == models.RefKindTag {
+		return utils.GitVerifyTag(repository, s.Ref, v.AllowedSigners, v.GpgHome)
+	}
+
+	return utils.GitVerifyCommit(repository, "HEAD", v.AllowedSigners, v.GpgHome)
+}
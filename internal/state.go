@@ -0,0 +1,304 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/jeftadlvw/git-nest/internal/constants"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/models/urls"
+	"github.com/jeftadlvw/git-nest/utils"
+	"os"
+	"slices"
+	"time"
+)
+
+const (
+	StateChangeAdded   = "added"
+	StateChangeRemoved = "removed"
+	StateChangeUpdated = "updated"
+)
+
+/*
+State records what git-nest last applied to a clone. It is stored outside the working tree and never tracked.
+*/
+type State struct {
+	/*
+		Modules contains the nested modules as they were last applied.
+	*/
+	Modules []StateModule `json:"modules"`
+
+	/*
+		History contains the changes of previous runs, oldest first.
+	*/
+	History []StateHistoryEntry `json:"history"`
+}
+
+/*
+StateModule records a nested module as it was last applied.
+*/
+type StateModule struct {
+	Path      string    `json:"path"`
+	Url       string    `json:"url"`
+	Ref       string    `json:"ref,omitempty"`
+	Commit    string    `json:"commit"`
+	AppliedAt time.Time `json:"applied_at"`
+
+	/*
+		Orphaned defines whether the module has been removed from the configuration while its directory still exists.
+	*/
+	Orphaned bool `json:"orphaned,omitempty"`
+
+	/*
+		Kept defines whether the directory of an orphaned module was kept on purpose, e.g. by 'remove --keep'.
+		Kept modules are never pruned.
+	*/
+	Kept bool `json:"kept,omitempty"`
+}
+
+/*
+StateHistoryEntry contains the changes of a single run.
+*/
+type StateHistoryEntry struct {
+	Time    time.Time     `json:"time"`
+	Command string        `json:"command"`
+	Changes []StateChange `json:"changes"`
+}
+
+/*
+StateChange describes how a nested module changed between two runs.
+*/
+type StateChange struct {
+	Kind           string `json:"kind"`
+	Path           string `json:"path"`
+	Url            string `json:"url,omitempty"`
+	PreviousUrl    string `json:"previous_url,omitempty"`
+	Commit         string `json:"commit,omitempty"`
+	PreviousCommit string `json:"previous_commit,omitempty"`
+}
+
+/*
+String returns a string representation of this StateChange.
+*/
+func (c StateChange) String() string {
+	switch c.Kind {
+	case StateChangeAdded:
+		return fmt.Sprintf("added %s at %s", c.Path, shortCommit(c.Commit))
+	case StateChangeRemoved:
+		return fmt.Sprintf("removed %s", c.Path)
+	}
+
+	s := fmt.Sprintf("updated %s", c.Path)
+	if c.PreviousCommit != c.Commit {
+		s += fmt.Sprintf(" %s -> %s", shortCommit(c.PreviousCommit), shortCommit(c.Commit))
+	}
+	if c.PreviousUrl != c.Url {
+		s += fmt.Sprintf(", url %s -> %s", c.PreviousUrl, c.Url)
+	}
+
+	return s
+}
+
+/*
+StateFileFromContext returns the Path to the state file of a models.NestContext. Returns an empty Path if
+the project is not a git repository.
+*/
+func StateFileFromContext(c models.NestContext) models.Path {
+	if !c.IsGitInstalled || !c.IsGitRepository {
+		return ""
+	}
+
+	gitDir := GitCommonDirFromContext(c)
+	return gitDir.SJoin(constants.StateDirName, constants.StateFileName)
+}
+
+/*
+ReadState reads a State from a file. A missing file results in an empty State.
+*/
+func ReadState(p models.Path) (State, error) {
+	if p.Empty() || !p.Exists() {
+		return State{}, nil
+	}
+
+	content, err := utils.ReadFileToStr(p)
+	if err != nil {
+		return State{}, fmt.Errorf("could not read state file %s: %w", p, err)
+	}
+
+	state := State{}
+	err = json.Unmarshal([]byte(content), &state)
+	if err != nil {
+		return State{}, fmt.Errorf("could not parse state file %s: %w", p, err)
+	}
+
+	return state, nil
+}
+
+/*
+WriteState writes a State to a file, creating its parent directory if required.
+*/
+func WriteState(p models.Path, state State) error {
+	parent := p.Parent()
+	err := os.MkdirAll(parent.String(), os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("could not create directory %s: %w", parent, err)
+	}
+
+	content, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not serialize state: %w", err)
+	}
+
+	return utils.WriteStrToFileAtomic(p, string(content)+"\n")
+}
+
+/*
+UpdateState compares the nested modules of a context with the previously applied ones, records the changes in
+the state's history and returns them. Modules that are no longer configured are kept as orphaned as long as their
+directory exists, so that they can be told apart from directories that were never nested modules.
+*/
+func UpdateState(state State, context models.NestContext, command string, now time.Time) (State, []StateChange) {
+	previous := make(map[string]StateModule)
+	for _, module := range state.Modules {
+		previous[module.Path] = module
+	}
+
+	modules := []StateModule{}
+	var changes []StateChange
+	configured := make(map[string]bool)
+
+	for _, submodule := range context.Config.Submodules {
+		modulePath := submodule.Path.UnixString()
+		configured[modulePath] = true
+
+		repository := context.ProjectRoot.Join(submodule.Path)
+		commit, err := utils.GetGitRefCommit(repository, "HEAD")
+		if err != nil {
+			// modules that do not exist (yet) are not applied
+			if module, ok := previous[modulePath]; ok && !module.Orphaned {
+				modules = append(modules, module)
+			}
+			continue
+		}
+
+		moduleUrl := ""
+		if submodule.Url != nil {
+			moduleUrl = urls.RedactCredentials(submodule.Url.String())
+		}
+
+		module := StateModule{Path: modulePath, Url: moduleUrl, Ref: submodule.Ref, Commit: commit, AppliedAt: now}
+		prev, ok := previous[modulePath]
+		switch {
+		case !ok || prev.Orphaned:
+			changes = append(changes, StateChange{Kind: StateChangeAdded, Path: modulePath, Url: moduleUrl, Commit: commit})
+		case prev.Commit != commit || prev.Url != moduleUrl:
+			changes = append(changes, StateChange{Kind: StateChangeUpdated, Path: modulePath, Url: moduleUrl, PreviousUrl: prev.Url, Commit: commit, PreviousCommit: prev.Commit})
+		default:
+			module.AppliedAt = prev.AppliedAt
+		}
+
+		modules = append(modules, module)
+	}
+
+	for _, prev := range state.Modules {
+		if configured[prev.Path] {
+			continue
+		}
+
+		if !prev.Orphaned {
+			changes = append(changes, StateChange{Kind: StateChangeRemoved, Path: prev.Path, Url: prev.Url, PreviousCommit: prev.Commit})
+		}
+
+		// removed modules are remembered until their directory is deleted
+		directory := context.ProjectRoot.Join(models.Path(prev.Path))
+		if directory.IsDir() {
+			prev.Orphaned = true
+			modules = append(modules, prev)
+		}
+	}
+
+	slices.SortFunc(modules, func(a, b StateModule) int {
+		if a.Path < b.Path {
+			return -1
+		} else if a.Path > b.Path {
+			return 1
+		}
+		return 0
+	})
+
+	state.Modules = modules
+	if len(changes) != 0 {
+		state.History = append(state.History, StateHistoryEntry{Time: now, Command: command, Changes: changes})
+		if len(state.History) > constants.StateHistoryLimit {
+			state.History = state.History[len(state.History)-constants.StateHistoryLimit:]
+		}
+	}
+
+	return state, changes
+}
+
+/*
+RecordState updates the state file of a models.NestContext with its current nested modules, see UpdateState.
+Nothing is recorded if the project is not a git repository.
+*/
+func RecordState(context models.NestContext, command string) ([]StateChange, error) {
+	stateFile := StateFileFromContext(context)
+	if stateFile.Empty() {
+		return nil, nil
+	}
+
+	state, err := ReadState(stateFile)
+	if err != nil {
+		return nil, err
+	}
+
+	state, changes := UpdateState(state, context, command, time.Now().UTC().Truncate(time.Second))
+	err = WriteState(stateFile, state)
+	if err != nil {
+		return nil, fmt.Errorf("could not write state file: %w", err)
+	}
+
+	return changes, nil
+}
+
+/*
+KeepStateModule marks the recorded module at p in the state file of a models.NestContext as kept on purpose,
+see StateModule.Kept. Modules that were never recorded are added as orphaned. The mark is dropped once the module
+is configured again. Nothing is recorded if the project is not a git repository.
+*/
+func KeepStateModule(context models.NestContext, p models.Path) error {
+	stateFile := StateFileFromContext(context)
+	if stateFile.Empty() {
+		return nil
+	}
+
+	state, err := ReadState(stateFile)
+	if err != nil {
+		return err
+	}
+
+	modulePath := p.UnixString()
+	index := slices.IndexFunc(state.Modules, func(module StateModule) bool { return module.Path == modulePath })
+	if index < 0 {
+		// modules that were never recorded are remembered as orphaned right away
+		state.Modules = append(state.Modules, StateModule{Path: modulePath, Orphaned: true, Kept: true})
+	} else {
+		state.Modules[index].Kept = true
+	}
+
+	err = WriteState(stateFile, state)
+	if err != nil {
+		return fmt.Errorf("could not write state file: %w", err)
+	}
+
+	return nil
+}
+
+/*
+shortCommit abbreviates a commit hash for output.
+*/
+func shortCommit(commit string) string {
+	if len(commit) > 7 {
+		return commit[:7]
+	}
+	return commit
+}
@@ -2,9 +2,11 @@ package internal
 
 import (
 	"errors"
+	"fmt"
 	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/models/urls"
 	"github.com/jeftadlvw/git-nest/utils"
-	"strings"
+	"slices"
 )
 
 const (
@@ -15,6 +17,7 @@ const (
 	SUBMODULE_EXISTS_ERR_NO_GIT
 	SUBMODULE_EXISTS_ERR_REMOTE
 	SUBMODULE_EXISTS_ERR_HEAD
+	SUBMODULE_EXISTS_ERR_REMOTE_MISSING
 )
 
 type SubmoduleExistsMapValue struct {
@@ -25,8 +28,9 @@ type SubmoduleExistsMapValue struct {
 
 /*
 SubmoduleExists returns whether and in what state a possible submodule exists.
+sshHttpsEquivalent defines whether a ssh remote url matches a http(s) url to the same host and path.
 */
-func SubmoduleExists(s models.Submodule, root models.Path) (int, string, error) {
+func SubmoduleExists(s models.Submodule, root models.Path, sshHttpsEquivalent bool) (int, string, error) {
 	submodulePath := root.Join(s.Path)
 
 	if !submodulePath.Exists() {
@@ -37,56 +41,59 @@ func SubmoduleExists(s models.Submodule, root models.Path) (int, string, error)
 		return SUBMODULE_EXISTS_ERR_FILE, "", nil
 	}
 
-	submoduleGitRemoteUrl, err := utils.GetGitRemoteUrl(submodulePath)
+	repositoryRemotes, err := utils.GetGitRemotes(submodulePath)
 	if err != nil {
 		return SUBMODULE_EXISTS_ERR_NO_GIT, "", nil
 	}
 
-	if submoduleGitRemoteUrl != s.Url.String() {
-		return SUBMODULE_EXISTS_ERR_REMOTE, submoduleGitRemoteUrl, nil
+	// compare the declared remote and all additional remotes
+	// embedded credentials are ignored and never returned
+	expectedRemotes := map[string]string{s.RemoteName(): s.Url.String()}
+	for name, remoteUrl := range s.Remotes {
+		expectedRemotes[name] = remoteUrl
+	}
+
+	for _, name := range append([]string{s.RemoteName()}, s.RemoteNames()...) {
+		if !slices.Contains(repositoryRemotes, name) {
+			return SUBMODULE_EXISTS_ERR_REMOTE_MISSING, name, nil
+		}
+
+		submoduleGitRemoteUrl, err := utils.GetGitRemoteUrlByName(submodulePath, name)
+		if err != nil {
+			return SUBMODULE_EXISTS_ERR_NO_GIT, "", nil
+		}
+
+		if !RemoteUrlMatches(submoduleGitRemoteUrl, expectedRemotes[name], sshHttpsEquivalent) {
+			return SUBMODULE_EXISTS_ERR_REMOTE, fmt.Sprintf("%s (%s)", urls.RedactCredentials(submoduleGitRemoteUrl), name), nil
+		}
 	}
 
 	if s.Ref == "" {
 		return SUBMODULE_EXISTS_UNDEFINED_REF, "", nil
 	}
 
-	var (
-		returnFlag    = SUBMODULE_EXISTS_OK
-		returnPayload string
-		returnErr     error
-	)
-
-	remoteRef, remoteRefAbbrev, err := utils.GetGitFetchHead(submodulePath)
+	// compare the HEAD according to the ref's kind
+	head, err := GetSubmoduleHead(submodulePath)
 	if err != nil {
-		returnFlag = SUBMODULE_EXISTS_ERR_HEAD
-		returnErr = err
-		return returnFlag, returnPayload, returnErr
+		return SUBMODULE_EXISTS_ERR_HEAD, "", err
 	}
 
-	if len(remoteRef) == 0 {
-		if !strings.HasPrefix(remoteRef, s.Ref) {
-			returnFlag = SUBMODULE_EXISTS_ERR_HEAD
-			returnPayload = remoteRef
-		}
-	} else {
-		if s.Ref != "" && remoteRefAbbrev != s.Ref {
-			returnFlag = SUBMODULE_EXISTS_ERR_HEAD
-			returnPayload = remoteRefAbbrev
-		}
+	if !SubmoduleHeadMatches(s, submodulePath, head) {
+		return SUBMODULE_EXISTS_ERR_HEAD, head.String(), nil
 	}
 
-	return returnFlag, returnPayload, returnErr
+	return SUBMODULE_EXISTS_OK, "", nil
 }
 
 /*
 SubmodulesExist takes multiple submodules and verifies their existence in bulk
 */
-func SubmodulesExist(submodules []models.Submodule, root models.Path) []SubmoduleExistsMapValue {
+func SubmodulesExist(submodules []models.Submodule, root models.Path, sshHttpsEquivalent bool) []SubmoduleExistsMapValue {
 
 	var existMapping []SubmoduleExistsMapValue
 
 	for _, submodule := range submodules {
-		status, payload, err := SubmoduleExists(submodule, root)
+		status, payload, err := SubmoduleExists(submodule, root, sshHttpsEquivalent)
 		existMapping = append(existMapping, SubmoduleExistsMapValue{
 			Status:  status,
 			Payload: payload,
@@ -100,19 +107,19 @@ func SubmodulesExist(submodules []models.Submodule, root models.Path) []Submodul
 /*
 SubmoduleValid takes multiple submodules and verifies their existence in bulk
 */
-func SubmoduleValid(submodule models.Submodule, root models.Path) bool {
-	status, _, _ := SubmoduleExists(submodule, root)
+func SubmoduleValid(submodule models.Submodule, root models.Path, sshHttpsEquivalent bool) bool {
+	status, _, _ := SubmoduleExists(submodule, root, sshHttpsEquivalent)
 	return SubmoduleStatusValid(status)
 }
 
 /*
 ValidSubmodulesCount how many passed submodules are valid.
 */
-func ValidSubmodulesCount(submodules []models.Submodule, root models.Path) int {
+func ValidSubmodulesCount(submodules []models.Submodule, root models.Path, sshHttpsEquivalent bool) int {
 	valid := 0
 
 	for _, submodule := range submodules {
-		if SubmoduleValid(submodule, root) {
+		if SubmoduleValid(submodule, root, sshHttpsEquivalent) {
 			valid++
 		}
 	}
@@ -143,6 +150,8 @@ func FmtSubmoduleExistOutput(status int, payload string, err error) (string, err
 		existStr = "error: git not installed"
 	case SUBMODULE_EXISTS_ERR_REMOTE:
 		existStr = "error: unequal remote urls: " + payload
+	case SUBMODULE_EXISTS_ERR_REMOTE_MISSING:
+		existStr = "error: missing remote " + payload
 	case SUBMODULE_EXISTS_ERR_HEAD:
 		if err != nil {
 			existStr = "error: unable to fetch HEAD: " + err.Error()
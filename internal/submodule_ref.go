@@ -0,0 +1,121 @@
+package internal
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/utils"
+)
+
+/*
+SubmoduleHead describes the HEAD of a nested module's repository.
+*/
+type SubmoduleHead struct {
+	/*
+		Commit contains the full commit hash HEAD points to.
+	*/
+	Commit string
+
+	/*
+		Branch contains the checked out branch. It is empty if HEAD is detached.
+	*/
+	Branch string
+
+	/*
+		Tag contains a tag that points exactly at a detached HEAD.
+	*/
+	Tag string
+}
+
+/*
+GetSubmoduleHead reads the HEAD of a nested module's repository.
+*/
+func GetSubmoduleHead(repository models.Path) (SubmoduleHead, error) {
+	commit, branch, err := utils.GetGitFetchHead(repository)
+	if err != nil {
+		return SubmoduleHead{}, err
+	}
+
+	head := SubmoduleHead{Commit: commit, Branch: branch}
+	if branch == "" {
+		head.Tag, _ = utils.GetGitExactTag(repository)
+	}
+
+	return head, nil
+}
+
+/*
+Ref returns the reference and its kind that best describe this SubmoduleHead:
+the checked out branch, the tag a detached HEAD is at, or the commit.
+*/
+func (h SubmoduleHead) Ref() (string, models.RefKind) {
+	if h.Branch != "" {
+		return h.Branch, models.RefKindBranch
+	}
+
+	if h.Tag != "" {
+		return h.Tag, models.RefKindTag
+	}
+
+	return h.Commit, models.RefKindCommit
+}
+
+/*
+String returns a string representation of this SubmoduleHead.
+*/
+func (h SubmoduleHead) String() string {
+	ref, kind := h.Ref()
+	if kind == models.RefKindCommit {
+		return ref
+	}
+
+	return fmt.Sprintf("%s %s", kind, ref)
+}
+
+/*
+SubmoduleRefKind returns the declared kind of a Submodule's ref or detects it from the nested module's repository.
+Refs that cannot be found locally are treated as commits if they look like a commit hash and as branches otherwise.
+*/
+func SubmoduleRefKind(s models.Submodule, repository models.Path) models.RefKind {
+	if s.RefKind != models.RefKindAuto {
+		return s.RefKind
+	}
+
+	kind, err := utils.GetGitRefKind(repository, s.Ref, s.RemoteName())
+	if err == nil {
+		return kind
+	}
+
+	if models.IsCommitHash(s.Ref) {
+		return models.RefKindCommit
+	}
+
+	return models.RefKindBranch
+}
+
+/*
+SubmoduleHeadMatches returns whether a nested module's HEAD matches the Submodule's ref according to its kind.
+Branches must be checked out, tags must be checked out detached at the tag's commit, and commits
+match if the HEAD's commit hash starts with the ref.
+*/
+func SubmoduleHeadMatches(s models.Submodule, repository models.Path, head SubmoduleHead) bool {
+	if s.Ref == "" {
+		return true
+	}
+
+	switch SubmoduleRefKind(s, repository) {
+	case models.RefKindBranch:
+		return head.Branch == s.Ref
+	case models.RefKindTag:
+		if head.Branch != "" {
+			return false
+		}
+		if head.Tag == s.Ref {
+			return true
+		}
+
+		tagCommit, err := utils.GetGitRefCommit(repository, "refs/tags/"+s.Ref)
+		return err == nil && tagCommit == head.Commit
+	default:
+		return models.CommitHashMatches(head.Commit, s.Ref)
+	}
+}
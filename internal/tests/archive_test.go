@@ -0,0 +1,34 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/internal"
+	"testing"
+)
+
+func TestArchiveFormatFromPath(t *testing.T) {
+	cases := []struct {
+		path     string
+		expected string
+	}{
+		{"out.tar.gz", internal.ArchiveFormatTarGz},
+		{"OUT.TGZ", internal.ArchiveFormatTarGz},
+		{"dir/out.tar", internal.ArchiveFormatTar},
+		{"out.zip", internal.ArchiveFormatZip},
+		{"out", internal.ArchiveFormatDirectory},
+		{"out.gz", internal.ArchiveFormatDirectory},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestArchiveFormatFromPath-%d", index+1), func(t *testing.T) {
+			format := internal.ArchiveFormatFromPath(tc.path)
+			if format != tc.expected {
+				t.Fatalf("unexpected format %s, expected %s", format, tc.expected)
+			}
+
+			if err := internal.ValidateArchiveFormat(format); err != nil {
+				t.Fatalf("inferred format is invalid: %s", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/internal"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/test_env"
+	"github.com/jeftadlvw/git-nest/utils"
+	"os"
+	"slices"
+	"testing"
+)
+
+func TestFindStagedNestedPaths(t *testing.T) {
+	cases := []struct {
+		projectSubDir string
+		stagedFiles   []string
+		expected      []string
+	}{
+		{"", nil, nil},
+		{"", []string{"foo"}, nil},
+		{"", []string{"module/foo"}, []string{"module/foo"}},
+		{"", []string{"foo", "module/foo", "module/bar/baz", "modules"}, []string{"module/foo", "module/bar/baz"}},
+		{"", []string{"nested/module/foo", "nested/foo"}, []string{"nested/module/foo"}},
+		{"sub", []string{"module/foo", "sub/module/foo"}, []string{"sub/module/foo"}},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestFindStagedNestedPaths-%d", index+1), func(t *testing.T) {
+			t.Parallel()
+
+			repoDir := models.Path(t.TempDir())
+			err := test_env.CreateLocalRepository(repoDir)
+			if err != nil {
+				t.Fatalf("error creating local repository: %s", err)
+			}
+
+			for _, stagedFile := range tc.stagedFiles {
+				stagedPath := repoDir.SJoin(stagedFile)
+				stagedDir := stagedPath.Parent()
+				err = os.MkdirAll(stagedDir.String(), os.ModePerm)
+				if err != nil {
+					t.Fatalf("error creating directory: %s", err)
+				}
+
+				err = utils.WriteStrToFile(stagedPath, "")
+				if err != nil {
+					t.Fatalf("error writing test file: %s", err)
+				}
+
+				out, err := utils.RunCommandCombinedOutput(repoDir, "git", "add", "-f", stagedFile)
+				if err != nil {
+					t.Fatalf("error staging file: %s; %s", err, out)
+				}
+			}
+
+			projectRoot := repoDir.SJoin(tc.projectSubDir)
+			err = os.MkdirAll(projectRoot.String(), os.ModePerm)
+			if err != nil {
+				t.Fatalf("error creating project directory: %s", err)
+			}
+
+			context, err := internal.CreateContext(projectRoot)
+			if err != nil {
+				t.Fatalf("error creating context: %s", err)
+			}
+			context.Config.Submodules = []models.Submodule{{Path: "module"}, {Path: "nested/module"}}
+
+			stagedNestedPaths, err := internal.FindStagedNestedPaths(context, "")
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			var paths []string
+			for _, stagedNestedPath := range stagedNestedPaths {
+				paths = append(paths, stagedNestedPath.Path)
+			}
+
+			slices.Sort(paths)
+			expected := slices.Clone(tc.expected)
+			slices.Sort(expected)
+			if !slices.Equal(paths, expected) {
+				t.Fatalf("unexpected staged nested paths: %v, expected %v", paths, expected)
+			}
+		})
+	}
+}
+
+func TestSubmoduleIgnoreConfigDiff(t *testing.T) {
+	cases := []struct {
+		excludeContent      string
+		submodules          []models.Submodule
+		expectedMissing     []string
+		expectedSuperfluous []string
+	}{
+		{"", nil, nil, nil},
+		{"", []models.Submodule{{Path: "foo"}}, []string{"foo"}, nil},
+		{"foo\n", []models.Submodule{{Path: "foo"}}, []string{"foo"}, nil},
+		{gitExcludePrefix + "\n" + gitExcludeInfo + "\nfoo\n" + gitExcludeSuffix, []models.Submodule{{Path: "foo"}}, nil, nil},
+		{"bar\n" + gitExcludePrefix + "\n" + gitExcludeInfo + "\nfoo\nbaz\n" + gitExcludeSuffix + "\nqux", []models.Submodule{{Path: "foo"}, {Path: "bar"}}, []string{"bar"}, []string{"baz"}},
+		{gitExcludePrefix + "\n" + gitExcludeInfo + "\n" + gitExcludeSuffix, nil, nil, nil},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestSubmoduleIgnoreConfigDiff-%d", index+1), func(t *testing.T) {
+			t.Parallel()
+
+			repoDir := models.Path(t.TempDir())
+			err := test_env.CreateLocalRepository(repoDir)
+			if err != nil {
+				t.Fatalf("error creating local repository: %s", err)
+			}
+
+			err = utils.WriteStrToFile(repoDir.SJoin(gitExcludeFile), tc.excludeContent)
+			if err != nil {
+				t.Fatalf("error writing exclude file: %s", err)
+			}
+
+			context, err := internal.CreateContext(repoDir)
+			if err != nil {
+				t.Fatalf("error creating context: %s", err)
+			}
+			context.Config.Submodules = tc.submodules
+
+			missing, superfluous, err := internal.SubmoduleIgnoreConfigDiff(context)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !slices.Equal(missing, tc.expectedMissing) {
+				t.Fatalf("unexpected missing entries: %v, expected %v", missing, tc.expectedMissing)
+			}
+			if !slices.Equal(superfluous, tc.expectedSuperfluous) {
+				t.Fatalf("unexpected superfluous entries: %v, expected %v", superfluous, tc.expectedSuperfluous)
+			}
+		})
+	}
+}
+
+func TestSubmoduleIgnoreConfigDiffGitignore(t *testing.T) {
+	cases := []struct {
+		config              models.Config
+		gitignoreFiles      map[string]string
+		expectedMissing     []string
+		expectedSuperfluous []string
+	}{
+		{models.Config{IgnoreMode: models.IgnoreModeGitignore}, nil, []string{"foo", "bar/baz"}, nil},
+		{models.Config{IgnoreMode: models.IgnoreModeGitignore}, map[string]string{".gitignore": "/foo\n/bar/baz"}, nil, nil},
+		{models.Config{IgnoreMode: models.IgnoreModeGitignore}, map[string]string{".gitignore": "/foo", "bar/.gitignore": "/baz"}, nil, nil},
+		{models.Config{IgnoreMode: models.IgnoreModeGitignore}, map[string]string{".gitignore": "/foo\n/qux"}, []string{"bar/baz"}, []string{"qux"}},
+		{models.Config{IgnoreMode: models.IgnoreModeBoth}, map[string]string{".gitignore": "/foo\n/bar/baz"}, []string{"foo", "bar/baz"}, nil},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestSubmoduleIgnoreConfigDiffGitignore-%d", index+1), func(t *testing.T) {
+			t.Parallel()
+
+			repoDir := models.Path(t.TempDir())
+			err := test_env.CreateLocalRepository(repoDir)
+			if err != nil {
+				t.Fatalf("error creating local repository: %s", err)
+			}
+
+			for gitignoreFile, entries := range tc.gitignoreFiles {
+				gitignorePath := repoDir.SJoin(gitignoreFile)
+				gitignoreDir := gitignorePath.Parent()
+				err = os.MkdirAll(gitignoreDir.String(), os.ModePerm)
+				if err != nil {
+					t.Fatalf("error creating directory: %s", err)
+				}
+
+				err = utils.WriteStrToFile(gitignorePath, gitExcludePrefix+"\n"+entries+"\n"+gitExcludeSuffix+"\n")
+				if err != nil {
+					t.Fatalf("error writing gitignore file: %s", err)
+				}
+			}
+
+			context, err := internal.CreateContext(repoDir)
+			if err != nil {
+				t.Fatalf("error creating context: %s", err)
+			}
+			context.Config.Config = tc.config
+			context.Config.Submodules = []models.Submodule{{Path: "foo"}, {Path: "bar/baz"}}
+
+			missing, superfluous, err := internal.SubmoduleIgnoreConfigDiff(context)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			slices.Sort(missing)
+			slices.Sort(superfluous)
+			expectedMissing := slices.Clone(tc.expectedMissing)
+			slices.Sort(expectedMissing)
+
+			if !slices.Equal(missing, expectedMissing) {
+				t.Fatalf("unexpected missing entries: %v, expected %v", missing, expectedMissing)
+			}
+			if !slices.Equal(superfluous, tc.expectedSuperfluous) {
+				t.Fatalf("unexpected superfluous entries: %v, expected %v", superfluous, tc.expectedSuperfluous)
+			}
+		})
+	}
+}
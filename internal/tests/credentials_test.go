@@ -0,0 +1,83 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jeftadlvw/git-nest/internal"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/utils"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestCredentialGitConfigParameters(t *testing.T) {
+	// remote that requires basic authentication with a fixed token
+	const (
+		username = "ci"
+		token    = "secret-token"
+	)
+
+	var authorizedRequests atomic.Int32
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, ok := r.BasicAuth()
+		if !ok || user != username || password != token {
+			w.Header().Set("WWW-Authenticate", `Basic realm="git-nest"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		authorizedRequests.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "https://")
+	remoteUrl := server.URL + "/repository.git"
+
+	t.Setenv("GIT_TERMINAL_PROMPT", "0")
+	t.Setenv("GIT_SSL_NO_VERIFY", "1")
+	t.Setenv("GIT_NEST_TEST_TOKEN", "")
+	defer utils.SetGitConfigParameters()
+
+	cases := []struct {
+		credentials []models.Credential
+		token       string
+		authErr     bool
+		authorized  bool
+	}{
+		{nil, "", true, false},
+		{[]models.Credential{{Host: host, TokenEnv: "GIT_NEST_TEST_TOKEN", Username: username}}, "", true, false},
+		{[]models.Credential{{Host: host, TokenEnv: "GIT_NEST_TEST_TOKEN", Username: username}}, "wrong", true, false},
+		{[]models.Credential{{Host: host, TokenEnv: "GIT_NEST_TEST_TOKEN", Username: username}}, token, false, true},
+		{[]models.Credential{{Host: "example.com", TokenEnv: "GIT_NEST_TEST_TOKEN", Username: username}}, token, true, false},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestCredentialGitConfigParameters-%d", index+1), func(t *testing.T) {
+			t.Setenv("GIT_NEST_TEST_TOKEN", tc.token)
+			authorizedRequests.Store(0)
+
+			parameters := internal.CredentialGitConfigParameters(tc.credentials)
+			for _, parameter := range parameters {
+				if tc.token != "" && strings.Contains(parameter, tc.token) {
+					t.Fatalf("token leaked into git configuration parameter %s", parameter)
+				}
+			}
+			utils.SetGitConfigParameters(parameters...)
+
+			_, err := utils.GetGitRemoteRefs(remoteUrl)
+			if err == nil {
+				t.Fatalf("no error, but expected one")
+			}
+			if tc.authErr != errors.Is(err, utils.ErrAuthRequired) {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if tc.authorized != (authorizedRequests.Load() != 0) {
+				t.Fatalf("unexpected authorization: %d authorized requests", authorizedRequests.Load())
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/internal"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/test_env"
+	"github.com/jeftadlvw/git-nest/utils"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestFmtSubmodulesGitignoreFiles(t *testing.T) {
+	submodules := []models.Submodule{{Path: "foo"}, {Path: "bar/baz"}, {Path: "bar/qux"}, {Path: "a/b/c"}}
+
+	cases := []struct {
+		perDirectory bool
+		expected     map[string]string
+	}{
+		{false, map[string]string{".gitignore": "/foo\n/bar/baz\n/bar/qux\n/a/b/c"}},
+		{true, map[string]string{".gitignore": "/foo", "bar/.gitignore": "/baz\n/qux", "a/b/.gitignore": "/c"}},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestFmtSubmodulesGitignoreFiles-%d", index+1), func(t *testing.T) {
+			formatted := internal.FmtSubmodulesGitignoreFiles(submodules, tc.perDirectory)
+			if !maps.Equal(formatted, tc.expected) {
+				t.Fatalf("unexpected gitignore entries: %v, expected %v", formatted, tc.expected)
+			}
+		})
+	}
+}
+
+func TestRemoveSubmoduleIgnoreConfig(t *testing.T) {
+	block := gitExcludePrefix + "\n" + gitExcludeInfo + "\nfoo\n" + gitExcludeSuffix
+
+	cases := []struct {
+		content  string
+		expected string
+		deleted  bool
+	}{
+		{"", "", true},
+		{"foo\n", "foo\n", false},
+		{block + "\n", "", true},
+		{"foo\n\n" + block + "\n", "foo\n", false},
+		{"foo\n\n" + block + "\nbar\n", "foo\n\nbar\n", false},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestRemoveSubmoduleIgnoreConfig-%d", index+1), func(t *testing.T) {
+			t.Parallel()
+
+			tempDir := models.Path(t.TempDir())
+			ignoreFile := tempDir.SJoin(".gitignore")
+			err := utils.WriteStrToFile(ignoreFile, tc.content)
+			if err != nil {
+				t.Fatalf("error writing ignore file: %s", err)
+			}
+
+			err = internal.RemoveSubmoduleIgnoreConfig(ignoreFile)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if tc.deleted {
+				// files without a block are never deleted, even if empty
+				if tc.content != "" && ignoreFile.Exists() {
+					t.Fatalf("empty ignore file was not deleted")
+				}
+				return
+			}
+
+			content, err := utils.ReadFileToStr(ignoreFile)
+			if err != nil {
+				t.Fatalf("error reading ignore file: %s", err)
+			}
+			if content != tc.expected {
+				t.Fatalf("unexpected content:\n>%s<\nexpected:\n>%s<", content, tc.expected)
+			}
+		})
+	}
+}
+
+func TestWriteSubmoduleGitignoreConfig(t *testing.T) {
+	t.Parallel()
+
+	repoDir := models.Path(t.TempDir())
+	err := test_env.CreateLocalRepository(repoDir)
+	if err != nil {
+		t.Fatalf("error creating local repository: %s", err)
+	}
+
+	err = utils.WriteStrToFile(repoDir.SJoin(".gitignore"), "node_modules\n")
+	if err != nil {
+		t.Fatalf("error writing gitignore file: %s", err)
+	}
+
+	submodules := []models.Submodule{{Path: "foo"}, {Path: "bar/baz"}}
+
+	// steps are run sequentially on the same repository
+	steps := []struct {
+		submodules   []models.Submodule
+		perDirectory bool
+		remove       bool
+		expected     map[string][]string
+	}{
+		{submodules, false, false, map[string][]string{".gitignore": {"/foo", "/bar/baz"}}},
+		{submodules, true, false, map[string][]string{".gitignore": {"/foo"}, "bar/.gitignore": {"/baz"}}},
+		{submodules[:1], true, false, map[string][]string{".gitignore": {"/foo"}}},
+		{submodules, true, true, map[string][]string{}},
+	}
+
+	for index, step := range steps {
+		if step.remove {
+			err = internal.RemoveSubmoduleGitignoreConfig(repoDir)
+		} else {
+			err = internal.WriteSubmoduleGitignoreConfig(repoDir, step.submodules, step.perDirectory)
+		}
+		if err != nil {
+			t.Fatalf("step %d: unexpected error: %s", index+1, err)
+		}
+
+		managedFiles, err := internal.FindManagedGitignoreFiles(repoDir)
+		if err != nil {
+			t.Fatalf("step %d: error finding managed gitignore files: %s", index+1, err)
+		}
+
+		slices.Sort(managedFiles)
+		var expectedFiles []string
+		for gitignoreFile := range step.expected {
+			expectedFiles = append(expectedFiles, gitignoreFile)
+		}
+		slices.Sort(expectedFiles)
+		if !slices.Equal(managedFiles, expectedFiles) {
+			t.Fatalf("step %d: unexpected managed files: %v, expected %v", index+1, managedFiles, expectedFiles)
+		}
+
+		for gitignoreFile, expectedEntries := range step.expected {
+			entries, found, err := internal.ReadSubmoduleIgnoreConfig(repoDir.SJoin(gitignoreFile))
+			if err != nil || !found {
+				t.Fatalf("step %d: error reading block from %s: %v", index+1, gitignoreFile, err)
+			}
+			if !slices.Equal(entries, expectedEntries) {
+				t.Fatalf("step %d: unexpected entries in %s: %v, expected %v", index+1, gitignoreFile, entries, expectedEntries)
+			}
+		}
+
+		// user content is never touched
+		content, err := utils.ReadFileToStr(repoDir.SJoin(".gitignore"))
+		if err != nil || !strings.HasPrefix(content, "node_modules\n") {
+			t.Fatalf("step %d: user content of .gitignore was changed:\n%s", index+1, content)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/internal"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/test_env"
+	"github.com/jeftadlvw/git-nest/utils"
+	"testing"
+)
+
+func TestVerifySubmoduleIntegrity(t *testing.T) {
+	cases := []struct {
+		modify           [][]string
+		files            map[string]string
+		record           bool
+		treeModified     bool
+		worktreeModified bool
+	}{
+		{nil, nil, false, false, false},
+		{nil, nil, true, false, false},
+		{nil, map[string]string{"tracked.txt": "changed"}, true, false, true},
+		{nil, map[string]string{"untracked.txt": ""}, true, false, true},
+		{nil, map[string]string{"build.log": ""}, true, false, false},
+		{[][]string{{"rm", "--quiet", "tracked.txt"}}, nil, true, false, true},
+		{[][]string{{"commit", "--allow-empty", "-m", "empty"}}, nil, true, false, false},
+		{[][]string{{"commit", "--quiet", "-a", "-m", "changed"}}, map[string]string{"tracked.txt": "changed"}, true, true, true},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestVerifySubmoduleIntegrity-%d", index+1), func(t *testing.T) {
+			t.Parallel()
+
+			root := models.Path(t.TempDir())
+			repository := root.Join("module")
+			err := test_env.CreateLocalRepository(repository)
+			if err != nil {
+				t.Fatalf("error creating module repository: %s", err)
+			}
+
+			for name, content := range map[string]string{"tracked.txt": "tracked", ".gitignore": "*.log\n"} {
+				err = utils.WriteStrToFile(repository.SJoin(name), content)
+				if err != nil {
+					t.Fatalf("error writing file: %s", err)
+				}
+			}
+
+			for _, args := range [][]string{{"add", "."}, {"commit", "--quiet", "-m", "first"}} {
+				out, err := utils.RunCommandCombinedOutput(repository, "git", args...)
+				if err != nil {
+					t.Fatalf("error running git %s: %s; %s", args[0], err, out)
+				}
+			}
+
+			submodule := models.Submodule{Path: "module"}
+			if tc.record {
+				submodule.TreeHash, submodule.WorktreeHash, err = internal.SubmoduleIntegrityHashes(repository)
+				if err != nil {
+					t.Fatalf("error computing integrity hashes: %s", err)
+				}
+			}
+
+			for name, content := range tc.files {
+				err = utils.WriteStrToFile(repository.SJoin(name), content)
+				if err != nil {
+					t.Fatalf("error writing file: %s", err)
+				}
+			}
+
+			for _, args := range tc.modify {
+				out, err := utils.RunCommandCombinedOutput(repository, "git", args...)
+				if err != nil {
+					t.Fatalf("error running git %s: %s; %s", args[0], err, out)
+				}
+			}
+
+			report := internal.VerifySubmoduleIntegrity(submodule, root)
+			if report.Error != nil {
+				t.Fatalf("unexpected error: %s", report.Error)
+			}
+			if report.Recorded != tc.record || report.TreeModified != tc.treeModified || report.WorktreeModified != tc.worktreeModified {
+				t.Fatalf("unexpected report: %+v (%s)", report, report)
+			}
+		})
+	}
+}
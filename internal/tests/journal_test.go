@@ -0,0 +1,104 @@
+package tests
+
+import (
+	"github.com/jeftadlvw/git-nest/internal"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/utils"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestJournalRollback(t *testing.T) {
+	root := t.TempDir()
+	journalFile := models.Path(filepath.Join(root, ".git", "git-nest", "journal.json"))
+	configFile := models.Path(filepath.Join(root, "nestmodules.toml"))
+	missingFile := models.Path(filepath.Join(root, ".gitignore"))
+	existingDir := filepath.Join(root, "lib")
+	createdDir := filepath.Join(existingDir, "module")
+
+	err := os.MkdirAll(existingDir, os.ModePerm)
+	if err != nil {
+		t.Fatalf("error creating directory: %s", err)
+	}
+
+	err = utils.WriteStrToFileAtomic(configFile, "before")
+	if err != nil {
+		t.Fatalf("error writing config file: %s", err)
+	}
+
+	journal, err := internal.StartJournal(journalFile, []string{"sync"}, []models.Path{configFile, missingFile, ""})
+	if err != nil {
+		t.Fatalf("unexpected error starting journal: %s", err)
+	}
+
+	_, err = internal.StartJournal(journalFile, []string{"sync"}, nil)
+	if err == nil {
+		t.Fatalf("expected error starting a journal while another one exists")
+	}
+
+	// first step finishes, the second one is interrupted
+	for index, createdPaths := range [][]string{{existingDir, createdDir}, {filepath.Join(root, "archive.zip")}} {
+		err = journal.StartStep("step", createdPaths)
+		if err != nil {
+			t.Fatalf("unexpected error starting step: %s", err)
+		}
+
+		for _, createdPath := range createdPaths {
+			err = os.MkdirAll(createdPath, os.ModePerm)
+			if err != nil {
+				t.Fatalf("error creating %s: %s", createdPath, err)
+			}
+		}
+
+		if index == 0 {
+			err = journal.FinishStep()
+			if err != nil {
+				t.Fatalf("unexpected error finishing step: %s", err)
+			}
+		}
+	}
+
+	for _, p := range []models.Path{configFile, missingFile} {
+		err = utils.WriteStrToFileAtomic(p, "after")
+		if err != nil {
+			t.Fatalf("error writing %s: %s", p, err)
+		}
+	}
+
+	// an interrupted run is detected by the next invocation
+	interrupted, err := internal.ReadJournal(journalFile)
+	if err != nil || interrupted == nil {
+		t.Fatalf("expected interrupted journal, got %v", err)
+	}
+
+	if step := interrupted.InterruptedStep(); step == nil || len(interrupted.Steps) != 2 || len(interrupted.Steps[0].CreatedPaths) != 1 {
+		t.Fatalf("unexpected journal steps: %+v", interrupted.Steps)
+	}
+
+	err = interrupted.Rollback()
+	if err != nil {
+		t.Fatalf("unexpected error rolling back: %s", err)
+	}
+
+	if _, err = os.Stat(createdDir); !os.IsNotExist(err) {
+		t.Fatalf("created directory has not been deleted")
+	}
+	if _, err = os.Stat(existingDir); err != nil {
+		t.Fatalf("pre-existing directory has been deleted")
+	}
+	if _, err = os.Stat(filepath.Join(root, "archive.zip")); !os.IsNotExist(err) {
+		t.Fatalf("path created by interrupted step has not been deleted")
+	}
+	if content, _ := utils.ReadFileToStr(configFile); content != "before" {
+		t.Fatalf("config file has not been restored: %q", content)
+	}
+	if missingFile.Exists() {
+		t.Fatalf("file that did not exist before has not been deleted")
+	}
+
+	journal, err = internal.ReadJournal(journalFile)
+	if err != nil || journal != nil {
+		t.Fatalf("journal has not been removed after rollback")
+	}
+}
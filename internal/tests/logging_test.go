@@ -0,0 +1,128 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/jeftadlvw/git-nest/internal"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestParseLogFormat(t *testing.T) {
+	cases := []struct {
+		format   string
+		expected internal.LogFormat
+		err      bool
+	}{
+		{"", internal.LogFormatText, false},
+		{"text", internal.LogFormatText, false},
+		{"JSON", internal.LogFormatJson, false},
+		{" json ", internal.LogFormatJson, false},
+		{"yaml", "", true},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestParseLogFormat-%d", index+1), func(t *testing.T) {
+			t.Parallel()
+
+			format, err := internal.ParseLogFormat(tc.format)
+			if tc.err != (err != nil) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if format != tc.expected {
+				t.Fatalf("unexpected format: >%s<, expected >%s<", format, tc.expected)
+			}
+		})
+	}
+}
+
+func TestLogLevel(t *testing.T) {
+	cases := []struct {
+		verbosity int
+		quiet     bool
+		expected  slog.Level
+	}{
+		{0, false, slog.LevelWarn},
+		{1, false, slog.LevelInfo},
+		{2, false, slog.LevelDebug},
+		{3, false, slog.LevelDebug},
+		{0, true, slog.LevelError},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestLogLevel-%d", index+1), func(t *testing.T) {
+			t.Parallel()
+
+			if level := internal.LogLevel(tc.verbosity, tc.quiet); level != tc.expected {
+				t.Fatalf("unexpected level: %s, expected %s", level, tc.expected)
+			}
+		})
+	}
+}
+
+func TestNewLogger(t *testing.T) {
+	cases := []struct {
+		level      slog.Level
+		format     internal.LogFormat
+		trace      bool
+		outLines   int
+		traceLines int
+	}{
+		{slog.LevelWarn, internal.LogFormatText, false, 1, 0},
+		{slog.LevelDebug, internal.LogFormatText, false, 3, 0},
+		{slog.LevelInfo, internal.LogFormatJson, true, 2, 3},
+		{slog.LevelError, internal.LogFormatJson, true, 0, 3},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestNewLogger-%d", index+1), func(t *testing.T) {
+			t.Parallel()
+
+			var out, trace bytes.Buffer
+			var traceWriter io.Writer
+			if tc.trace {
+				traceWriter = &trace
+			}
+
+			logger := internal.NewLogger(&out, tc.level, tc.format, traceWriter)
+			logger = logger.With(slog.String("module", "lib"))
+			logger.Debug("debug")
+			logger.Info("info")
+			logger.Warn("warn")
+
+			outLines := strings.Split(strings.TrimSpace(out.String()), "\n")
+			if out.Len() == 0 {
+				outLines = nil
+			}
+			if len(outLines) != tc.outLines {
+				t.Fatalf("unexpected number of records: %d, expected %d:\n%s", len(outLines), tc.outLines, out.String())
+			}
+
+			for _, line := range outLines {
+				if !strings.Contains(line, "lib") {
+					t.Fatalf("record does not contain attribute: %s", line)
+				}
+				if tc.format == internal.LogFormatJson && !json.Valid([]byte(line)) {
+					t.Fatalf("record is not valid json: %s", line)
+				}
+			}
+
+			traceLines := strings.Split(strings.TrimSpace(trace.String()), "\n")
+			if trace.Len() == 0 {
+				traceLines = nil
+			}
+			if len(traceLines) != tc.traceLines {
+				t.Fatalf("unexpected number of trace records: %d, expected %d:\n%s", len(traceLines), tc.traceLines, trace.String())
+			}
+
+			for _, line := range traceLines {
+				if !json.Valid([]byte(line)) {
+					t.Fatalf("trace record is not valid json: %s", line)
+				}
+			}
+		})
+	}
+}
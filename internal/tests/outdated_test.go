@@ -0,0 +1,29 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/internal"
+	"testing"
+)
+
+func TestSubmoduleOutdatedReportStatus(t *testing.T) {
+	cases := []struct {
+		report   internal.SubmoduleOutdatedReport
+		expected string
+	}{
+		{internal.SubmoduleOutdatedReport{CurrentSha: "abc"}, "up to date"},
+		{internal.SubmoduleOutdatedReport{CurrentSha: "abc", Outdated: true}, "outdated"},
+		{internal.SubmoduleOutdatedReport{}, "does not exist"},
+		{internal.SubmoduleOutdatedReport{Error: "unreachable"}, "error: unreachable"},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestSubmoduleOutdatedReportStatus-%d", index+1), func(t *testing.T) {
+			t.Parallel()
+
+			if status := tc.report.Status(); status != tc.expected {
+				t.Fatalf("unexpected status: >%s<, expected >%s<", status, tc.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/internal"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/models/urls"
+	"github.com/jeftadlvw/git-nest/test_env"
+	"github.com/jeftadlvw/git-nest/utils"
+	"reflect"
+	"testing"
+)
+
+func TestReadPolicyFile(t *testing.T) {
+	cases := []struct {
+		content  string
+		expected models.Policy
+		err      bool
+	}{
+		{"", models.Policy{}, false},
+		{`[policy]
+allowed_hosts = ["github.com"]
+required_ref_kinds = ["tag", "commit"]
+forbidden_paths = ["vendor"]`, models.Policy{AllowedHosts: []string{"github.com"}, RequiredRefKinds: []models.RefKind{models.RefKindTag, models.RefKindCommit}, ForbiddenPaths: []string{"vendor"}}, false},
+		{`[policy]
+allowed_host = ["github.com"]`, models.Policy{}, true},
+		{`[policy]
+required_ref_kinds = ["revision"]`, models.Policy{}, true},
+		{`[policy`, models.Policy{}, true},
+		{"-", models.Policy{}, true},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestReadPolicyFile-%d", index+1), func(t *testing.T) {
+			tempDir := models.Path(t.TempDir())
+			policyFile := tempDir.Join("policy.toml")
+			if tc.content != "-" {
+				err := utils.WriteStrToFile(policyFile, tc.content)
+				if err != nil {
+					t.Fatalf("error writing policy file: %s", err)
+				}
+			}
+
+			policy, err := internal.ReadPolicyFile(policyFile)
+			if tc.err && err == nil {
+				t.Fatalf("no error, but expected one")
+			}
+			if !tc.err && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !tc.err && !reflect.DeepEqual(policy, tc.expected) {
+				t.Fatalf("unexpected policy %+v, expected %+v", policy, tc.expected)
+			}
+		})
+	}
+}
+
+func TestSubmodulePolicyViolationsDetectsRefKind(t *testing.T) {
+	root := models.Path(t.TempDir())
+	repository := root.Join("module")
+	err := test_env.CreateLocalRepository(repository, "first")
+	if err != nil {
+		t.Fatalf("error creating module repository: %s", err)
+	}
+
+	out, err := utils.RunCommandCombinedOutput(repository, "git", "tag", "v1.0.0")
+	if err != nil {
+		t.Fatalf("error creating tag: %s; %s", err, out)
+	}
+
+	configuredUrl, err := urls.HttpUrlFromString("https://example.com/user/repository")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	policy := models.Policy{RequiredRefKinds: []models.RefKind{models.RefKindTag}}
+
+	cases := []struct {
+		ref        string
+		violations int
+	}{
+		{"v1.0.0", 0},
+		{test_env.RepoBranchDefault, 1},
+		{"", 1},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestSubmodulePolicyViolationsDetectsRefKind-%d", index+1), func(t *testing.T) {
+			submodule := models.Submodule{Path: "module", Url: &configuredUrl, Ref: tc.ref}
+			violations := internal.SubmodulePolicyViolations(policy, submodule, root)
+			if len(violations) != tc.violations {
+				t.Fatalf("expected %d violations, got %v", tc.violations, violations)
+			}
+		})
+	}
+
+	violations := internal.PolicyViolations(policy, []models.Submodule{
+		{Path: "module", Url: &configuredUrl, Ref: test_env.RepoBranchDefault},
+		{Path: "other", Url: &configuredUrl, Ref: "abc1234", RefKind: models.RefKindCommit},
+	}, root)
+	err = internal.PolicyError(violations)
+	if err == nil || len(violations) != 2 {
+		t.Fatalf("expected policy error for both nested modules, got %v", violations)
+	}
+	if internal.PolicyError(nil) != nil {
+		t.Fatalf("unexpected policy error without violations")
+	}
+}
@@ -0,0 +1,130 @@
+package tests
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/jeftadlvw/git-nest/internal"
+	"github.com/jeftadlvw/git-nest/models"
+	"strings"
+	"testing"
+)
+
+func TestParseProgressMode(t *testing.T) {
+	cases := []struct {
+		mode     string
+		expected internal.ProgressMode
+		err      bool
+	}{
+		{"", internal.ProgressModeAuto, false},
+		{"auto", internal.ProgressModeAuto, false},
+		{"TTY", internal.ProgressModeTty, false},
+		{" plain ", internal.ProgressModePlain, false},
+		{"none", internal.ProgressModeNone, false},
+		{"bars", "", true},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestParseProgressMode-%d", index+1), func(t *testing.T) {
+			t.Parallel()
+
+			mode, err := internal.ParseProgressMode(tc.mode)
+			if tc.err != (err != nil) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if mode != tc.expected {
+				t.Fatalf("unexpected mode: >%s<, expected >%s<", mode, tc.expected)
+			}
+		})
+	}
+}
+
+func TestPlainProgressReporter(t *testing.T) {
+	var out bytes.Buffer
+	reporter := internal.NewPlainProgressReporter(&out)
+
+	task := reporter.Start("lib")
+	for current := 1; current <= 10; current++ {
+		task.Update(models.Progress{Phase: "Receiving objects", Percent: current * 10, Current: current, Total: 10})
+	}
+	task.Update(models.Progress{Phase: "Resolving deltas", Percent: 100, Current: 4, Total: 4})
+	reporter.Message("warning: message")
+	task.Done(errors.New("failed"))
+
+	expected := []string{
+		"lib: busy",
+		"lib: Receiving objects 10% (1/10)",
+		"lib: Receiving objects 30% (3/10)",
+		"lib: Receiving objects 50% (5/10)",
+		"lib: Receiving objects 80% (8/10)",
+		"lib: Receiving objects 100% (10/10)",
+		"lib: Resolving deltas 100% (4/4)",
+		"warning: message",
+		"lib: failed.",
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if strings.Join(lines, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("unexpected output:\n%s\nexpected:\n%s", out.String(), strings.Join(expected, "\n"))
+	}
+}
+
+func TestTtyProgressReporter(t *testing.T) {
+	var out bytes.Buffer
+	reporter := internal.NewTtyProgressReporter(&out, func() int { return 40 })
+
+	first := reporter.Start("first")
+	second := reporter.Start("second/with/a/very/long/path/that/does/not/fit")
+	first.Update(models.Progress{Phase: "Receiving objects", Percent: 50, Current: 5, Total: 10})
+	second.Update(models.Progress{Phase: "Receiving objects", Percent: 10, Current: 1, Total: 10})
+	first.Done(nil)
+	reporter.Message("warning: message")
+	second.Done(nil)
+
+	// every redraw moves up to the first line of running operations
+	redraws := strings.Split(out.String(), "\r\x1b[J")[1:]
+
+	cases := []struct {
+		redraw   string
+		expected []string
+	}{
+		{redraws[0], []string{"first: busy"}},
+		{redraws[1], []string{"first: busy", "second/with/a/very/long/path/that/do..."}},
+		{redraws[2], []string{"first [##########..........]  50% Re...", "second/with/a/very/long/path/that/do..."}},
+		{redraws[4], []string{"first: done.", "second/with/a/very/long/path/that/do..."}},
+		{redraws[5], []string{"warning: message", "second/with/a/very/long/path/that/do..."}},
+		{redraws[6], []string{"second/with/a/very/long/path/that/does/not/fit: done."}},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestTtyProgressReporter-%d", index+1), func(t *testing.T) {
+			redraw := tc.redraw
+			if i := strings.Index(redraw, "\x1b["); i >= 0 {
+				redraw = redraw[:i]
+			}
+
+			lines := strings.Split(strings.TrimSuffix(redraw, "\n"), "\n")
+			if strings.Join(lines, "\n") != strings.Join(tc.expected, "\n") {
+				t.Fatalf("unexpected output:\n%q\nexpected:\n%q", lines, tc.expected)
+			}
+		})
+	}
+
+	if !strings.HasSuffix(out.String(), "\x1b[1A\r\x1b[Jsecond/with/a/very/long/path/that/does/not/fit: done.\n") {
+		t.Fatalf("finished output does not move up to the last running operation: %q", out.String())
+	}
+}
+
+func TestSilentProgressReporter(t *testing.T) {
+	var out bytes.Buffer
+	reporter := internal.NewSilentProgressReporter(&out)
+
+	task := reporter.Start("lib")
+	task.Update(models.Progress{Phase: "Receiving objects", Percent: 50, Current: 5, Total: 10})
+	reporter.Message("warning: message")
+	task.Done(nil)
+
+	if out.String() != "warning: message\n" {
+		t.Fatalf("unexpected output: %q", out.String())
+	}
+}
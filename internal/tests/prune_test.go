@@ -0,0 +1,174 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/internal"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/test_env"
+	"github.com/jeftadlvw/git-nest/utils"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestFindOrphanedModules(t *testing.T) {
+	cases := []struct {
+		excludeEntries   string
+		gitignoreEntries string
+		submodules       []models.Submodule
+		repositories     []string
+		directories      []string
+		expected         []string
+	}{
+		{"", "", nil, nil, nil, nil},
+		{"foo", "", nil, []string{"foo"}, nil, []string{"foo"}},
+		{"foo", "", []models.Submodule{{Path: "foo"}}, []string{"foo"}, nil, nil},
+		{"foo\nbar", "", []models.Submodule{{Path: "foo"}}, []string{"foo", "bar"}, nil, []string{"bar"}},
+		{"foo\nbar", "", nil, []string{"foo"}, []string{"bar"}, []string{"foo"}},
+		{"foo\n../outside\n.", "", nil, []string{"foo"}, nil, []string{"foo"}},
+		{"", "/lib/foo", nil, []string{"lib/foo"}, nil, []string{"lib/foo"}},
+		{"lib/foo", "/lib/foo", nil, []string{"lib/foo"}, nil, []string{"lib/foo"}},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestFindOrphanedModules-%d", index+1), func(t *testing.T) {
+			t.Parallel()
+
+			repoDir := models.Path(t.TempDir())
+			err := test_env.CreateLocalRepository(repoDir)
+			if err != nil {
+				t.Fatalf("error creating local repository: %s", err)
+			}
+
+			err = utils.WriteStrToFile(repoDir.SJoin(gitExcludeFile), gitExcludePrefix+"\n"+gitExcludeInfo+"\n"+tc.excludeEntries+"\n"+gitExcludeSuffix+"\n")
+			if err != nil {
+				t.Fatalf("error writing exclude file: %s", err)
+			}
+
+			if tc.gitignoreEntries != "" {
+				err = utils.WriteStrToFile(repoDir.SJoin(".gitignore"), gitExcludePrefix+"\n"+tc.gitignoreEntries+"\n"+gitExcludeSuffix+"\n")
+				if err != nil {
+					t.Fatalf("error writing gitignore file: %s", err)
+				}
+			}
+
+			for _, repository := range tc.repositories {
+				err = test_env.CreateLocalRepository(repoDir.SJoin(repository))
+				if err != nil {
+					t.Fatalf("error creating repository %s: %s", repository, err)
+				}
+			}
+
+			for _, directory := range tc.directories {
+				err = os.MkdirAll(filepath.Join(string(repoDir), directory), os.ModePerm)
+				if err != nil {
+					t.Fatalf("error creating directory %s: %s", directory, err)
+				}
+			}
+
+			context, err := internal.CreateContext(repoDir)
+			if err != nil {
+				t.Fatalf("error creating context: %s", err)
+			}
+			context.Config.Submodules = tc.submodules
+
+			orphans, err := internal.FindOrphanedModules(context)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			var paths []string
+			for _, orphan := range orphans {
+				paths = append(paths, orphan.Path.UnixString())
+			}
+
+			if !slices.Equal(paths, tc.expected) {
+				t.Fatalf("unexpected orphans: %v, expected %v", paths, tc.expected)
+			}
+		})
+	}
+}
+
+func TestFindOrphanedModulesFromState(t *testing.T) {
+	repoDir := models.Path(t.TempDir())
+	err := test_env.CreateLocalRepository(repoDir)
+	if err != nil {
+		t.Fatalf("error creating local repository: %s", err)
+	}
+
+	for _, repository := range []string{"foo", "bar"} {
+		err = test_env.CreateLocalRepository(repoDir.SJoin(repository))
+		if err != nil {
+			t.Fatalf("error creating repository %s: %s", repository, err)
+		}
+	}
+
+	context, err := internal.CreateContext(repoDir)
+	if err != nil {
+		t.Fatalf("error creating context: %s", err)
+	}
+	context.Config.Submodules = []models.Submodule{{Path: "bar"}}
+
+	// the exclude file no longer lists foo, but the state file still remembers it
+	state := internal.State{Modules: []internal.StateModule{{Path: "foo", Orphaned: true}, {Path: "bar"}, {Path: "missing"}}}
+	err = internal.WriteState(internal.StateFileFromContext(context), state)
+	if err != nil {
+		t.Fatalf("error writing state: %s", err)
+	}
+
+	orphans, err := internal.FindOrphanedModules(context)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(orphans) != 1 || orphans[0].Path != "foo" || orphans[0].Source != "state file" {
+		t.Fatalf("unexpected orphans: %+v", orphans)
+	}
+}
+
+func TestFindOrphanedModulesKept(t *testing.T) {
+	repoDir := models.Path(t.TempDir())
+	err := test_env.CreateLocalRepository(repoDir)
+	if err != nil {
+		t.Fatalf("error creating local repository: %s", err)
+	}
+
+	err = test_env.CreateLocalRepository(repoDir.SJoin("foo"))
+	if err != nil {
+		t.Fatalf("error creating repository foo: %s", err)
+	}
+
+	// the exclude file still lists foo, but its directory was kept on purpose
+	err = utils.WriteStrToFile(repoDir.SJoin(gitExcludeFile), gitExcludePrefix+"\n"+gitExcludeInfo+"\nfoo\n"+gitExcludeSuffix+"\n")
+	if err != nil {
+		t.Fatalf("error writing exclude file: %s", err)
+	}
+
+	context, err := internal.CreateContext(repoDir)
+	if err != nil {
+		t.Fatalf("error creating context: %s", err)
+	}
+
+	err = internal.KeepStateModule(context, "foo")
+	if err != nil {
+		t.Fatalf("error keeping module: %s", err)
+	}
+
+	state, err := internal.ReadState(internal.StateFileFromContext(context))
+	if err != nil {
+		t.Fatalf("error reading state: %s", err)
+	}
+	if len(state.Modules) != 1 || !state.Modules[0].Kept || !state.Modules[0].Orphaned {
+		t.Fatalf("unexpected state modules: %+v", state.Modules)
+	}
+
+	orphans, err := internal.FindOrphanedModules(context)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(orphans) != 0 {
+		t.Fatalf("unexpected orphans: %+v", orphans)
+	}
+}
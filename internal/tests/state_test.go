@@ -0,0 +1,144 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/internal"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/test_env"
+	"github.com/jeftadlvw/git-nest/utils"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUpdateState(t *testing.T) {
+	cases := []struct {
+		previous        []internal.StateModule
+		configured      []string
+		deleted         []string
+		expectedChanges []string
+		expectedModules []string
+		expectedOrphans []string
+	}{
+		{nil, nil, nil, nil, nil, nil},
+		{nil, []string{"foo"}, nil, []string{"added foo at {foo}"}, []string{"foo"}, nil},
+		{[]internal.StateModule{{Path: "foo", Commit: "{foo}"}}, []string{"foo"}, nil, nil, []string{"foo"}, nil},
+		{[]internal.StateModule{{Path: "foo", Commit: "1234567890"}}, []string{"foo"}, nil, []string{"updated foo 1234567 -> {foo}"}, []string{"foo"}, nil},
+		{[]internal.StateModule{{Path: "foo", Commit: "{foo}"}}, nil, nil, []string{"removed foo"}, []string{"foo"}, []string{"foo"}},
+		{[]internal.StateModule{{Path: "foo", Commit: "{foo}"}}, nil, []string{"foo"}, []string{"removed foo"}, nil, nil},
+		{[]internal.StateModule{{Path: "foo", Commit: "{foo}", Orphaned: true}}, nil, nil, nil, []string{"foo"}, []string{"foo"}},
+		{[]internal.StateModule{{Path: "foo", Commit: "{foo}", Orphaned: true}}, nil, []string{"foo"}, nil, nil, nil},
+		{[]internal.StateModule{{Path: "foo", Commit: "{foo}", Orphaned: true}}, []string{"foo"}, nil, []string{"added foo at {foo}"}, []string{"foo"}, nil},
+		{[]internal.StateModule{{Path: "bar", Commit: "{bar}"}}, []string{"bar"}, []string{"bar"}, nil, []string{"bar"}, nil},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestUpdateState-%d", index+1), func(t *testing.T) {
+			t.Parallel()
+
+			root := models.Path(t.TempDir())
+			commits := make(map[string]string)
+			for _, name := range []string{"foo", "bar"} {
+				repository := root.SJoin(name)
+				err := test_env.CreateLocalRepository(repository, "first")
+				if err != nil {
+					t.Fatalf("error creating repository: %s", err)
+				}
+
+				commits[name], err = utils.GetGitRefCommit(repository, "HEAD")
+				if err != nil {
+					t.Fatalf("error resolving head: %s", err)
+				}
+			}
+
+			replaceCommits := func(s string) string {
+				for name, commit := range commits {
+					s = strings.ReplaceAll(s, "{"+name+"}", commit[:7])
+				}
+				return s
+			}
+
+			for _, name := range tc.deleted {
+				err := os.RemoveAll(filepath.Join(string(root), name))
+				if err != nil {
+					t.Fatalf("error deleting %s: %s", name, err)
+				}
+			}
+
+			state := internal.State{}
+			for _, module := range tc.previous {
+				if commit, ok := commits[module.Path]; ok && module.Commit == "{"+module.Path+"}" {
+					module.Commit = commit
+				}
+				state.Modules = append(state.Modules, module)
+			}
+
+			context := models.NestContext{ProjectRoot: root}
+			for _, name := range tc.configured {
+				context.Config.Submodules = append(context.Config.Submodules, models.Submodule{Path: models.Path(name)})
+			}
+
+			now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+			state, changes := internal.UpdateState(state, context, "sync", now)
+
+			var changeStrs []string
+			for _, change := range changes {
+				changeStrs = append(changeStrs, change.String())
+			}
+			var expectedChanges []string
+			for _, change := range tc.expectedChanges {
+				expectedChanges = append(expectedChanges, replaceCommits(change))
+			}
+			if !reflect.DeepEqual(changeStrs, expectedChanges) {
+				t.Fatalf("unexpected changes %q, expected %q", changeStrs, expectedChanges)
+			}
+
+			var modules, orphans []string
+			for _, module := range state.Modules {
+				modules = append(modules, module.Path)
+				if module.Orphaned {
+					orphans = append(orphans, module.Path)
+				}
+			}
+			if !reflect.DeepEqual(modules, tc.expectedModules) || !reflect.DeepEqual(orphans, tc.expectedOrphans) {
+				t.Fatalf("unexpected modules %v (orphaned %v), expected %v (orphaned %v)", modules, orphans, tc.expectedModules, tc.expectedOrphans)
+			}
+
+			if (len(state.History) != 0) != (len(changes) != 0) {
+				t.Fatalf("unexpected history length %d", len(state.History))
+			}
+		})
+	}
+}
+
+func TestReadWriteState(t *testing.T) {
+	p := models.Path(filepath.Join(t.TempDir(), "git-nest", "state.json"))
+
+	state, err := internal.ReadState(p)
+	if err != nil || len(state.Modules) != 0 || len(state.History) != 0 {
+		t.Fatalf("expected empty state for missing file, got %+v, %v", state, err)
+	}
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	expected := internal.State{
+		Modules: []internal.StateModule{{Path: "foo", Url: "https://example.com/foo", Commit: "abc", AppliedAt: now}},
+		History: []internal.StateHistoryEntry{{Time: now, Command: "sync", Changes: []internal.StateChange{{Kind: internal.StateChangeAdded, Path: "foo", Commit: "abc"}}}},
+	}
+
+	err = internal.WriteState(p, expected)
+	if err != nil {
+		t.Fatalf("unexpected error writing state: %s", err)
+	}
+
+	state, err = internal.ReadState(p)
+	if err != nil {
+		t.Fatalf("unexpected error reading state: %s", err)
+	}
+
+	if !reflect.DeepEqual(state, expected) {
+		t.Fatalf("unexpected state %+v, expected %+v", state, expected)
+	}
+}
@@ -0,0 +1,91 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/internal"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/models/urls"
+	"github.com/jeftadlvw/git-nest/test_env"
+	"github.com/jeftadlvw/git-nest/utils"
+	"testing"
+)
+
+func TestSubmoduleExistsRemoteEquivalence(t *testing.T) {
+	configuredUrl, err := urls.HttpUrlFromString("https://example.com/user/repository")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		origin             string
+		sshHttpsEquivalent bool
+		expected           int
+	}{
+		{"https://example.com/user/repository", false, internal.SUBMODULE_EXISTS_OK},
+		{"https://example.com/user/repository.git", false, internal.SUBMODULE_EXISTS_OK},
+		{"https://Example.COM:443/user/repository/", false, internal.SUBMODULE_EXISTS_OK},
+		{"https://token@example.com/user/repository.git", false, internal.SUBMODULE_EXISTS_OK},
+		{"git@example.com:user/repository.git", false, internal.SUBMODULE_EXISTS_ERR_REMOTE},
+		{"git@example.com:user/repository.git", true, internal.SUBMODULE_EXISTS_OK},
+		{"https://example.com/user/other", true, internal.SUBMODULE_EXISTS_ERR_REMOTE},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestSubmoduleExistsRemoteEquivalence-%d", index+1), func(t *testing.T) {
+			t.Parallel()
+
+			root := models.Path(t.TempDir())
+			err := test_env.CreateLocalRepository(root.SJoin("module"), "first")
+			if err != nil {
+				t.Fatalf("error creating module repository: %s", err)
+			}
+
+			out, err := utils.RunCommandCombinedOutput(root.SJoin("module"), "git", "remote", "add", "origin", tc.origin)
+			if err != nil {
+				t.Fatalf("error adding remote: %s; %s", err, out)
+			}
+
+			submodule := models.Submodule{Path: "module", Url: &configuredUrl, Ref: test_env.RepoBranchDefault}
+			status, payload, err := internal.SubmoduleExists(submodule, root, tc.sshHttpsEquivalent)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if status != tc.expected {
+				t.Fatalf("unexpected status %d, expected %d (payload: %s)", status, tc.expected, payload)
+			}
+		})
+	}
+}
+
+func TestFmtSubmoduleExistOutput(t *testing.T) {
+	cases := []struct {
+		status   int
+		payload  string
+		expected string
+		err      bool
+	}{
+		{internal.SUBMODULE_EXISTS_OK, "", "ok", false},
+		{internal.SUBMODULE_EXISTS_UNDEFINED_REF, "", "ok, empty ref", false},
+		{internal.SUBMODULE_EXISTS_ERR_NO_EXIST, "", "no exist", false},
+		{internal.SUBMODULE_EXISTS_ERR_REMOTE_MISSING, "origin", "error: missing remote origin", false},
+		{-1, "", "", true},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestFmtSubmoduleExistOutput-%d", index+1), func(t *testing.T) {
+			t.Parallel()
+
+			existStr, err := internal.FmtSubmoduleExistOutput(tc.status, tc.payload, nil)
+			if tc.err && err == nil {
+				t.Fatalf("no error, but expected one")
+			}
+			if !tc.err && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if existStr != tc.expected {
+				t.Fatalf("unexpected output: >%s<, expected >%s<", existStr, tc.expected)
+			}
+		})
+	}
+}
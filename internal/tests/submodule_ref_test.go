@@ -0,0 +1,85 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/internal"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/test_env"
+	"github.com/jeftadlvw/git-nest/utils"
+	"strings"
+	"testing"
+)
+
+func TestSubmoduleHeadMatches(t *testing.T) {
+	cases := []struct {
+		checkout     string
+		ref          string
+		refKind      models.RefKind
+		expectedKind models.RefKind
+		expected     bool
+	}{
+		{test_env.RepoBranchDefault, test_env.RepoBranchDefault, models.RefKindAuto, models.RefKindBranch, true},
+		{test_env.RepoBranchDefault, "feature", models.RefKindAuto, models.RefKindBranch, false},
+		{test_env.RepoBranchDefault, "v1", models.RefKindAuto, models.RefKindTag, false},
+		{test_env.RepoBranchDefault, "{second}", models.RefKindAuto, models.RefKindCommit, true},
+		{"v1", "v1", models.RefKindAuto, models.RefKindTag, true},
+		{"v1", "{first:7}", models.RefKindAuto, models.RefKindCommit, true},
+		{"v1", "{first:7}", models.RefKindCommit, models.RefKindCommit, true},
+		{"v1", "{second:7}", models.RefKindAuto, models.RefKindCommit, false},
+		{"v1", "v1", models.RefKindBranch, models.RefKindBranch, false},
+		{"{first}", "v1", models.RefKindTag, models.RefKindTag, true},
+		{"{second}", "v1", models.RefKindAuto, models.RefKindTag, false},
+		{"{first}", "abc1234", models.RefKindAuto, models.RefKindCommit, false},
+		{"{first}", "release", models.RefKindAuto, models.RefKindBranch, false},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestSubmoduleHeadMatches-%d", index+1), func(t *testing.T) {
+			t.Parallel()
+
+			root := models.Path(t.TempDir())
+			repository := root.Join("module")
+			err := test_env.CreateLocalRepository(repository, "first", "second")
+			if err != nil {
+				t.Fatalf("error creating module repository: %s", err)
+			}
+
+			commits := make(map[string]string)
+			for name, rev := range map[string]string{"first": "HEAD~1", "second": "HEAD"} {
+				commits[name], err = utils.GetGitRefCommit(repository, rev)
+				if err != nil {
+					t.Fatalf("error resolving %s: %s", rev, err)
+				}
+			}
+
+			replaceCommits := func(s string) string {
+				for name, commit := range commits {
+					s = strings.ReplaceAll(s, "{"+name+":7}", commit[:7])
+					s = strings.ReplaceAll(s, "{"+name+"}", commit)
+				}
+				return s
+			}
+
+			for _, args := range [][]string{{"tag", "v1", commits["first"]}, {"branch", "feature", commits["first"]}, {"checkout", "--quiet", replaceCommits(tc.checkout)}} {
+				out, err := utils.RunCommandCombinedOutput(repository, "git", args...)
+				if err != nil {
+					t.Fatalf("error running git %s: %s; %s", args[0], err, out)
+				}
+			}
+
+			submodule := models.Submodule{Path: "module", Ref: replaceCommits(tc.ref), RefKind: tc.refKind}
+			if kind := internal.SubmoduleRefKind(submodule, repository); kind != tc.expectedKind {
+				t.Fatalf("unexpected ref kind %s, expected %s", kind, tc.expectedKind)
+			}
+
+			head, err := internal.GetSubmoduleHead(repository)
+			if err != nil {
+				t.Fatalf("error reading head: %s", err)
+			}
+
+			if matches := internal.SubmoduleHeadMatches(submodule, repository, head); matches != tc.expected {
+				t.Fatalf("unexpected match %t for head %s", matches, head)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/jeftadlvw/git-nest/internal"
 	"github.com/jeftadlvw/git-nest/models"
 	"github.com/jeftadlvw/git-nest/models/urls"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -55,7 +56,7 @@ func TestSubmoduleArrTomlStrFromNestConfig(t *testing.T) {
 
 	nestConfig := models.NestConfig{}
 	nestConfig.Submodules = append(nestConfig.Submodules, models.Submodule{})
-	nestConfig.Submodules = append(nestConfig.Submodules, models.Submodule{"", &urls.HttpUrl{"example.com", 80, "/foo", false}, ""})
+	nestConfig.Submodules = append(nestConfig.Submodules, models.Submodule{Path: "", Url: &urls.HttpUrl{"example.com", 80, "/foo", false}, Ref: ""})
 
 	expectedOutput := `[[submodule]]
   path = ""
@@ -86,7 +87,7 @@ func TestSubmoduleToTomlConfig(t *testing.T) {
 	}
 
 	// set values
-	submodule = models.Submodule{"example/path", &urls.HttpUrl{"example.com", 443, "", true}, "example-ref"}
+	submodule = models.Submodule{Path: "example/path", Url: &urls.HttpUrl{"example.com", 443, "", true}, Ref: "example-ref"}
 	expectedOutput = `[[submodule]]
   path = "example/path"
   url = "https://example.com/"
@@ -97,7 +98,7 @@ func TestSubmoduleToTomlConfig(t *testing.T) {
 	}
 
 	// double seperators
-	submodule = models.Submodule{"example//path", &urls.HttpUrl{"example.com", 443, "", true}, "example-ref"}
+	submodule = models.Submodule{Path: "example//path", Url: &urls.HttpUrl{"example.com", 443, "", true}, Ref: "example-ref"}
 	expectedOutput = `[[submodule]]
   path = "example/path"
   url = "https://example.com/"
@@ -108,7 +109,7 @@ func TestSubmoduleToTomlConfig(t *testing.T) {
 	}
 
 	// windows path style
-	submodule = models.Submodule{"example\\path", &urls.HttpUrl{"example.com", 443, "", true}, "example-ref"}
+	submodule = models.Submodule{Path: "example\\path", Url: &urls.HttpUrl{"example.com", 443, "", true}, Ref: "example-ref"}
 	expectedOutput = `[[submodule]]
   path = "example/path"
   url = "https://example.com/"
@@ -119,7 +120,7 @@ func TestSubmoduleToTomlConfig(t *testing.T) {
 	}
 
 	// something messed up
-	submodule = models.Submodule{"example\\\\path", &urls.HttpUrl{"example.com", 443, "", true}, "example-ref"}
+	submodule = models.Submodule{Path: "example\\\\path", Url: &urls.HttpUrl{"example.com", 443, "", true}, Ref: "example-ref"}
 	expectedOutput = `[[submodule]]
   path = "example/path"
   url = "https://example.com/"
@@ -128,4 +129,233 @@ func TestSubmoduleToTomlConfig(t *testing.T) {
 	if output := internal.SubmoduleToTomlConfig(submodule, indent); output != expectedOutput {
 		t.Fatalf("\nExpected:\n>%s<\n\nActual:\n>%s<", expectedOutput, output)
 	}
+
+	// declared ref kind
+	submodule = models.Submodule{Path: "example/path", Url: &urls.HttpUrl{HostnameS: "example.com", Port: 443, Secure: true}, Ref: "v1.0.0", RefKind: models.RefKindTag}
+	expectedOutput = `[[submodule]]
+  path = "example/path"
+  url = "https://example.com/"
+  ref = "v1.0.0"
+  ref_kind = "tag"`
+
+	if output := internal.SubmoduleToTomlConfig(submodule, indent); output != expectedOutput {
+		t.Fatalf("\nExpected:\n>%s<\n\nActual:\n>%s<", expectedOutput, output)
+	}
+
+	nestConfig := models.NestConfig{}
+	err := internal.PopulateNestConfigFromToml(&nestConfig, expectedOutput, true)
+	if err != nil {
+		t.Fatalf("error populating nest config from toml string: %s", err)
+	}
+	if len(nestConfig.Submodules) != 1 || nestConfig.Submodules[0].RefKind != models.RefKindTag {
+		t.Fatalf("ref kind does not match after round trip: %v", nestConfig.Submodules)
+	}
+
+	// declared remote and additional remotes
+	submodule = models.Submodule{
+		Path:    "example/path",
+		Url:     &urls.HttpUrl{HostnameS: "example.com", Port: 443, PathS: "/upstream", Secure: true},
+		Remote:  "upstream",
+		Remotes: map[string]string{"origin": "git@example.com:user/fork.git", "backup": "https://example.org/backup"},
+	}
+	expectedOutput = `[[submodule]]
+  path = "example/path"
+  url = "https://example.com/upstream"
+  remote = "upstream"
+  remotes = { backup = "https://example.org/backup", origin = "git@example.com:user/fork.git" }`
+
+	output := internal.SubmoduleToTomlConfig(submodule, indent)
+	if output != expectedOutput {
+		t.Fatalf("\nExpected:\n>%s<\n\nActual:\n>%s<", expectedOutput, output)
+	}
+
+	nestConfig = models.NestConfig{}
+	err = internal.PopulateNestConfigFromToml(&nestConfig, output, true)
+	if err != nil {
+		t.Fatalf("error populating nest config from toml string: %s", err)
+	}
+	if len(nestConfig.Submodules) != 1 || nestConfig.Submodules[0].Remote != submodule.Remote || !reflect.DeepEqual(nestConfig.Submodules[0].Remotes, submodule.Remotes) {
+		t.Fatalf("remotes do not match after round trip: %v", nestConfig.Submodules)
+	}
+
+	// remote names containing a dot are quoted, so that they are not parsed as dotted keys
+	submodule = models.Submodule{
+		Path:    "example/path",
+		Url:     &urls.HttpUrl{HostnameS: "example.com", Port: 443, PathS: "/repository", Secure: true},
+		Remotes: map[string]string{"my.fork": "https://example.org/fork"},
+	}
+	expectedOutput = `[[submodule]]
+  path = "example/path"
+  url = "https://example.com/repository"
+  remotes = { "my.fork" = "https://example.org/fork" }`
+
+	output = internal.SubmoduleToTomlConfig(submodule, indent)
+	if output != expectedOutput {
+		t.Fatalf("\nExpected:\n>%s<\n\nActual:\n>%s<", expectedOutput, output)
+	}
+
+	nestConfig = models.NestConfig{}
+	err = internal.PopulateNestConfigFromToml(&nestConfig, output, true)
+	if err != nil {
+		t.Fatalf("error populating nest config from toml string: %s", err)
+	}
+	if len(nestConfig.Submodules) != 1 || !reflect.DeepEqual(nestConfig.Submodules[0].Remotes, submodule.Remotes) {
+		t.Fatalf("remotes do not match after round trip: %v", nestConfig.Submodules)
+	}
+
+	// remote urls are escaped as well
+	submodule = models.Submodule{
+		Path:    "example/path",
+		Url:     &urls.HttpUrl{HostnameS: "example.com", Port: 443, PathS: "/repository", Secure: true},
+		Remotes: map[string]string{"fork": `https://example.org/"fork"\repo`},
+	}
+	expectedOutput = `[[submodule]]
+  path = "example/path"
+  url = "https://example.com/repository"
+  remotes = { fork = "https://example.org/\"fork\"\\repo" }`
+
+	output = internal.SubmoduleToTomlConfig(submodule, indent)
+	if output != expectedOutput {
+		t.Fatalf("\nExpected:\n>%s<\n\nActual:\n>%s<", expectedOutput, output)
+	}
+
+	nestConfig = models.NestConfig{}
+	err = internal.PopulateNestConfigFromToml(&nestConfig, output, true)
+	if err != nil {
+		t.Fatalf("error populating nest config from toml string: %s", err)
+	}
+	if len(nestConfig.Submodules) != 1 || !reflect.DeepEqual(nestConfig.Submodules[0].Remotes, submodule.Remotes) {
+		t.Fatalf("escaped remotes do not match after round trip: %v", nestConfig.Submodules)
+	}
+
+	// windows paths of signature settings are escaped
+	submodule = models.Submodule{
+		Path:           "example/path",
+		Url:            &urls.HttpUrl{HostnameS: "example.com", Port: 443, PathS: "/repository", Secure: true},
+		AllowedSigners: `C:\keys\signers`,
+		GpgHome:        `C:\keys\gnupg`,
+	}
+	expectedOutput = `[[submodule]]
+  path = "example/path"
+  url = "https://example.com/repository"
+  allowed_signers = "C:\\keys\\signers"
+  gpg_home = "C:\\keys\\gnupg"`
+
+	output = internal.SubmoduleToTomlConfig(submodule, indent)
+	if output != expectedOutput {
+		t.Fatalf("\nExpected:\n>%s<\n\nActual:\n>%s<", expectedOutput, output)
+	}
+
+	nestConfig = models.NestConfig{}
+	err = internal.PopulateNestConfigFromToml(&nestConfig, output, true)
+	if err != nil {
+		t.Fatalf("error populating nest config from toml string: %s", err)
+	}
+	if len(nestConfig.Submodules) != 1 || nestConfig.Submodules[0].AllowedSigners != submodule.AllowedSigners || nestConfig.Submodules[0].GpgHome != submodule.GpgHome {
+		t.Fatalf("signature settings do not match after round trip: %v", nestConfig.Submodules)
+	}
+
+	// groups and sparse paths
+	submodule = models.Submodule{
+		Path:        "example/path",
+		Url:         &urls.HttpUrl{HostnameS: "example.com", Port: 443, PathS: "/repository", Secure: true},
+		Groups:      []string{"web", "server"},
+		SparsePaths: []string{"src", "docs/README.md"},
+	}
+	expectedOutput = `[[submodule]]
+  path = "example/path"
+  url = "https://example.com/repository"
+  groups = ["web", "server"]
+  sparse_paths = ["src", "docs/README.md"]`
+
+	output = internal.SubmoduleToTomlConfig(submodule, indent)
+	if output != expectedOutput {
+		t.Fatalf("\nExpected:\n>%s<\n\nActual:\n>%s<", expectedOutput, output)
+	}
+
+	nestConfig = models.NestConfig{}
+	err = internal.PopulateNestConfigFromToml(&nestConfig, output, true)
+	if err != nil {
+		t.Fatalf("error populating nest config from toml string: %s", err)
+	}
+	if len(nestConfig.Submodules) != 1 || !reflect.DeepEqual(nestConfig.Submodules[0].Groups, submodule.Groups) || !reflect.DeepEqual(nestConfig.Submodules[0].SparsePaths, submodule.SparsePaths) {
+		t.Fatalf("groups and sparse paths do not match after round trip: %v", nestConfig.Submodules)
+	}
+
+	// backslashes, quotes and control characters are escaped
+	submodule = models.Submodule{
+		Path:        "example/path",
+		Url:         &urls.HttpUrl{HostnameS: "example.com", Port: 443, PathS: "/repository", Secure: true},
+		Groups:      []string{`say "hi"`},
+		SparsePaths: []string{`sub\x`, "tab\there"},
+	}
+	expectedOutput = `[[submodule]]
+  path = "example/path"
+  url = "https://example.com/repository"
+  groups = ["say \"hi\""]
+  sparse_paths = ["sub\\x", "tab\there"]`
+
+	output = internal.SubmoduleToTomlConfig(submodule, indent)
+	if output != expectedOutput {
+		t.Fatalf("\nExpected:\n>%s<\n\nActual:\n>%s<", expectedOutput, output)
+	}
+
+	nestConfig = models.NestConfig{}
+	err = internal.PopulateNestConfigFromToml(&nestConfig, output, true)
+	if err != nil {
+		t.Fatalf("error populating nest config from toml string: %s", err)
+	}
+	if len(nestConfig.Submodules) != 1 || !reflect.DeepEqual(nestConfig.Submodules[0].Groups, submodule.Groups) || !reflect.DeepEqual(nestConfig.Submodules[0].SparsePaths, submodule.SparsePaths) {
+		t.Fatalf("escaped groups and sparse paths do not match after round trip: %v", nestConfig.Submodules)
+	}
+}
+
+func TestConfigToTomlConfig(t *testing.T) {
+
+	indent := "  "
+
+	// default config
+	expectedOutput := `[config]
+  allow_duplicate_origins = false
+  allow_unequal_roots = false`
+
+	if output := internal.ConfigToTomlConfig(models.Config{}, indent); output != expectedOutput {
+		t.Fatalf("\nExpected:\n>%s<\n\nActual:\n>%s<", expectedOutput, output)
+	}
+
+	// set values and read them back in
+	configs := []models.Config{
+		{AllowDuplicateOrigins: true, AllowUnequalRoots: true},
+		{IgnoreMode: models.IgnoreModeBoth, GitignoreLocation: models.GitignoreLocationDirectory},
+		{SshHttpsEquivalent: true},
+		{VerifySignature: true, AllowedSigners: "keys/allowed_signers", GpgHome: "keys/gnupg"},
+		{VerifySignature: true, AllowedSigners: `C:\keys\signers`, GpgHome: `C:\Users\ci\"gnupg"`},
+		{RecordIntegrity: true},
+		{Policy: models.Policy{AllowedHosts: []string{"github.com", "*.example.com"}, RequiredRefKinds: []models.RefKind{models.RefKindTag}, ForbiddenPaths: []string{"vendor"}}},
+		{AllowDuplicateOrigins: true, Policy: models.Policy{AllowedUrls: []string{"https://github.com/org/*"}}, Credentials: []models.Credential{{Host: "example.com", Helper: "store"}}},
+		{Credentials: []models.Credential{
+			{Host: "example.com", Helper: "store"},
+			{Host: "git.example.com:8443", TokenEnv: "EXAMPLE_TOKEN", Username: "ci"},
+		}},
+		{UrlRewrites: []models.UrlRewrite{
+			{Prefix: "https://github.com/", Replacement: "https://git-mirror.example.com/github/"},
+		}},
+		{Timeouts: models.Timeouts{Clone: "1h", LsRemote: "0", Local: "5m"}},
+		{RecordIntegrity: true, Timeouts: models.Timeouts{Fetch: "90s"}, Credentials: []models.Credential{{Host: "example.com", Helper: "store"}}},
+		{Retry: models.Retry{Attempts: 3, Backoff: "5s", MaxBackoff: "1m", RetryOn: []models.GitErrorClass{models.GitErrorClassNetwork, models.GitErrorClassServer}}},
+	}
+
+	for _, config := range configs {
+		output := internal.ConfigToTomlConfig(config, indent)
+
+		nestConfig := models.NestConfig{}
+		err := internal.PopulateNestConfigFromToml(&nestConfig, output, true)
+		if err != nil {
+			t.Fatalf("error populating nest config from toml string: %s", err)
+		}
+
+		if !reflect.DeepEqual(nestConfig.Config, config) {
+			t.Fatalf("config does not match after round trip (%v != %v)", nestConfig.Config, config)
+		}
+	}
 }
@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/internal"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/utils"
+	"reflect"
+	"testing"
+)
+
+func TestParseUrlRewrites(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected []models.UrlRewrite
+		err      bool
+	}{
+		{"", nil, false},
+		{" ; ", nil, false},
+		{"https://github.com/=https://mirror.example.com/github/", []models.UrlRewrite{{Prefix: "https://github.com/", Replacement: "https://mirror.example.com/github/"}}, false},
+		{"https://github.com/ = https://mirror.example.com/github/; https://gitlab.com/=https://mirror.example.com/gitlab/", []models.UrlRewrite{
+			{Prefix: "https://github.com/", Replacement: "https://mirror.example.com/github/"},
+			{Prefix: "https://gitlab.com/", Replacement: "https://mirror.example.com/gitlab/"},
+		}, false},
+		{"https://github.com/", nil, true},
+		{"https://github.com/=", nil, true},
+		{"https://github.com/=a;https://github.com/=b", nil, true},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestParseUrlRewrites-%d", index+1), func(t *testing.T) {
+			t.Parallel()
+
+			rewrites, err := internal.ParseUrlRewrites(tc.input)
+			if tc.err && err == nil {
+				t.Fatalf("no error, but expected one")
+			}
+			if !tc.err && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(rewrites, tc.expected) {
+				t.Fatalf("unexpected rewrites %v, expected %v", rewrites, tc.expected)
+			}
+		})
+	}
+}
+
+func TestMergeUrlRewrites(t *testing.T) {
+	env := []models.UrlRewrite{{Prefix: "https://github.com/", Replacement: "env"}}
+	local := []models.UrlRewrite{{Prefix: "https://github.com/", Replacement: "local"}, {Prefix: "https://gitlab.com/", Replacement: "local"}}
+	project := []models.UrlRewrite{{Prefix: "https://gitlab.com/", Replacement: "project"}, {Prefix: "https://example.com/", Replacement: "project"}}
+
+	expected := []models.UrlRewrite{
+		{Prefix: "https://github.com/", Replacement: "env"},
+		{Prefix: "https://gitlab.com/", Replacement: "local"},
+		{Prefix: "https://example.com/", Replacement: "project"},
+	}
+
+	if merged := internal.MergeUrlRewrites(env, local, project); !reflect.DeepEqual(merged, expected) {
+		t.Fatalf("unexpected rewrites %v, expected %v", merged, expected)
+	}
+}
+
+func TestReadLocalUrlRewrites(t *testing.T) {
+	cases := []struct {
+		content  string
+		expected []models.UrlRewrite
+		err      bool
+	}{
+		{"", nil, false},
+		{"[[config.url_rewrite]]\nprefix = \"https://github.com/\"\nreplacement = \"https://mirror.example.com/\"\n", []models.UrlRewrite{{Prefix: "https://github.com/", Replacement: "https://mirror.example.com/"}}, false},
+		{"[config]\nallow_duplicate_origins = true\n", nil, true},
+		{"[[submodule]]\npath = \"foo\"\n", nil, true},
+		{"[[config.url_rewrite]]\nprefix = \"https://github.com/\"\n", nil, true},
+		{"[config", nil, true},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestReadLocalUrlRewrites-%d", index+1), func(t *testing.T) {
+			t.Parallel()
+
+			tempDir := models.Path(t.TempDir())
+			p := tempDir.SJoin("git-nest.toml")
+			err := utils.WriteStrToFile(p, tc.content)
+			if err != nil {
+				t.Fatalf("error writing local configuration: %s", err)
+			}
+
+			rewrites, err := internal.ReadLocalUrlRewrites(p)
+			if tc.err && err == nil {
+				t.Fatalf("no error, but expected one")
+			}
+			if !tc.err && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(rewrites, tc.expected) {
+				t.Fatalf("unexpected rewrites %v, expected %v", rewrites, tc.expected)
+			}
+		})
+	}
+
+	tempDir := models.Path(t.TempDir())
+	rewrites, err := internal.ReadLocalUrlRewrites(tempDir.SJoin("non-existing.toml"))
+	if err != nil || rewrites != nil {
+		t.Fatalf("non-existing local configuration returned (%v, %v)", rewrites, err)
+	}
+}
@@ -5,8 +5,11 @@ import (
 	"github.com/jeftadlvw/git-nest/internal"
 	"github.com/jeftadlvw/git-nest/internal/constants"
 	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/models/urls"
+	"github.com/jeftadlvw/git-nest/test_env"
 	"github.com/jeftadlvw/git-nest/utils"
 	"os"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -332,3 +335,178 @@ func TestWriteProjectConfigFiles(t *testing.T) {
 		}
 	})
 }
+
+func TestWriteProjectConfigFilesGitDirectories(t *testing.T) {
+
+	const (
+		layoutWorktree = iota
+		layoutSeparateGitDir
+		layoutGitDirEnv
+	)
+
+	cases := []struct {
+		layout int
+	}{
+		{layoutWorktree},
+		{layoutSeparateGitDir},
+		{layoutGitDirEnv},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestWriteProjectConfigFilesGitDirectories-%d", index+1), func(t *testing.T) {
+			tempDir := models.Path(t.TempDir())
+			mainRepo := tempDir.SJoin("main")
+			projectRoot := tempDir.SJoin("project")
+			gitDir := tempDir.SJoin("gitdir")
+
+			run := func(d models.Path, args ...string) {
+				out, err := utils.RunCommandCombinedOutput(d, "git", args...)
+				if err != nil {
+					t.Fatalf("error running git %s: %s; %s", args[0], err, out)
+				}
+			}
+
+			switch tc.layout {
+			case layoutWorktree:
+				err := test_env.CreateLocalRepository(mainRepo, "initial commit")
+				if err != nil {
+					t.Fatalf("error creating local repository: %s", err)
+				}
+				run(mainRepo, "worktree", "add", "--quiet", "--detach", projectRoot.String())
+				gitDir = mainRepo.SJoin(".git")
+			case layoutSeparateGitDir:
+				err := os.Mkdir(projectRoot.String(), os.ModePerm)
+				if err != nil {
+					t.Fatalf("error creating project directory: %s", err)
+				}
+				run(projectRoot, "init", "--quiet", "--separate-git-dir", gitDir.String())
+			case layoutGitDirEnv:
+				err := os.Mkdir(projectRoot.String(), os.ModePerm)
+				if err != nil {
+					t.Fatalf("error creating project directory: %s", err)
+				}
+				run(tempDir, "init", "--quiet", "--bare", gitDir.String())
+				t.Setenv("GIT_DIR", gitDir.String())
+				t.Setenv("GIT_WORK_TREE", projectRoot.String())
+			}
+
+			context, err := internal.CreateContext(projectRoot)
+			if err != nil {
+				t.Fatalf("error creating context: %s", err)
+			}
+
+			if !context.IsGitRepository || context.GitRepositoryRoot.String() != projectRoot.String() {
+				t.Fatalf("project was not detected as git repository root: %s", context.GitRepositoryRoot)
+			}
+			if context.GitCommonDir.String() != gitDir.String() {
+				t.Fatalf("unexpected common git directory: %s, expected %s", context.GitCommonDir, gitDir)
+			}
+
+			context.Config.Submodules = []models.Submodule{{Path: "module"}}
+			r, err := internal.WriteProjectConfigFiles(context)
+			if err != nil {
+				t.Fatalf("failed to write project config files: %s", err)
+			}
+			if r.GitExcludeWriteError != nil || !r.GitExcludeWritten {
+				t.Fatalf("writing to git exclude failed: %v", r.GitExcludeWriteError)
+			}
+
+			excludeEntries, found, err := internal.ReadSubmoduleIgnoreConfig(gitDir.SJoin("info", "exclude"))
+			if err != nil || !found {
+				t.Fatalf("git exclude file in common git directory was not written: %v", err)
+			}
+			if len(excludeEntries) != 1 || excludeEntries[0] != "module" {
+				t.Fatalf("unexpected exclude entries: %v", excludeEntries)
+			}
+
+			// no bogus directory next to a .git file
+			bogusDir := projectRoot.SJoin(".git", "info")
+			if tc.layout != layoutGitDirEnv && bogusDir.Exists() {
+				t.Fatalf("bogus exclude directory was created at %s", bogusDir)
+			}
+		})
+	}
+}
+
+func TestWriteNestConfigKeepsTables(t *testing.T) {
+	const configTables = `[config.policy]
+  allowed_hosts = ["github.com"]
+  forbidden_paths = ["vendor/*"]
+
+[[config.credentials]]
+  host = "github.com"
+  token_env = "GITHUB_TOKEN"
+
+[[config.url_rewrite]]
+  prefix = "https://github.com/"
+  replacement = "https://mirror.example.com/"
+
+[config.timeouts]
+  clone = "5m"
+
+[config.retry]
+  attempts = 3
+  retry_on = ["network"]`
+
+	const submodule = `[[submodule]]
+  path = "lib"
+  url = "https://github.com/user/lib"`
+
+	const description = `# nested modules of this project, see "git nest --help"
+# [config.fake] is a comment and no table`
+
+	cases := []string{
+		"[config]\n  allow_duplicate_origins = true\n\n" + configTables + "\n\n" + submodule + "\n",
+		"[config]\n  allow_duplicate_origins = true\n\n" + submodule + "\n\n" + configTables + "\n",
+		"[config]\n  allow_duplicate_origins = true\n\n" + submodule + "\n\n" + submodule + "\n\n" + configTables + "\n",
+		description + "\n[config]\n  allow_duplicate_origins = true\n\n" + submodule + "\n" + configTables + "\n",
+	}
+
+	for index, content := range cases {
+		t.Run(fmt.Sprintf("TestWriteNestConfigKeepsTables-%d", index+1), func(t *testing.T) {
+			t.Parallel()
+
+			tempDir := models.Path(t.TempDir())
+			configFile := tempDir.SJoin(constants.ConfigFileName)
+			err := utils.WriteStrToFile(configFile, content)
+			if err != nil {
+				t.Fatalf("error writing configuration: %s", err)
+			}
+
+			var before models.NestConfig
+			err = internal.PopulateNestConfigFromToml(&before, content, true)
+			if err != nil {
+				t.Fatalf("error parsing configuration: %s", err)
+			}
+
+			movedUrl, err := urls.HttpUrlFromString("https://github.com/user/lib")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			submodules := []models.Submodule{{Path: "moved", Url: &movedUrl}}
+			err = internal.WriteNestConfig(configFile, submodules)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			written, err := utils.ReadFileToStr(configFile)
+			if err != nil {
+				t.Fatalf("error reading configuration: %s", err)
+			}
+
+			var after models.NestConfig
+			err = internal.PopulateNestConfigFromToml(&after, written, true)
+			if err != nil {
+				t.Fatalf("error parsing written configuration: %s\n%s", err, written)
+			}
+
+			if !reflect.DeepEqual(before.Config, after.Config) {
+				t.Fatalf("configuration changed:\n%+v\n%+v\n%s", before.Config, after.Config, written)
+			}
+			if len(after.Submodules) != 1 || after.Submodules[0].Path != "moved" {
+				t.Fatalf("unexpected submodules: %+v\n%s", after.Submodules, written)
+			}
+		})
+	}
+}
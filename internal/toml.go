@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/BurntSushi/toml"
 	"github.com/jeftadlvw/git-nest/models"
+	"regexp"
 	"strings"
 )
 
@@ -24,6 +25,137 @@ func PopulateNestConfigFromToml(nestConfig *models.NestConfig, s string, strict
 	return nil
 }
 
+/*
+ConfigToTomlConfig returns a configuration string in TOML's markup language for the [config] section of a models.Config.
+*/
+func ConfigToTomlConfig(c models.Config, indent string) string {
+	var sb strings.Builder
+
+	sb.WriteString("[config]")
+	sb.WriteString("\n")
+
+	sb.WriteString(formatTomlKeyRawValue("allow_duplicate_origins", fmt.Sprintf("%t", c.AllowDuplicateOrigins), indent))
+	sb.WriteString(formatTomlKeyRawValue("allow_unequal_roots", fmt.Sprintf("%t", c.AllowUnequalRoots), indent))
+
+	// optional values are only written if they differ from their default
+	if c.IgnoreMode != "" {
+		sb.WriteString(formatTomlKeyValue("ignore_mode", c.IgnoreMode, indent))
+	}
+	if c.GitignoreLocation != "" {
+		sb.WriteString(formatTomlKeyValue("gitignore_location", c.GitignoreLocation, indent))
+	}
+	if c.SshHttpsEquivalent {
+		sb.WriteString(formatTomlKeyRawValue("ssh_https_equivalent", "true", indent))
+	}
+
+	if c.VerifySignature {
+		sb.WriteString(formatTomlKeyRawValue("verify_signature", "true", indent))
+	}
+	if c.AllowedSigners != "" {
+		sb.WriteString(formatTomlKeyValue("allowed_signers", c.AllowedSigners, indent))
+	}
+	if c.GpgHome != "" {
+		sb.WriteString(formatTomlKeyValue("gpg_home", c.GpgHome, indent))
+	}
+
+	if c.RecordIntegrity {
+		sb.WriteString(formatTomlKeyRawValue("record_integrity", "true", indent))
+	}
+
+	if !c.Policy.Empty() {
+		sb.WriteString("\n")
+		sb.WriteString(PolicyToTomlConfig(c.Policy, "[config.policy]", indent))
+		sb.WriteString("\n")
+	}
+
+	if !c.Timeouts.Empty() {
+		sb.WriteString("\n[config.timeouts]\n")
+		for _, entry := range []struct{ key, value string }{
+			{"clone", c.Timeouts.Clone},
+			{"fetch", c.Timeouts.Fetch},
+			{"ls_remote", c.Timeouts.LsRemote},
+			{"local", c.Timeouts.Local},
+		} {
+			if entry.value != "" {
+				sb.WriteString(formatTomlKeyValue(entry.key, entry.value, indent))
+			}
+		}
+	}
+
+	if !c.Retry.Empty() {
+		sb.WriteString("\n[config.retry]\n")
+		if c.Retry.Attempts != 0 {
+			sb.WriteString(formatTomlKeyRawValue("attempts", fmt.Sprintf("%d", c.Retry.Attempts), indent))
+		}
+		if c.Retry.Backoff != "" {
+			sb.WriteString(formatTomlKeyValue("backoff", c.Retry.Backoff, indent))
+		}
+		if c.Retry.MaxBackoff != "" {
+			sb.WriteString(formatTomlKeyValue("max_backoff", c.Retry.MaxBackoff, indent))
+		}
+		if len(c.Retry.RetryOn) != 0 {
+			retryOn := make([]string, len(c.Retry.RetryOn))
+			for index, class := range c.Retry.RetryOn {
+				retryOn[index] = string(class)
+			}
+			sb.WriteString(formatTomlKeyStringArray("retry_on", retryOn, indent))
+		}
+	}
+
+	for _, credential := range c.Credentials {
+		sb.WriteString("\n[[config.credentials]]\n")
+		sb.WriteString(formatTomlKeyValue("host", credential.Host, indent))
+		if credential.Helper != "" {
+			sb.WriteString(formatTomlKeyValue("helper", credential.Helper, indent))
+		}
+		if credential.TokenEnv != "" {
+			sb.WriteString(formatTomlKeyValue("token_env", credential.TokenEnv, indent))
+		}
+		if credential.Username != "" {
+			sb.WriteString(formatTomlKeyValue("username", credential.Username, indent))
+		}
+	}
+
+	for _, rewrite := range c.UrlRewrites {
+		sb.WriteString("\n[[config.url_rewrite]]\n")
+		sb.WriteString(formatTomlKeyValue("prefix", rewrite.Prefix, indent))
+		sb.WriteString(formatTomlKeyValue("replacement", rewrite.Replacement, indent))
+	}
+
+	return strings.TrimSpace(sb.String())
+}
+
+/*
+PolicyToTomlConfig returns a configuration string in TOML's markup language for a models.Policy within the passed table.
+*/
+func PolicyToTomlConfig(p models.Policy, table string, indent string) string {
+	var sb strings.Builder
+
+	sb.WriteString(table)
+	sb.WriteString("\n")
+
+	refKinds := make([]string, len(p.RequiredRefKinds))
+	for index, kind := range p.RequiredRefKinds {
+		refKinds[index] = string(kind)
+	}
+
+	for _, entry := range []struct {
+		key    string
+		values []string
+	}{
+		{"allowed_hosts", p.AllowedHosts},
+		{"allowed_urls", p.AllowedUrls},
+		{"required_ref_kinds", refKinds},
+		{"forbidden_paths", p.ForbiddenPaths},
+	} {
+		if len(entry.values) != 0 {
+			sb.WriteString(formatTomlKeyStringArray(entry.key, entry.values, indent))
+		}
+	}
+
+	return strings.TrimSpace(sb.String())
+}
+
 /*
 SubmoduleToTomlConfig returns a configuration string in TOML's markup language for a single models.Submodule.
 */
@@ -46,6 +178,46 @@ func SubmoduleToTomlConfig(s models.Submodule, indent string) string {
 		sb.WriteString(formatTomlKeyValue("ref", s.Ref, indent))
 	}
 
+	if s.RefKind != models.RefKindAuto {
+		sb.WriteString(formatTomlKeyValue("ref_kind", string(s.RefKind), indent))
+	}
+
+	if s.Remote != "" && s.Remote != models.DefaultRemoteName {
+		sb.WriteString(formatTomlKeyValue("remote", s.Remote, indent))
+	}
+
+	if len(s.Remotes) != 0 {
+		var remotes []string
+		for _, name := range s.RemoteNames() {
+			remotes = append(remotes, formatTomlKey(name)+" = "+formatTomlString(s.Remotes[name]))
+		}
+		sb.WriteString(formatTomlKeyRawValue("remotes", "{ "+strings.Join(remotes, ", ")+" }", indent))
+	}
+
+	if len(s.Groups) != 0 {
+		sb.WriteString(formatTomlKeyStringArray("groups", s.Groups, indent))
+	}
+	if len(s.SparsePaths) != 0 {
+		sb.WriteString(formatTomlKeyStringArray("sparse_paths", s.SparsePaths, indent))
+	}
+
+	if s.VerifySignature {
+		sb.WriteString(formatTomlKeyRawValue("verify_signature", "true", indent))
+	}
+	if s.AllowedSigners != "" {
+		sb.WriteString(formatTomlKeyValue("allowed_signers", s.AllowedSigners, indent))
+	}
+	if s.GpgHome != "" {
+		sb.WriteString(formatTomlKeyValue("gpg_home", s.GpgHome, indent))
+	}
+
+	if s.TreeHash != "" {
+		sb.WriteString(formatTomlKeyValue("tree_hash", s.TreeHash, indent))
+	}
+	if s.WorktreeHash != "" {
+		sb.WriteString(formatTomlKeyValue("worktree_hash", s.WorktreeHash, indent))
+	}
+
 	return strings.TrimSpace(sb.String())
 }
 
@@ -66,5 +238,78 @@ func SubmodulesToTomlConfig(indent string, submodules ...models.Submodule) strin
 formatTomlKeyValue formats a key and value in TOML's markup language.
 */
 func formatTomlKeyValue(k string, v string, indent string) string {
-	return fmt.Sprintf("%s%s = \"%s\"\n", indent, k, v)
+	return fmt.Sprintf("%s%s = %s\n", indent, k, formatTomlString(v))
+}
+
+/*
+tomlBareKeyPattern matches keys that can be written without quotes in TOML's markup language.
+*/
+var tomlBareKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+/*
+formatTomlKey formats a key in TOML's markup language. Keys that are not bare keys are quoted,
+so that e.g. a dot is not interpreted as a dotted key.
+*/
+func formatTomlKey(k string) string {
+	if tomlBareKeyPattern.MatchString(k) {
+		return k
+	}
+
+	return formatTomlString(k)
+}
+
+/*
+formatTomlString formats a value as a basic string in TOML's markup language, escaping backslashes,
+quotes and control characters.
+*/
+func formatTomlString(v string) string {
+	var sb strings.Builder
+
+	sb.WriteString("\"")
+	for _, r := range v {
+		switch r {
+		case '\\':
+			sb.WriteString(`\\`)
+		case '"':
+			sb.WriteString(`\"`)
+		case '\b':
+			sb.WriteString(`\b`)
+		case '\t':
+			sb.WriteString(`\t`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\f':
+			sb.WriteString(`\f`)
+		case '\r':
+			sb.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				_, _ = fmt.Fprintf(&sb, `\u%04X`, r)
+			} else {
+				sb.WriteRune(r)
+			}
+		}
+	}
+	sb.WriteString("\"")
+
+	return sb.String()
+}
+
+/*
+formatTomlKeyStringArray formats a key and an array of strings in TOML's markup language.
+*/
+func formatTomlKeyStringArray(k string, v []string, indent string) string {
+	quoted := make([]string, len(v))
+	for index, value := range v {
+		quoted[index] = formatTomlString(value)
+	}
+
+	return formatTomlKeyRawValue(k, "["+strings.Join(quoted, ", ")+"]", indent)
+}
+
+/*
+formatTomlKeyRawValue formats a key and an unquoted value (e.g. booleans and numbers) in TOML's markup language.
+*/
+func formatTomlKeyRawValue(k string, v string, indent string) string {
+	return fmt.Sprintf("%s%s = %s\n", indent, k, v)
 }
@@ -0,0 +1,119 @@
+package internal
+
+import (
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/models/urls"
+	"github.com/jeftadlvw/git-nest/utils"
+	"strings"
+)
+
+/*
+localConfig represents the settings that can be overridden in the clone-local configuration file.
+*/
+type localConfig struct {
+	Config struct {
+		UrlRewrites []models.UrlRewrite `toml:"url_rewrite"`
+	} `toml:"config"`
+}
+
+/*
+ReadLocalUrlRewrites reads the url rewrites from a clone-local configuration file.
+A non-existing file is not treated as an error.
+*/
+func ReadLocalUrlRewrites(p models.Path) ([]models.UrlRewrite, error) {
+	if !p.IsFile() {
+		return nil, nil
+	}
+
+	content, err := utils.ReadFileToStr(p)
+	if err != nil {
+		return nil, fmt.Errorf("could not read local configuration %s: %w", p, err)
+	}
+
+	config := localConfig{}
+	md, err := toml.Decode(content, &config)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse local configuration %s: %w", p, err)
+	}
+
+	undecoded := md.Undecoded()
+	if len(undecoded) != 0 {
+		return nil, fmt.Errorf("local configuration %s contains unsupported keys: %q", p, undecoded)
+	}
+
+	err = models.ValidateUrlRewrites(config.Config.UrlRewrites)
+	if err != nil {
+		return nil, fmt.Errorf("local configuration %s: %w", p, err)
+	}
+
+	return config.Config.UrlRewrites, nil
+}
+
+/*
+ParseUrlRewrites parses url rewrites formatted as 'prefix=replacement' and separated by ';', e.g. from an environment variable.
+*/
+func ParseUrlRewrites(s string) ([]models.UrlRewrite, error) {
+	var rewrites []models.UrlRewrite
+
+	for _, rule := range strings.Split(s, ";") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+
+		prefix, replacement, found := strings.Cut(rule, "=")
+		if !found {
+			return nil, fmt.Errorf("url rewrite '%s' is not formatted as prefix=replacement", urls.RedactCredentials(rule))
+		}
+
+		rewrites = append(rewrites, models.UrlRewrite{
+			Prefix:      strings.TrimSpace(prefix),
+			Replacement: strings.TrimSpace(replacement),
+		})
+	}
+
+	err := models.ValidateUrlRewrites(rewrites)
+	if err != nil {
+		return nil, err
+	}
+
+	return rewrites, nil
+}
+
+/*
+MergeUrlRewrites combines lists of url rewrites, ordered by descending precedence.
+If a prefix is defined in multiple lists, the rewrite from the first list is kept.
+*/
+func MergeUrlRewrites(rewriteLists ...[]models.UrlRewrite) []models.UrlRewrite {
+	var (
+		merged   []models.UrlRewrite
+		prefixes = make(map[string]bool)
+	)
+
+	for _, rewrites := range rewriteLists {
+		for _, rewrite := range rewrites {
+			if prefixes[rewrite.Prefix] {
+				continue
+			}
+
+			prefixes[rewrite.Prefix] = true
+			merged = append(merged, rewrite)
+		}
+	}
+
+	return merged
+}
+
+/*
+RemoteUrlMatches returns whether a repository's remote url matches a configured url, using urls.Equivalent.
+A remote url that is equivalent to the rewritten configured url, e.g. a mirror, is also treated as a match.
+*/
+func RemoteUrlMatches(remoteUrl string, configuredUrl string, sshHttpsEquivalent bool) bool {
+	if rewrittenUrl, rewritten := utils.RewriteGitUrl(configuredUrl); rewritten && urls.Equivalent(remoteUrl, rewrittenUrl, sshHttpsEquivalent) {
+		return true
+	}
+
+	return urls.Equivalent(remoteUrl, configuredUrl, sshHttpsEquivalent)
+}
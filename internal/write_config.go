@@ -5,22 +5,28 @@ import (
 	"github.com/jeftadlvw/git-nest/models"
 	"github.com/jeftadlvw/git-nest/utils"
 	"os"
+	"path"
+	"path/filepath"
 	"regexp"
 	"strings"
 )
 
-const gitExcludeDirectory = ".git/info"
-const gitExcludeFile = ".git/info/exclude"
+const gitDirectory = ".git"
+const gitExcludeDirectory = "info"
+const gitExcludeFile = "info/exclude"
 const gitExcludePrefix string = "# git-nest configuration start"
 const gitExcludeSuffix string = "# git-nest configuration end"
 const gitExcludeInfo string = `# This part influences how git handles nested modules using git-nest.
 # Do not touch except you know what you are doing!`
+const gitignoreFileName = ".gitignore"
 
 type WriteProjectConfigFilesReturn struct {
 	ConfigWritten        bool
 	GitExcludeWritten    bool
+	GitignoreWritten     bool
 	ConfigWriteError     error
 	GitExcludeWriteError error
+	GitignoreWriteError  error
 }
 
 /*
@@ -36,45 +42,212 @@ func FmtSubmodulesGitIgnore(submodules []models.Submodule) string {
 	return strings.TrimSpace(sb.String())
 }
 
+/*
+FmtSubmodulesGitignoreFiles returns a map of .gitignore files, relative to the project root, to the entries that ignore
+the passed submodules within them. Entries are anchored to the directory of their .gitignore file, so that equally
+named directories elsewhere are not ignored. If perDirectory is set, every submodule is ignored in the .gitignore file
+of its parent directory, else in the project root's one.
+*/
+func FmtSubmodulesGitignoreFiles(submodules []models.Submodule, perDirectory bool) map[string]string {
+	entries := map[string][]string{}
+	for _, submodule := range submodules {
+		gitignoreFile := gitignoreFileName
+		entry := "/" + submodule.Path.UnixString()
+
+		if perDirectory {
+			parent := submodule.Path.Parent()
+			gitignoreFile = path.Join(parent.UnixString(), gitignoreFileName)
+			entry = "/" + submodule.Path.Base()
+		}
+
+		entries[gitignoreFile] = append(entries[gitignoreFile], entry)
+	}
+
+	formatted := map[string]string{}
+	for gitignoreFile, fileEntries := range entries {
+		formatted[gitignoreFile] = strings.Join(fileEntries, "\n")
+	}
+
+	return formatted
+}
+
 /*
 WriteSubmoduleIgnoreConfig uses internal.FmtSubmodulesGitIgnore, wraps it with some user information and writes that
 into the passed file. Pre-existing configuration is replaced using utils.StringInsertAtFirst.
 */
 func WriteSubmoduleIgnoreConfig(p models.Path, modules []models.Submodule) error {
-	submoduleGitExcludeFmt := FmtSubmodulesGitIgnore(modules)
-	submoduleGitExcludePart := gitExcludePrefix + "\n" + gitExcludeInfo + "\n"
-
-	if submoduleGitExcludeFmt != "" {
-		submoduleGitExcludePart = submoduleGitExcludePart + submoduleGitExcludeFmt + "\n"
+	existingContent, err := utils.ReadFileToStr(p)
+	if err != nil {
+		return err
 	}
-	submoduleGitExcludePart = submoduleGitExcludePart + gitExcludeSuffix
 
-	fileContent := ""
+	return writeSubmoduleIgnoreBlock(p, existingContent, FmtSubmodulesGitIgnore(modules))
+}
+
+/*
+RemoveSubmoduleIgnoreConfig removes the git-nest block from the passed ignore file, if it contains one.
+Files that are empty afterward are deleted.
+*/
+func RemoveSubmoduleIgnoreConfig(p models.Path) error {
+	if !p.IsFile() {
+		return nil
+	}
 
 	existingContent, err := utils.ReadFileToStr(p)
 	if err != nil {
 		return err
 	}
 
-	localFileContent, localErr := utils.StringInsertAtFirst(existingContent, submoduleGitExcludePart, gitExcludePrefix, gitExcludeSuffix)
+	// no block to remove
+	if !strings.Contains(existingContent, gitExcludePrefix) || !strings.Contains(existingContent, gitExcludeSuffix) {
+		return nil
+	}
+
+	// join content around the block without leaving additional blank lines
+	before, _, _ := strings.Cut(existingContent, gitExcludePrefix)
+	_, after, _ := strings.Cut(existingContent, gitExcludeSuffix)
+	before, after = strings.TrimSpace(before), strings.TrimSpace(after)
+
+	fileContent := before
+	if before != "" && after != "" {
+		fileContent = before + "\n\n" + after
+	} else if after != "" {
+		fileContent = after
+	}
+
+	if fileContent == "" {
+		return os.Remove(p.String())
+	}
+
+	return utils.WriteStrToFileAtomic(p, fileContent+"\n")
+}
+
+/*
+WriteSubmoduleGitignoreConfig writes the git-nest blocks into the .gitignore files returned by FmtSubmodulesGitignoreFiles,
+creating them if required. Blocks in other .gitignore files within the project are removed, e.g. after a module
+has been removed or GitignoreLocation has been changed.
+*/
+func WriteSubmoduleGitignoreConfig(projectRoot models.Path, modules []models.Submodule, perDirectory bool) error {
+	gitignoreEntries := FmtSubmodulesGitignoreFiles(modules, perDirectory)
+
+	for gitignoreFile, entries := range gitignoreEntries {
+		gitignorePath := projectRoot.SJoin(gitignoreFile)
+
+		// per-directory files might be written before their module has been cloned
+		gitignoreDir := gitignorePath.Parent()
+		err := os.MkdirAll(gitignoreDir.String(), os.ModePerm)
+		if err != nil {
+			return fmt.Errorf("cannot create directory %s: %w", gitignoreDir, err)
+		}
+
+		existingContent := ""
+		if gitignorePath.Exists() {
+			localExistingContent, err := utils.ReadFileToStr(gitignorePath)
+			if err != nil {
+				return err
+			}
+			existingContent = localExistingContent
+		}
+
+		err = writeSubmoduleIgnoreBlock(gitignorePath, existingContent, entries)
+		if err != nil {
+			return fmt.Errorf("cannot write %s: %w", gitignorePath, err)
+		}
+	}
+
+	return removeStaleGitignoreBlocks(projectRoot, gitignoreEntries)
+}
+
+/*
+RemoveSubmoduleGitignoreConfig removes the git-nest blocks from all .gitignore files within the project.
+*/
+func RemoveSubmoduleGitignoreConfig(projectRoot models.Path) error {
+	return removeStaleGitignoreBlocks(projectRoot, nil)
+}
+
+/*
+FindManagedGitignoreFiles returns all .gitignore files within the project, relative to the project root,
+that contain a git-nest block. Within git repositories, only tracked and not ignored files are searched.
+Otherwise, only the project root's .gitignore file is considered.
+*/
+func FindManagedGitignoreFiles(projectRoot models.Path) ([]string, error) {
+	candidates, err := utils.GetGitListFiles(projectRoot, ":(glob)**/"+gitignoreFileName)
+	if err != nil {
+		candidates = []string{gitignoreFileName}
+	}
+
+	var managedFiles []string
+	for _, candidate := range candidates {
+		candidatePath := projectRoot.SJoin(candidate)
+		if !candidatePath.IsFile() {
+			continue
+		}
+
+		content, err := utils.ReadFileToStr(candidatePath)
+		if err != nil {
+			return nil, err
+		}
+
+		if strings.Contains(content, gitExcludePrefix) {
+			managedFiles = append(managedFiles, path.Clean(filepath.ToSlash(candidate)))
+		}
+	}
+
+	return managedFiles, nil
+}
+
+func removeStaleGitignoreBlocks(projectRoot models.Path, keep map[string]string) error {
+	managedFiles, err := FindManagedGitignoreFiles(projectRoot)
+	if err != nil {
+		return fmt.Errorf("cannot find .gitignore files: %w", err)
+	}
+
+	for _, managedFile := range managedFiles {
+		if _, ok := keep[managedFile]; ok {
+			continue
+		}
+
+		err = RemoveSubmoduleIgnoreConfig(projectRoot.SJoin(managedFile))
+		if err != nil {
+			return fmt.Errorf("cannot clean up %s: %w", managedFile, err)
+		}
+	}
+
+	return nil
+}
+
+/*
+writeSubmoduleIgnoreBlock wraps formatted entries with some user information and writes them into the passed file.
+Pre-existing configuration is replaced using utils.StringInsertAtFirst.
+*/
+func writeSubmoduleIgnoreBlock(p models.Path, existingContent string, entries string) error {
+	submoduleIgnorePart := gitExcludePrefix + "\n" + gitExcludeInfo + "\n"
+
+	if entries != "" {
+		submoduleIgnorePart = submoduleIgnorePart + entries + "\n"
+	}
+	submoduleIgnorePart = submoduleIgnorePart + gitExcludeSuffix
+
+	fileContent := ""
+
+	localFileContent, localErr := utils.StringInsertAtFirst(existingContent, submoduleIgnorePart, gitExcludePrefix, gitExcludeSuffix)
 	if localErr != nil {
 		// error during insert (which should only happen if delimiters could not be found)
-		// then append gitExcludePart to existing content
-		fileContent = strings.TrimSpace(existingContent) + "\n\n" + submoduleGitExcludePart + "\n"
+		// then append submoduleIgnorePart to existing content
+		fileContent = strings.TrimSpace(existingContent) + "\n\n" + submoduleIgnorePart + "\n"
 	} else {
 		fileContent = strings.TrimSpace(localFileContent) + "\n"
 	}
 
-	err = utils.WriteStrToFile(p, fileContent)
-	return err
+	return utils.WriteStrToFileAtomic(p, strings.TrimLeft(fileContent, "\n"))
 }
 
 /*
-WriteNestConfig writes models.Submodule configuration into the git-nest configuration file, preserving the first [config] section.
+WriteNestConfig writes models.Submodule configuration into the git-nest configuration file, preserving all other
+tables (e.g. [config] and its sub-tables) regardless of where they are placed within the file.
 */
 func WriteNestConfig(p models.Path, modules []models.Submodule) error {
 	existingContent := ""
-	existingConfig := ""
 
 	if p.Empty() {
 		return fmt.Errorf("cannot write to empty path")
@@ -85,33 +258,21 @@ func WriteNestConfig(p models.Path, modules []models.Submodule) error {
 	}
 
 	if p.IsFile() {
-		localExistingConfig, err := utils.ReadFileToStr(p)
+		existingConfig, err := utils.ReadFileToStr(p)
 		if err != nil {
 			return fmt.Errorf("cannot read existing config: %w", err)
 		}
-		existingConfig = localExistingConfig
-
-		// append '[[submodule]]' to in-memory config for regex to find existing config;
-		// even if that header already exists, it'll be removed anyway by later processing
-		existingConfig = existingConfig + "[[submodule]]"
-	}
-
-	// find any existing config section
-	re := regexp.MustCompile(`\[config](?s:(.*?))\[\[submodule]]`)
-	matches := re.FindStringSubmatch(existingConfig)
-
-	if len(matches) > 1 {
-		existingContent = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(matches[0]), "[[submodule]]"))
+		existingContent = nonSubmoduleTables(existingConfig)
 	}
 
 	submodulesConfig := SubmodulesToTomlConfig("  ", modules...)
 
-	if existingContent != "" {
+	if existingContent != "" && submodulesConfig != "" {
 		existingContent = existingContent + "\n\n"
 	}
 
 	finalConfigContent := existingContent + submodulesConfig + "\n"
-	err := utils.WriteStrToFile(p, finalConfigContent)
+	err := utils.WriteStrToFileAtomic(p, finalConfigContent)
 	if err != nil {
 		return fmt.Errorf("cannot write 'nestmodules.toml': %w", err)
 	}
@@ -119,6 +280,61 @@ func WriteNestConfig(p models.Path, modules []models.Submodule) error {
 	return nil
 }
 
+/*
+tomlTableHeaderPattern matches the header of a table or of an array of tables in TOML's markup language
+and captures its name.
+*/
+var tomlTableHeaderPattern = regexp.MustCompile(`^\s*\[\[?\s*([A-Za-z0-9_.\-]+)\s*]]?\s*(#.*)?$`)
+
+/*
+nonSubmoduleTables returns the content of a configuration file without its [[submodule]] tables, which are
+rewritten by WriteNestConfig. Every other table is kept as-is and in order.
+*/
+func nonSubmoduleTables(content string) string {
+	var kept []string
+	var section []string
+	inSubmodule := false
+	inMultilineString := false
+
+	flush := func() {
+		text := strings.TrimRight(strings.TrimLeft(strings.Join(section, "\n"), "\n"), " \t\n")
+		if !inSubmodule && strings.TrimSpace(text) != "" {
+			kept = append(kept, text)
+		}
+		section = nil
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n") {
+		matches := tomlTableHeaderPattern.FindStringSubmatch(line)
+		if !inMultilineString && matches != nil {
+			flush()
+			inSubmodule = matches[1] == "submodule" || strings.HasPrefix(matches[1], "submodule.")
+		}
+
+		// headers within multi-line strings are not headers
+		if !strings.HasPrefix(strings.TrimSpace(line), "#") && (strings.Count(line, `"""`)+strings.Count(line, "'''"))%2 == 1 {
+			inMultilineString = !inMultilineString
+		}
+
+		section = append(section, line)
+	}
+	flush()
+
+	return strings.Join(kept, "\n\n")
+}
+
+/*
+GitCommonDirFromContext returns the common git directory of a models.NestContext.
+Falls back to the .git directory at the repository root if the context does not contain it.
+*/
+func GitCommonDirFromContext(c models.NestContext) models.Path {
+	if !c.GitCommonDir.Empty() {
+		return c.GitCommonDir
+	}
+
+	return c.GitRepositoryRoot.SJoin(gitDirectory)
+}
+
 /*
 WriteProjectConfigFiles is a total wrapper function for internal.WriteSubmoduleIgnoreConfig and internal.WriteNestConfig,
 calling both functions based on the passed models.NestContext.
@@ -148,11 +364,14 @@ func WriteProjectConfigFiles(c models.NestContext) (WriteProjectConfigFilesRetur
 	}
 	r.ConfigWriteError = err
 
+	config := c.Config.Config
+
 	// write to git_exclude if project is a git repository
 	if c.IsGitInstalled && c.IsGitRepository {
 
 		// create gitExcludeDirectory if it does not exist
-		gitExcludeDirectoryPath := c.GitRepositoryRoot.SJoin(gitExcludeDirectory)
+		gitDir := GitCommonDirFromContext(c)
+		gitExcludeDirectoryPath := gitDir.SJoin(gitExcludeDirectory)
 		if gitExcludeDirectoryPath.IsFile() {
 			r.GitExcludeWriteError = fmt.Errorf("%s is a file", gitExcludeDirectoryPath)
 		}
@@ -165,7 +384,15 @@ func WriteProjectConfigFiles(c models.NestContext) (WriteProjectConfigFilesRetur
 		}
 
 		if gitExcludeDirectoryPath.IsDir() {
-			err = WriteSubmoduleIgnoreConfig(c.GitRepositoryRoot.SJoin(gitExcludeFile), c.Config.Submodules)
+			gitExcludeFilePath := gitDir.SJoin(gitExcludeFile)
+
+			// the exclude file is cleaned up if another ignore mode is used
+			if config.UsesGitExclude() {
+				err = WriteSubmoduleIgnoreConfig(gitExcludeFilePath, c.Config.Submodules)
+			} else {
+				err = RemoveSubmoduleIgnoreConfig(gitExcludeFilePath)
+			}
+
 			if err == nil {
 				r.GitExcludeWritten = true
 			} else {
@@ -174,5 +401,18 @@ func WriteProjectConfigFiles(c models.NestContext) (WriteProjectConfigFilesRetur
 		}
 	}
 
+	// .gitignore files are tracked and therefore work without git being installed
+	if config.UsesGitignore() {
+		err = WriteSubmoduleGitignoreConfig(c.ProjectRoot, c.Config.Submodules, config.GitignorePerDirectory())
+	} else {
+		err = RemoveSubmoduleGitignoreConfig(c.ProjectRoot)
+	}
+
+	if err == nil {
+		r.GitignoreWritten = config.UsesGitignore()
+	} else {
+		r.GitignoreWriteError = err
+	}
+
 	return r, nil
 }
@@ -0,0 +1,69 @@
+package context
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/internal"
+	"github.com/jeftadlvw/git-nest/internal/constants"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/utils"
+	"os"
+)
+
+type CreateConfigFile struct {
+	Context      *models.NestContext
+	ProjectRoot  models.Path
+	UseConfigDir bool
+	Config       models.Config
+}
+
+func (m CreateConfigFile) Migrate() error {
+	if m.Context == nil {
+		return fmt.Errorf("migration contained nil context")
+	}
+
+	if !m.ProjectRoot.IsDir() {
+		return fmt.Errorf("%s is not a directory", m.ProjectRoot)
+	}
+
+	configFile := m.ProjectRoot.SJoin(constants.ConfigFileName)
+	if m.UseConfigDir {
+		configFile = m.ProjectRoot.SJoin(constants.ConfigSubDirFileName)
+	}
+
+	// either configuration file location makes a directory a project root
+	for _, existingConfigFile := range []models.Path{m.ProjectRoot.SJoin(constants.ConfigFileName), m.ProjectRoot.SJoin(constants.ConfigSubDirFileName)} {
+		if existingConfigFile.Exists() {
+			return fmt.Errorf("%s already exists", existingConfigFile)
+		}
+	}
+
+	configDir := configFile.Parent()
+	err := os.MkdirAll(configDir.String(), os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("cannot create directory %s: %w", configDir, err)
+	}
+
+	configStr := internal.ConfigToTomlConfig(m.Config, "") + "\n"
+	err = utils.WriteStrToFileAtomic(configFile, configStr)
+	if err != nil {
+		return fmt.Errorf("cannot write configuration file: %w", err)
+	}
+
+	// point context to the new project
+	m.Context.ProjectRoot = m.ProjectRoot
+	m.Context.ConfigFile = configFile
+	m.Context.ConfigFileExists = true
+	m.Context.Config = models.NestConfig{Config: m.Config}
+	m.Context.Checksums.ConfigurationFile = utils.CalculateChecksumS(configStr)
+	m.Context.IsGitRepository = false
+	if m.Context.IsGitInstalled {
+		gitRoot, gitCommonDir, err := utils.GetGitRepositoryDirectories(m.ProjectRoot)
+		if err == nil {
+			m.Context.GitRepositoryRoot = gitRoot
+			m.Context.GitCommonDir = gitCommonDir
+			m.Context.IsGitRepository = m.Context.GitRepositoryRoot.Equals(m.ProjectRoot)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,29 @@
+package context
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/internal"
+	"github.com/jeftadlvw/git-nest/models"
+)
+
+/*
+KeepModuleDirectory records that the directory of a removed nested module was kept on purpose, so that it is not
+pruned once RecordState records its removal.
+*/
+type KeepModuleDirectory struct {
+	Context *models.NestContext
+	Path    models.Path
+}
+
+func (m KeepModuleDirectory) Migrate() error {
+	if m.Context == nil {
+		return fmt.Errorf("migration contained nil context")
+	}
+
+	err := internal.KeepStateModule(*m.Context, m.Path)
+	if err != nil {
+		return fmt.Errorf("error recording kept directory: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,29 @@
+package context
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/internal"
+	"github.com/jeftadlvw/git-nest/models"
+)
+
+/*
+RecordState records the nested modules of the context in the state file. The recorded changes are stored in Changes, if set.
+*/
+type RecordState struct {
+	Context *models.NestContext
+	Command string
+	Changes *[]internal.StateChange
+}
+
+func (m RecordState) Migrate() error {
+	changes, err := internal.RecordState(*m.Context, m.Command)
+	if err != nil {
+		return fmt.Errorf("error recording state: %w", err)
+	}
+
+	if m.Changes != nil {
+		*m.Changes = changes
+	}
+
+	return nil
+}
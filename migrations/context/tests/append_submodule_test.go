@@ -6,6 +6,7 @@ import (
 	"github.com/jeftadlvw/git-nest/migrations/context"
 	"github.com/jeftadlvw/git-nest/models"
 	"github.com/jeftadlvw/git-nest/models/urls"
+	"reflect"
 	"slices"
 	"testing"
 )
@@ -44,7 +45,7 @@ func TestAppendSubmodule(t *testing.T) {
 			if !tc.err && err != nil {
 				t.Fatalf("unexpected error: %s", err)
 			}
-			if !tc.err && !slices.Contains(tc.context.Config.Submodules, tc.submodule) {
+			if !tc.err && !slices.ContainsFunc(tc.context.Config.Submodules, func(s models.Submodule) bool { return reflect.DeepEqual(s, tc.submodule) }) {
 				t.Fatalf("context did not contain submodule")
 			}
 		})
@@ -0,0 +1,91 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/interfaces"
+	"github.com/jeftadlvw/git-nest/internal/constants"
+	"github.com/jeftadlvw/git-nest/migrations/context"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/utils"
+	"reflect"
+	"testing"
+)
+
+func TestCreateConfigFileImplementsInterface(t *testing.T) {
+	var _ interfaces.Migration = (*context.CreateConfigFile)(nil)
+}
+
+func TestCreateConfigFile(t *testing.T) {
+	tests := []struct {
+		context        *models.NestContext
+		useConfigDir   bool
+		existingConfig bool
+		nonExistingDir bool
+		err            bool
+	}{
+		{nil, false, false, false, true},
+		{&models.NestContext{}, false, false, true, true},
+		{&models.NestContext{}, false, true, false, true},
+		{&models.NestContext{}, true, true, false, true},
+		{&models.NestContext{}, false, false, false, false},
+		{&models.NestContext{}, true, false, false, false},
+		{&models.NestContext{Config: models.NestConfig{Submodules: []models.Submodule{{Path: "foo"}}}}, false, false, false, false},
+	}
+
+	for index, tc := range tests {
+		t.Run(fmt.Sprintf("TestCreateConfigFile-%d", index+1), func(t *testing.T) {
+			projectRoot := models.Path(t.TempDir())
+			if tc.nonExistingDir {
+				projectRoot = projectRoot.SJoin("foo")
+			}
+
+			configFile := projectRoot.SJoin(constants.ConfigFileName)
+			if tc.useConfigDir {
+				configFile = projectRoot.SJoin(constants.ConfigSubDirFileName)
+			}
+
+			if tc.existingConfig {
+				err := utils.WriteStrToFile(projectRoot.SJoin(constants.ConfigFileName), "")
+				if err != nil {
+					t.Fatalf("error writing existing config: %s", err)
+				}
+			}
+
+			config := models.Config{AllowDuplicateOrigins: true}
+			err := context.CreateConfigFile{
+				Context:      tc.context,
+				ProjectRoot:  projectRoot,
+				UseConfigDir: tc.useConfigDir,
+				Config:       config,
+			}.Migrate()
+
+			if tc.err && err == nil {
+				t.Fatalf("no error, but expected one")
+			}
+			if !tc.err && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if tc.err {
+				return
+			}
+
+			if !configFile.IsFile() {
+				t.Fatalf("configuration file %s was not created", configFile)
+			}
+			if tc.context.ConfigFile != configFile || !tc.context.ConfigFileExists || tc.context.ProjectRoot != projectRoot {
+				t.Fatalf("context was not updated")
+			}
+			if !reflect.DeepEqual(tc.context.Config.Config, config) || len(tc.context.Config.Submodules) != 0 {
+				t.Fatalf("context configuration was not replaced")
+			}
+
+			checksum, err := utils.CalculateChecksumF(configFile)
+			if err != nil {
+				t.Fatalf("error calculating checksum: %s", err)
+			}
+			if tc.context.Checksums.ConfigurationFile != checksum {
+				t.Fatalf("context checksum does not match configuration file")
+			}
+		})
+	}
+}
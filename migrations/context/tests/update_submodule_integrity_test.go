@@ -0,0 +1,54 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/interfaces"
+	"github.com/jeftadlvw/git-nest/migrations/context"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/test_env"
+	"testing"
+)
+
+func TestUpdateSubmoduleIntegrityImplementsInterface(t *testing.T) {
+	var _ interfaces.Migration = (*context.UpdateSubmoduleIntegrity)(nil)
+}
+
+func TestUpdateSubmoduleIntegrity(t *testing.T) {
+	root := models.Path(t.TempDir())
+	err := test_env.CreateLocalRepository(root.SJoin("module"), "first")
+	if err != nil {
+		t.Fatalf("error creating module repository: %s", err)
+	}
+
+	tests := []struct {
+		path models.Path
+		err  bool
+	}{
+		{"module", false},
+		{"other", true},
+	}
+
+	for index, tc := range tests {
+		t.Run(fmt.Sprintf("TestUpdateSubmoduleIntegrity-%d", index+1), func(t *testing.T) {
+			mockContext := models.NestContext{ProjectRoot: root}
+			mockContext.Config.Submodules = []models.Submodule{{Path: "module"}}
+
+			err := context.UpdateSubmoduleIntegrity{
+				Context:       &mockContext,
+				SubmodulePath: tc.path,
+			}.Migrate()
+
+			if tc.err && err == nil {
+				t.Fatalf("no error, but expected one")
+			}
+			if !tc.err && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			submodule := mockContext.Config.Submodules[0]
+			if (submodule.TreeHash != "" && submodule.WorktreeHash != "") == tc.err {
+				t.Fatalf("unexpected integrity hashes: %s, %s", submodule.TreeHash, submodule.WorktreeHash)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package context
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/migrations/submodules"
+	"github.com/jeftadlvw/git-nest/models"
+)
+
+/*
+UpdateSubmoduleIntegrity records the integrity hashes of the nested module at SubmodulePath, see
+submodules.UpdateIntegrity. The module is looked up when the migration runs, so that it can be appended
+by preceding migrations.
+*/
+type UpdateSubmoduleIntegrity struct {
+	Context       *models.NestContext
+	SubmodulePath models.Path
+}
+
+func (m UpdateSubmoduleIntegrity) Migrate() error {
+	if m.Context == nil {
+		return fmt.Errorf("migration contained nil context")
+	}
+
+	for index := range m.Context.Config.Submodules {
+		submodule := &m.Context.Config.Submodules[index]
+		if submodule.Path == m.SubmodulePath {
+			return submodules.UpdateIntegrity{Submodule: submodule, Path: m.Context.ProjectRoot.Join(submodule.Path)}.Migrate()
+		}
+	}
+
+	return fmt.Errorf("no nested module at %s", m.SubmodulePath)
+}
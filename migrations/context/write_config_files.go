@@ -17,6 +17,8 @@ func (m WriteConfigFiles) Migrate() error {
 			err = r.ConfigWriteError
 		} else if r.GitExcludeWriteError != nil {
 			err = r.GitExcludeWriteError
+		} else if r.GitignoreWriteError != nil {
+			err = r.GitignoreWriteError
 		}
 	}
 
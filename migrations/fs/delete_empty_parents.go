@@ -0,0 +1,57 @@
+package fs
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jeftadlvw/git-nest/internal"
+	"github.com/jeftadlvw/git-nest/models"
+	"os"
+)
+
+/*
+DeleteEmptyParents deletes the parent directories of a path that are empty, e.g. after a directory was moved away.
+Parents are deleted from the innermost outwards, stopping at the first one that is not empty and at Root,
+which is never deleted.
+*/
+type DeleteEmptyParents struct {
+	Path models.Path
+	Root models.Path
+}
+
+func (m DeleteEmptyParents) Migrate() error {
+	if m.Path.Empty() || m.Root.Empty() {
+		return errors.New("path is empty")
+	}
+
+	if internal.PathOutsideRoot(m.Root, m.Path) {
+		return fmt.Errorf("%s is not located within %s", m.Path, m.Root)
+	}
+
+	relative, err := m.Root.Relative(m.Path)
+	if err != nil {
+		return fmt.Errorf("could not find path relative to %s: %w", m.Root, err)
+	}
+
+	// walk up the relative path, so that nothing outside of root is touched
+	for parent := relative.Parent(); parent.String() != "." && parent.String() != ""; parent = parent.Parent() {
+		directory := m.Root.Join(parent)
+
+		entries, err := os.ReadDir(directory.String())
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("could not read directory %s: %w", directory, err)
+		}
+		if len(entries) != 0 {
+			return nil
+		}
+
+		err = os.Remove(directory.String())
+		if err != nil {
+			return fmt.Errorf("could not delete directory %s: %w", directory, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,29 @@
+package fs
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jeftadlvw/git-nest/models"
+	"os"
+)
+
+type DeleteFile struct {
+	Path models.Path
+}
+
+func (m DeleteFile) Migrate() error {
+	if m.Path.Empty() {
+		return errors.New("path is empty")
+	}
+
+	if m.Path.IsDir() {
+		return fmt.Errorf("%s is a directory", m.Path)
+	}
+
+	err := os.Remove(m.Path.String())
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("could not delete file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package fs
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jeftadlvw/git-nest/models"
+	"os"
+)
+
+type MoveDirectory struct {
+	From models.Path
+	To   models.Path
+}
+
+func (m MoveDirectory) Migrate() error {
+	if m.From.Empty() || m.To.Empty() {
+		return errors.New("path is empty")
+	}
+
+	if m.From.AtRoot() || m.To.AtRoot() {
+		return errors.New("cannot move system root directory")
+	}
+
+	if !m.From.IsDir() {
+		return fmt.Errorf("%s is not a directory", m.From)
+	}
+
+	if m.To.Exists() {
+		return fmt.Errorf("%s already exists", m.To)
+	}
+
+	// create parent directories of the target, as rename does not do that
+	toParent := m.To.Parent()
+	err := os.MkdirAll(toParent.String(), os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("could not create directory %s: %w", toParent, err)
+	}
+
+	// rename is atomic on the same filesystem, so the module is either
+	// completely at its old or at its new location
+	err = os.Rename(m.From.String(), m.To.String())
+	if err != nil {
+		return fmt.Errorf("could not move directory: %w", err)
+	}
+
+	return nil
+}
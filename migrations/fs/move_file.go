@@ -0,0 +1,34 @@
+package fs
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jeftadlvw/git-nest/models"
+	"os"
+)
+
+type MoveFile struct {
+	From models.Path
+	To   models.Path
+}
+
+func (m MoveFile) Migrate() error {
+	if m.From.Empty() || m.To.Empty() {
+		return errors.New("path is empty")
+	}
+
+	if !m.From.IsFile() {
+		return fmt.Errorf("%s is not a file", m.From)
+	}
+
+	if m.To.Exists() {
+		return fmt.Errorf("%s already exists", m.To)
+	}
+
+	err := os.Rename(m.From.String(), m.To.String())
+	if err != nil {
+		return fmt.Errorf("could not move file: %w", err)
+	}
+
+	return nil
+}
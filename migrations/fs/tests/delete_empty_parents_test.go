@@ -0,0 +1,88 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/interfaces"
+	"github.com/jeftadlvw/git-nest/migrations/fs"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/utils"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDeleteEmptyParentsImplementsInterface(t *testing.T) {
+	var _ interfaces.Migration = (*fs.DeleteEmptyParents)(nil)
+}
+
+func TestDeleteEmptyParents(t *testing.T) {
+	tests := []struct {
+		path        string
+		directories []string
+		files       []string
+		remaining   []string
+		deleted     []string
+		outside     bool
+		err         bool
+	}{
+		{"", nil, nil, nil, nil, false, true},
+		{"lib", nil, nil, nil, nil, false, false},
+		{"vendor/lib", []string{"vendor"}, nil, nil, []string{"vendor"}, false, false},
+		{"a/b/c/lib", []string{"a/b/c"}, nil, nil, []string{"a"}, false, false},
+		{"a/b/lib", []string{"a/b"}, []string{"a/file"}, []string{"a"}, []string{"a/b"}, false, false},
+		{"a/b/lib", []string{"a/b/other"}, nil, []string{"a/b/other"}, nil, false, false},
+		{"a/lib", nil, nil, nil, nil, false, false},
+		{"lib", nil, nil, nil, nil, true, true},
+	}
+
+	for index, tc := range tests {
+		t.Run(fmt.Sprintf("TestDeleteEmptyParents-%d", index+1), func(t *testing.T) {
+			t.Parallel()
+			tempDir := models.Path(t.TempDir())
+
+			for _, directory := range tc.directories {
+				err := os.MkdirAll(filepath.Join(string(tempDir), directory), os.ModePerm)
+				if err != nil {
+					t.Fatalf("error creating directory: %s", err)
+				}
+			}
+			for _, file := range tc.files {
+				err := utils.WriteStrToFile(tempDir.SJoin(file), "")
+				if err != nil {
+					t.Fatalf("error writing test file: %s", err)
+				}
+			}
+
+			p := models.Path(tc.path)
+			if !p.Empty() {
+				p = tempDir.Join(p)
+			}
+			root := tempDir
+			if tc.outside {
+				root = tempDir.SJoin("root")
+			}
+
+			err := fs.DeleteEmptyParents{Path: p, Root: root}.Migrate()
+			if tc.err && err == nil {
+				t.Fatalf("no error, but expected one")
+			}
+			if !tc.err && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			for _, remaining := range tc.remaining {
+				if directory := tempDir.SJoin(remaining); !directory.IsDir() {
+					t.Fatalf("directory %s was deleted", remaining)
+				}
+			}
+			for _, deleted := range tc.deleted {
+				if directory := tempDir.SJoin(deleted); directory.Exists() {
+					t.Fatalf("directory %s was not deleted", deleted)
+				}
+			}
+			if !tempDir.IsDir() {
+				t.Fatalf("root directory was deleted")
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/interfaces"
+	"github.com/jeftadlvw/git-nest/migrations/fs"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/utils"
+	"os"
+	"testing"
+)
+
+func TestDeleteFileImplementsInterface(t *testing.T) {
+	var _ interfaces.Migration = (*fs.DeleteFile)(nil)
+}
+
+func TestDeleteFile(t *testing.T) {
+	tests := []struct {
+		path       string
+		createFile bool
+		createDir  bool
+		err        bool
+	}{
+		{"", false, false, true},
+		{"foo", false, true, true},
+		{"foo", false, false, false},
+		{"foo", true, false, false},
+	}
+
+	for index, tc := range tests {
+		t.Run(fmt.Sprintf("TestDeleteFile-%d", index+1), func(t *testing.T) {
+			t.Parallel()
+			tempDir := models.Path(t.TempDir())
+
+			path := models.Path(tc.path)
+			if !path.Empty() {
+				path = tempDir.Join(path)
+			}
+
+			if tc.createFile {
+				err := utils.WriteStrToFile(path, "")
+				if err != nil {
+					t.Fatalf("error writing test file: %s", err)
+				}
+			}
+
+			if tc.createDir {
+				err := os.Mkdir(path.String(), os.ModePerm)
+				if err != nil {
+					t.Fatalf("error creating test directory: %s", err)
+				}
+			}
+
+			err := fs.DeleteFile{Path: path}.Migrate()
+			if tc.err && err == nil {
+				t.Fatalf("no error, but expected one")
+			}
+			if !tc.err && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if tc.err {
+				return
+			}
+
+			if path.Exists() {
+				t.Fatalf("%s still exists", path)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/interfaces"
+	"github.com/jeftadlvw/git-nest/migrations/fs"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/utils"
+	"os"
+	"testing"
+)
+
+func TestMoveDirectoryImplementsInterface(t *testing.T) {
+	var _ interfaces.Migration = (*fs.MoveDirectory)(nil)
+}
+
+func TestMoveDirectory(t *testing.T) {
+	const testFile = "testfile"
+
+	tests := []struct {
+		from       string
+		to         string
+		createFrom bool
+		createTo   bool
+		fromIsFile bool
+		err        bool
+	}{
+		{"", "", false, false, false, true},
+		{"foo", "", true, false, false, true},
+		{"", "bar", false, false, false, true},
+		{"/", "bar", false, false, false, true},
+		{"foo", "bar", false, false, false, true},
+		{"foo", "bar", true, true, false, true},
+		{"foo", "bar", true, false, true, true},
+		{"foo", "bar", true, false, false, false},
+		{"foo", "nested/dir/bar", true, false, false, false},
+	}
+
+	for index, tc := range tests {
+		t.Run(fmt.Sprintf("TestMoveDirectory-%d", index+1), func(t *testing.T) {
+			t.Parallel()
+			tempDir := models.Path(t.TempDir())
+
+			from := models.Path(tc.from)
+			to := models.Path(tc.to)
+			if !from.Empty() && !from.AtRoot() {
+				from = tempDir.Join(from)
+			}
+			if !to.Empty() {
+				to = tempDir.Join(to)
+			}
+
+			if tc.createFrom {
+				if tc.fromIsFile {
+					err := utils.WriteStrToFile(from, "")
+					if err != nil {
+						t.Fatalf("error writing test file: %s", err)
+					}
+				} else {
+					err := os.Mkdir(from.String(), os.ModePerm)
+					if err != nil {
+						t.Fatalf("error creating test directory: %s", err)
+					}
+
+					err = utils.WriteStrToFile(from.SJoin(testFile), "")
+					if err != nil {
+						t.Fatalf("error writing test file: %s", err)
+					}
+				}
+			}
+
+			if tc.createTo {
+				err := os.Mkdir(to.String(), os.ModePerm)
+				if err != nil {
+					t.Fatalf("error creating test directory: %s", err)
+				}
+			}
+
+			err := fs.MoveDirectory{
+				From: from,
+				To:   to,
+			}.Migrate()
+
+			if tc.err && err == nil {
+				t.Fatalf("no error, but expected one")
+			}
+			if !tc.err && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !tc.err {
+				movedFile := to.SJoin(testFile)
+				if from.Exists() || !movedFile.IsFile() {
+					t.Fatalf("directory was not moved from %s to %s", from, to)
+				}
+			}
+		})
+	}
+}
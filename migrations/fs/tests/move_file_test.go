@@ -0,0 +1,94 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/interfaces"
+	"github.com/jeftadlvw/git-nest/migrations/fs"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/utils"
+	"os"
+	"testing"
+)
+
+func TestMoveFileImplementsInterface(t *testing.T) {
+	var _ interfaces.Migration = (*fs.MoveFile)(nil)
+}
+
+func TestMoveFile(t *testing.T) {
+	const content = "hello world"
+
+	tests := []struct {
+		from       string
+		to         string
+		createFrom bool
+		fromIsDir  bool
+		createTo   bool
+		err        bool
+	}{
+		{"", "", false, false, false, true},
+		{"foo", "", true, false, false, true},
+		{"", "bar", false, false, false, true},
+		{"foo", "bar", false, false, false, true},
+		{"foo", "bar", true, true, false, true},
+		{"foo", "bar", true, false, true, true},
+		{"foo", "bar", true, false, false, false},
+	}
+
+	for index, tc := range tests {
+		t.Run(fmt.Sprintf("TestMoveFile-%d", index+1), func(t *testing.T) {
+			t.Parallel()
+			tempDir := models.Path(t.TempDir())
+
+			from := models.Path(tc.from)
+			to := models.Path(tc.to)
+			if !from.Empty() {
+				from = tempDir.Join(from)
+			}
+			if !to.Empty() {
+				to = tempDir.Join(to)
+			}
+
+			if tc.createFrom {
+				var err error
+				if tc.fromIsDir {
+					err = os.Mkdir(from.String(), os.ModePerm)
+				} else {
+					err = utils.WriteStrToFile(from, content)
+				}
+				if err != nil {
+					t.Fatalf("error creating test file: %s", err)
+				}
+			}
+
+			if tc.createTo {
+				err := utils.WriteStrToFile(to, "")
+				if err != nil {
+					t.Fatalf("error creating test file: %s", err)
+				}
+			}
+
+			err := fs.MoveFile{From: from, To: to}.Migrate()
+			if tc.err && err == nil {
+				t.Fatalf("no error, but expected one")
+			}
+			if !tc.err && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if tc.err {
+				return
+			}
+
+			if from.Exists() {
+				t.Fatalf("%s still exists", from)
+			}
+
+			moved, err := utils.ReadFileToStr(to)
+			if err != nil {
+				t.Fatalf("error reading moved file: %s", err)
+			}
+			if moved != content {
+				t.Fatalf("unexpected file content: >%s<", moved)
+			}
+		})
+	}
+}
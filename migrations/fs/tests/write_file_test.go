@@ -0,0 +1,88 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/interfaces"
+	"github.com/jeftadlvw/git-nest/migrations/fs"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/utils"
+	"os"
+	"testing"
+)
+
+func TestWriteFileImplementsInterface(t *testing.T) {
+	var _ interfaces.Migration = (*fs.WriteFile)(nil)
+}
+
+func TestWriteFile(t *testing.T) {
+	const content = "hello world"
+
+	tests := []struct {
+		path         string
+		createDir    bool
+		createFile   bool
+		perm         os.FileMode
+		expectedPerm os.FileMode
+		err          bool
+	}{
+		{"", false, false, 0, 0, true},
+		{"foo", true, false, 0, 0, true},
+		{"foo", false, false, 0, 0644, false},
+		{"foo", false, false, 0755, 0755, false},
+		{"foo", false, true, 0755, 0755, false},
+		{"nested/dir/foo", false, false, 0600, 0600, false},
+	}
+
+	for index, tc := range tests {
+		t.Run(fmt.Sprintf("TestWriteFile-%d", index+1), func(t *testing.T) {
+			t.Parallel()
+			tempDir := models.Path(t.TempDir())
+
+			path := models.Path(tc.path)
+			if !path.Empty() {
+				path = tempDir.Join(path)
+			}
+
+			if tc.createDir {
+				err := os.Mkdir(path.String(), os.ModePerm)
+				if err != nil {
+					t.Fatalf("error creating test directory: %s", err)
+				}
+			}
+
+			if tc.createFile {
+				err := utils.WriteStrToFile(path, "old content")
+				if err != nil {
+					t.Fatalf("error writing test file: %s", err)
+				}
+			}
+
+			err := fs.WriteFile{Path: path, Content: content, Perm: tc.perm}.Migrate()
+			if tc.err && err == nil {
+				t.Fatalf("no error, but expected one")
+			}
+			if !tc.err && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if tc.err {
+				return
+			}
+
+			written, err := utils.ReadFileToStr(path)
+			if err != nil {
+				t.Fatalf("error reading written file: %s", err)
+			}
+			if written != content {
+				t.Fatalf("unexpected file content: >%s<", written)
+			}
+
+			info, err := os.Stat(path.String())
+			if err != nil {
+				t.Fatalf("error reading file info: %s", err)
+			}
+			if info.Mode().Perm() != tc.expectedPerm {
+				t.Fatalf("unexpected permissions: %s, expected %s", info.Mode().Perm(), tc.expectedPerm)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package fs
+
+import (
+	"errors"
+	"github.com/jeftadlvw/git-nest/internal"
+	"github.com/jeftadlvw/git-nest/models"
+)
+
+/*
+WriteArchive writes the files of multiple repository revisions and a manifest into an archive or a directory.
+*/
+type WriteArchive struct {
+	Output   models.Path
+	Format   string
+	Sources  []internal.ArchiveSource
+	Manifest internal.ArchiveManifest
+}
+
+func (m WriteArchive) Migrate() error {
+	if m.Output.Empty() {
+		return errors.New("output path is empty")
+	}
+
+	return internal.WriteArchive(m.Output, m.Format, m.Sources, m.Manifest)
+}
+
+func (m WriteArchive) CreatedPaths() []string {
+	return []string{m.Output.String()}
+}
@@ -0,0 +1,29 @@
+package fs
+
+import (
+	"errors"
+	"github.com/jeftadlvw/git-nest/internal"
+	"github.com/jeftadlvw/git-nest/models"
+)
+
+/*
+WriteBundle writes an offline bundle with a git bundle of every nested module and the configuration file.
+*/
+type WriteBundle struct {
+	Output      models.Path
+	ProjectRoot models.Path
+	ConfigFile  models.Path
+	Manifest    internal.BundleManifest
+}
+
+func (m WriteBundle) Migrate() error {
+	if m.Output.Empty() {
+		return errors.New("output path is empty")
+	}
+
+	return internal.WriteBundle(m.Output, m.ProjectRoot, m.ConfigFile, m.Manifest)
+}
+
+func (m WriteBundle) CreatedPaths() []string {
+	return []string{m.Output.String()}
+}
@@ -0,0 +1,52 @@
+package fs
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jeftadlvw/git-nest/models"
+	"os"
+)
+
+type WriteFile struct {
+	Path    models.Path
+	Content string
+	Perm    os.FileMode
+}
+
+func (m WriteFile) Migrate() error {
+	if m.Path.Empty() {
+		return errors.New("path is empty")
+	}
+
+	if m.Path.IsDir() {
+		return fmt.Errorf("%s is a directory", m.Path)
+	}
+
+	perm := m.Perm
+	if perm == 0 {
+		perm = 0644
+	}
+
+	parent := m.Path.Parent()
+	err := os.MkdirAll(parent.String(), os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("could not create directory %s: %w", parent, err)
+	}
+
+	err = os.WriteFile(m.Path.String(), []byte(m.Content), perm)
+	if err != nil {
+		return fmt.Errorf("could not write file: %w", err)
+	}
+
+	// WriteFile does not change permissions of existing files
+	err = os.Chmod(m.Path.String(), perm)
+	if err != nil {
+		return fmt.Errorf("could not set permissions of %s: %w", m.Path, err)
+	}
+
+	return nil
+}
+
+func (m WriteFile) CreatedPaths() []string {
+	return []string{m.Path.String()}
+}
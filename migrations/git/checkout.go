@@ -14,7 +14,7 @@ type Checkout struct {
 func (m Checkout) Migrate() error {
 	err := utils.GitCheckout(m.Path, m.Ref)
 	if err != nil {
-		return fmt.Errorf("error while changing ref: %s", err)
+		return fmt.Errorf("error while changing ref: %w", err)
 	}
 
 	return nil
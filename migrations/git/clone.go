@@ -1,11 +1,12 @@
 package git
 
 import (
+	"context"
 	"fmt"
 	"github.com/jeftadlvw/git-nest/interfaces"
+	"github.com/jeftadlvw/git-nest/internal"
 	"github.com/jeftadlvw/git-nest/models"
 	"github.com/jeftadlvw/git-nest/utils"
-	"golang.org/x/term"
 	"os"
 )
 
@@ -13,9 +14,14 @@ type Clone struct {
 	Url          interfaces.Url
 	Path         models.Path
 	CloneDirName string
+	RemoteName   string
 }
 
 func (m Clone) Migrate() error {
+	return m.MigrateContext(utils.CommandContext())
+}
+
+func (m Clone) MigrateContext(ctx context.Context) error {
 
 	if !m.Path.Exists() {
 		err := os.MkdirAll(m.Path.String(), os.ModePerm)
@@ -24,45 +30,27 @@ func (m Clone) Migrate() error {
 		}
 	}
 
-	var liveOutputFunc func(string) = nil
-	terminalFd := 0
-	var terminalWidth int
-
-	if term.IsTerminal(terminalFd) {
-		// execute once, but allows for early returns using break
-		localTerminalWidth, _, err := term.GetSize(terminalFd)
-		terminalWidth = localTerminalWidth
-
-		for range 1 {
-			if err != nil {
-				break
-			}
-
-			if terminalWidth == 0 {
-				break
-			}
-
-			liveOutputFunc = func(line string) {
-				// shorten string and add ellipsis if it's shorter than the terminal width
-				if len(line) > terminalWidth {
-					line = line[:utils.MaxInt(0, terminalWidth-6)] + "..."
-				}
-
-				_, _ = fmt.Fprintf(os.Stderr, "\r%*s", -terminalWidth, line)
-			}
-		}
-	}
-
-	err := utils.CloneGitRepository(m.Url.String(), m.Path, m.CloneDirName, liveOutputFunc)
+	clonePath := m.Path.SJoin(m.CloneDirName)
+	task := internal.ProgressReporter().Start(clonePath.String())
+	err := utils.CloneGitRepositoryContext(ctx, m.Url.String(), m.Path, m.CloneDirName, gitProgressFunc(task))
+	task.Done(err)
 
-	if liveOutputFunc != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "\r%*s", -terminalWidth, "")
-		_, _ = fmt.Fprintf(os.Stderr, "\r")
+	if err != nil {
+		return fmt.Errorf("error while cloning into %s: %w", clonePath, err)
 	}
 
-	if err != nil {
-		return fmt.Errorf("error while cloning into %s: %s", m.Path.SJoin(m.CloneDirName), err)
+	// git clones the remote as 'origin'
+	if m.RemoteName != "" && m.RemoteName != models.DefaultRemoteName {
+		err = utils.GitRenameRemote(clonePath, models.DefaultRemoteName, m.RemoteName)
+		if err != nil {
+			return fmt.Errorf("could not rename remote at %s: %w", clonePath, err)
+		}
 	}
 
 	return nil
 }
+
+func (m Clone) CreatedPaths() []string {
+	clonePath := m.Path.SJoin(m.CloneDirName)
+	return []string{m.Path.String(), clonePath.String()}
+}
@@ -0,0 +1,52 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/utils"
+	"os"
+)
+
+/*
+CloneBundle clones a repository from a local git bundle file. Like Clone, the remote is named RemoteName.
+*/
+type CloneBundle struct {
+	Bundle       models.Path
+	Path         models.Path
+	CloneDirName string
+	RemoteName   string
+}
+
+func (m CloneBundle) Migrate() error {
+	return m.MigrateContext(utils.CommandContext())
+}
+
+func (m CloneBundle) MigrateContext(ctx context.Context) error {
+	if !m.Path.Exists() {
+		err := os.MkdirAll(m.Path.String(), os.ModePerm)
+		if err != nil {
+			return fmt.Errorf("internal error: could not create directory %s: %w", m.Path, err)
+		}
+	}
+
+	err := utils.CloneGitRepositoryContext(ctx, m.Bundle.String(), m.Path, m.CloneDirName, nil)
+	if err != nil {
+		return fmt.Errorf("error while cloning %s into %s: %w", m.Bundle, m.Path.SJoin(m.CloneDirName), err)
+	}
+
+	// git clones the bundle as 'origin'
+	if m.RemoteName != "" && m.RemoteName != models.DefaultRemoteName {
+		err = utils.GitRenameRemote(m.Path.SJoin(m.CloneDirName), models.DefaultRemoteName, m.RemoteName)
+		if err != nil {
+			return fmt.Errorf("could not rename remote at %s: %w", m.Path.SJoin(m.CloneDirName), err)
+		}
+	}
+
+	return nil
+}
+
+func (m CloneBundle) CreatedPaths() []string {
+	clonePath := m.Path.SJoin(m.CloneDirName)
+	return []string{m.Path.String(), clonePath.String()}
+}
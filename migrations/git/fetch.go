@@ -0,0 +1,26 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/utils"
+)
+
+type Fetch struct {
+	Path   models.Path
+	Remote string
+}
+
+func (m Fetch) Migrate() error {
+	return m.MigrateContext(utils.CommandContext())
+}
+
+func (m Fetch) MigrateContext(ctx context.Context) error {
+	err := utils.GitFetchContext(ctx, m.Path, m.Remote, nil)
+	if err != nil {
+		return fmt.Errorf("could not fetch updates at %s: %w", m.Path, err)
+	}
+
+	return nil
+}
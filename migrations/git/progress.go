@@ -0,0 +1,17 @@
+package git
+
+import (
+	"github.com/jeftadlvw/git-nest/interfaces"
+	"github.com/jeftadlvw/git-nest/utils"
+)
+
+/*
+gitProgressFunc returns a callback for the live output of a git command that reports git's progress to task.
+*/
+func gitProgressFunc(task interfaces.ProgressTask) func(string) {
+	return func(line string) {
+		if progress, ok := utils.ParseGitProgress(line); ok {
+			task.Update(progress)
+		}
+	}
+}
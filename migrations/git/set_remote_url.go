@@ -0,0 +1,22 @@
+package git
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/utils"
+)
+
+type SetRemoteUrl struct {
+	Path   models.Path
+	Remote string
+	Url    string
+}
+
+func (m SetRemoteUrl) Migrate() error {
+	err := utils.GitSetRemoteUrl(m.Path, m.Remote, m.Url)
+	if err != nil {
+		return fmt.Errorf("could not set url of remote %s at %s: %w", m.Remote, m.Path, err)
+	}
+
+	return nil
+}
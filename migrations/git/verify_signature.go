@@ -0,0 +1,31 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jeftadlvw/git-nest/internal"
+	"github.com/jeftadlvw/git-nest/models"
+)
+
+/*
+VerifySignature verifies the signature of a nested module's checked out ref. The Submodule is read when the
+migration runs, so that preceding migrations like submodules.UpdateRef are respected.
+*/
+type VerifySignature struct {
+	Path         models.Path
+	Submodule    *models.Submodule
+	Verification models.SignatureVerification
+}
+
+func (m VerifySignature) Migrate() error {
+	if m.Submodule == nil {
+		return errors.New("migration contained nil submodule")
+	}
+
+	err := internal.VerifySubmoduleSignature(*m.Submodule, m.Path, m.Verification)
+	if err != nil {
+		return fmt.Errorf("refusing nested module at %s: %w", m.Submodule.Path, err)
+	}
+
+	return nil
+}
@@ -1,16 +1,164 @@
 package migrations
 
 import (
+	"context"
 	"fmt"
 	"github.com/jeftadlvw/git-nest/interfaces"
+	"github.com/jeftadlvw/git-nest/internal"
+	"github.com/jeftadlvw/git-nest/internal/constants"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/utils"
+	"log/slog"
+	"reflect"
+	"time"
 )
 
+var (
+	journalFile    models.Path
+	journalCommand []string
+	journalBackups []models.Path
+)
+
+/*
+SetJournal enables journaling for all following calls of RunMigrations. The journal is written to file and records the
+command and backups of the passed configuration files, see internal.Journal. An empty file disables journaling.
+*/
+func SetJournal(file models.Path, command []string, backups []models.Path) {
+	journalFile = file
+	journalCommand = command
+	journalBackups = backups
+}
+
+/*
+RunMigrations runs migrations in order, bound to the context of all commands, see utils.SetCommandContext.
+*/
 func RunMigrations(migrations ...interfaces.Migration) error {
+	return RunMigrationsContext(utils.CommandContext(), migrations...)
+}
+
+/*
+RunMigrationsContext runs migrations in order and stops at the first failing one. Once ctx is done, no further
+migrations are started and running ones that implement interfaces.ContextMigration are aborted.
+The journal of an aborted run is kept, so that it can be recovered.
+*/
+func RunMigrationsContext(ctx context.Context, migrations ...interfaces.Migration) error {
+	if journalFile.Empty() || len(migrations) == 0 {
+		for index, migration := range migrations {
+			if err := ctx.Err(); err != nil {
+				return fmt.Errorf("aborted before migration #%d: %w", index+1, err)
+			}
+
+			if err := migrate(ctx, index, migration); err != nil {
+				return fmt.Errorf("%w (migration #%d)", err, index+1)
+			}
+		}
+
+		return nil
+	}
+
+	journal, err := internal.StartJournal(journalFile, journalCommand, journalBackups)
+	if err != nil {
+		return err
+	}
+
 	for index, migration := range migrations {
-		if err := migration.Migrate(); err != nil {
+		if err = ctx.Err(); err != nil {
+			if index == 0 {
+				_ = journal.Finish()
+				return fmt.Errorf("aborted before migration #%d: %w", index+1, err)
+			}
+			return fmt.Errorf("aborted before migration #%d: %w.\nRun '%s recover' to resume or roll it back", index+1, err, constants.ApplicationName)
+		}
+
+		var createdPaths []string
+		if creator, ok := migration.(interfaces.PathCreator); ok {
+			createdPaths = creator.CreatedPaths()
+		}
+
+		err = journal.StartStep(fmt.Sprintf("%T", migration), createdPaths)
+		if err != nil {
+			return err
+		}
+
+		// failed runs are reported right away, only interrupted ones are left for recovery
+		if err = migrate(ctx, index, migration); err != nil {
+			if ctx.Err() != nil {
+				return fmt.Errorf("%w (migration #%d).\nRun '%s recover' to resume or roll it back", err, index+1, constants.ApplicationName)
+			}
+
+			_ = journal.Finish()
 			return fmt.Errorf("%w (migration #%d)", err, index+1)
 		}
+
+		err = journal.FinishStep()
+		if err != nil {
+			return err
+		}
+	}
+
+	return journal.Finish()
+}
+
+/*
+migrate runs a migration, passing ctx to migrations that implement interfaces.ContextMigration.
+Its start and its result are logged together with its position within the run, starting at 0.
+*/
+func migrate(ctx context.Context, index int, migration interfaces.Migration) error {
+	logger := utils.Logger().With(slog.Int("migration", index+1), slog.String("type", fmt.Sprintf("%T", migration)))
+	logger.Info("migration started", slog.Any("paths", migrationPaths(migration)))
+
+	start := time.Now()
+	var err error
+	if contextMigration, ok := migration.(interfaces.ContextMigration); ok {
+		err = contextMigration.MigrateContext(ctx)
+	} else {
+		err = migration.Migrate()
 	}
 
+	if err != nil {
+		logger.Info("migration failed", slog.Duration("duration", time.Since(start)), slog.String("error", err.Error()))
+		return err
+	}
+
+	logger.Info("migration finished", slog.Duration("duration", time.Since(start)))
 	return nil
 }
+
+/*
+migrationPaths returns the paths a migration operates on, which are the values of its fields of type models.Path
+and the path of its nested module. Other fields are not logged, as they may contain file contents or credentials.
+*/
+func migrationPaths(migration interfaces.Migration) []string {
+	v := reflect.ValueOf(migration)
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var paths []string
+	for index := range v.NumField() {
+		if !v.Type().Field(index).IsExported() {
+			continue
+		}
+
+		switch field := v.Field(index).Interface().(type) {
+		case models.Path:
+			if !field.Empty() {
+				paths = append(paths, field.String())
+			}
+		case models.Submodule:
+			paths = append(paths, field.Path.String())
+		case *models.Submodule:
+			if field != nil {
+				paths = append(paths, field.Path.String())
+			}
+		}
+	}
+
+	return paths
+}
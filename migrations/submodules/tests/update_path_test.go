@@ -0,0 +1,58 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/interfaces"
+	"github.com/jeftadlvw/git-nest/migrations/submodules"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/models/urls"
+	"testing"
+)
+
+func TestUpdatePathImplementsInterface(t *testing.T) {
+	var _ interfaces.Migration = (*submodules.UpdatePath)(nil)
+}
+
+func TestUpdatePath(t *testing.T) {
+	exampleUrl, err := urls.HttpUrlFromString("https://example.com/")
+	if err != nil {
+		t.Fatalf("could not parse example url: %s", err)
+	}
+
+	tests := []struct {
+		submodule *models.Submodule
+		path      models.Path
+		expected  models.Path
+		err       bool
+	}{
+		{nil, "foo", "", true},
+		{&models.Submodule{Path: "foo", Url: &exampleUrl}, "", "", true},
+		{&models.Submodule{Path: "foo", Url: &exampleUrl}, ".", "", true},
+		{&models.Submodule{Path: "foo", Url: &exampleUrl}, "b*ar", "", true},
+		{&models.Submodule{Path: "foo", Url: &exampleUrl}, "bar", "bar", false},
+		{&models.Submodule{Path: "foo", Url: &exampleUrl}, "  bar//baz  ", "bar/baz", false},
+	}
+
+	for index, tc := range tests {
+		t.Run(fmt.Sprintf("TestUpdatePath-%d", index+1), func(t *testing.T) {
+			t.Parallel()
+			err := submodules.UpdatePath{
+				Submodule: tc.submodule,
+				Path:      tc.path,
+			}.Migrate()
+
+			if tc.err && err == nil {
+				t.Fatalf("no error, but expected one")
+			}
+			if !tc.err && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if tc.err && tc.submodule != nil && tc.submodule.Path != "foo" {
+				t.Fatalf("failed migration changed path to %s", tc.submodule.Path)
+			}
+			if !tc.err && tc.submodule.Path != tc.expected {
+				t.Fatalf("submodule path not updated: >%s<, expected >%s<", tc.submodule.Path, tc.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package submodules
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jeftadlvw/git-nest/internal"
+	"github.com/jeftadlvw/git-nest/models"
+)
+
+/*
+UpdateIntegrity records the integrity hashes of a nested module. They are computed when the migration runs,
+so that preceding migrations like git.Checkout are respected.
+*/
+type UpdateIntegrity struct {
+	Submodule *models.Submodule
+	Path      models.Path
+}
+
+func (m UpdateIntegrity) Migrate() error {
+	if m.Submodule == nil {
+		return errors.New("migration contained nil submodule")
+	}
+
+	treeHash, worktreeHash, err := internal.SubmoduleIntegrityHashes(m.Path)
+	if err != nil {
+		return fmt.Errorf("could not record integrity of %s: %w", m.Submodule.Path, err)
+	}
+
+	m.Submodule.TreeHash = treeHash
+	m.Submodule.WorktreeHash = worktreeHash
+	return nil
+}
@@ -0,0 +1,33 @@
+package submodules
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jeftadlvw/git-nest/models"
+)
+
+type UpdatePath struct {
+	Submodule *models.Submodule
+	Path      models.Path
+}
+
+func (m UpdatePath) Migrate() error {
+	if m.Submodule == nil {
+		return errors.New("migration contained nil submodule")
+	}
+
+	m.Path = m.Path.Clean()
+	if m.Path.EmptyOrAtRoot() {
+		return errors.New("migration contained empty path")
+	}
+
+	updatedSubmodule := *m.Submodule
+	updatedSubmodule.Path = m.Path
+	err := updatedSubmodule.Validate()
+	if err != nil {
+		return fmt.Errorf("validation error for path: %w", err)
+	}
+
+	m.Submodule.Path = m.Path
+	return nil
+}
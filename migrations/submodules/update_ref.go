@@ -9,6 +9,11 @@ import (
 type UpdateRef struct {
 	Submodule *models.Submodule
 	Ref       string
+
+	/*
+		RefKind contains the kind of Ref. Empty keeps detecting the kind from the nested module's repository.
+	*/
+	RefKind models.RefKind
 }
 
 func (m UpdateRef) Migrate() error {
@@ -22,5 +27,6 @@ func (m UpdateRef) Migrate() error {
 	}
 
 	m.Submodule.Ref = m.Ref
+	m.Submodule.RefKind = m.RefKind
 	return nil
 }
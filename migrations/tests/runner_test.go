@@ -0,0 +1,67 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/jeftadlvw/git-nest/interfaces"
+	"github.com/jeftadlvw/git-nest/migrations"
+	"github.com/jeftadlvw/git-nest/migrations/fs"
+	"github.com/jeftadlvw/git-nest/migrations/submodules"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/utils"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRunMigrationsLogging(t *testing.T) {
+	var buf bytes.Buffer
+	utils.SetLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo})))
+	defer utils.SetLogger(nil)
+
+	dir := models.Path(t.TempDir())
+	file := dir.SJoin("file.txt")
+	submodule := models.Submodule{Path: "module", Ref: "main"}
+
+	cases := []struct {
+		migration interfaces.Migration
+		expected  []string
+		hidden    string
+	}{
+		{fs.WriteFile{Path: file, Content: "secret content"}, []string{file.String()}, "secret content"},
+		{submodules.UpdateRef{Submodule: &submodule, Ref: "secret-ref"}, []string{"module"}, "secret-ref"},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestRunMigrationsLogging-%d", index+1), func(t *testing.T) {
+			buf.Reset()
+
+			err := migrations.RunMigrations(tc.migration)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			var record struct {
+				Msg   string   `json:"msg"`
+				Type  string   `json:"type"`
+				Paths []string `json:"paths"`
+			}
+			err = json.Unmarshal([]byte(strings.SplitN(buf.String(), "\n", 2)[0]), &record)
+			if err != nil {
+				t.Fatalf("error parsing record: %s\n%s", err, buf.String())
+			}
+
+			if record.Msg != "migration started" || record.Type != fmt.Sprintf("%T", tc.migration) {
+				t.Fatalf("unexpected record: %s", buf.String())
+			}
+			if !slices.Equal(record.Paths, tc.expected) {
+				t.Fatalf("unexpected paths: %v, expected %v", record.Paths, tc.expected)
+			}
+			if strings.Contains(buf.String(), tc.hidden) {
+				t.Fatalf("record contains migration contents: %s", buf.String())
+			}
+		})
+	}
+}
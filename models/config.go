@@ -1,5 +1,18 @@
 package models
 
+import "fmt"
+
+const (
+	IgnoreModeExclude   = "exclude"
+	IgnoreModeGitignore = "gitignore"
+	IgnoreModeBoth      = "both"
+)
+
+const (
+	GitignoreLocationRoot      = "root"
+	GitignoreLocationDirectory = "directory"
+)
+
 /*
 Config represents all git-nest configurable options.
 */
@@ -13,11 +26,161 @@ type Config struct {
 		AllowUnequalRoots defines whether the project's git root and git-nest root are allowed to not be aligned.
 	*/
 	AllowUnequalRoots bool `toml:"allow_unequal_roots"`
+
+	/*
+		IgnoreMode defines where nested modules are ignored from the parent repository: in the clone-local
+		git exclude file, in a tracked .gitignore file or in both. Defaults to the git exclude file.
+	*/
+	IgnoreMode string `toml:"ignore_mode"`
+
+	/*
+		GitignoreLocation defines whether nested modules are ignored in the project root's .gitignore file
+		or in the .gitignore file of each module's parent directory. Defaults to the project root.
+	*/
+	GitignoreLocation string `toml:"gitignore_location"`
+
+	/*
+		SshHttpsEquivalent defines whether ssh and http(s) remote urls to the same host and path are treated as equal
+		when comparing a nested module's remote with its configured url.
+	*/
+	SshHttpsEquivalent bool `toml:"ssh_https_equivalent"`
+
+	/*
+		Credentials defines how git authenticates against private https hosts.
+	*/
+	Credentials []Credential `toml:"credentials"`
+
+	/*
+		UrlRewrites defines url prefixes that are replaced whenever git contacts a remote.
+	*/
+	UrlRewrites []UrlRewrite `toml:"url_rewrite"`
+
+	/*
+		VerifySignature defines whether the signatures of all nested modules' checked out refs are verified.
+	*/
+	VerifySignature bool `toml:"verify_signature"`
+
+	/*
+		AllowedSigners contains the path to an ssh allowed signers file, relative to the project root.
+	*/
+	AllowedSigners string `toml:"allowed_signers"`
+
+	/*
+		GpgHome contains the path to a GnuPG home directory with a keyring of trusted keys, relative to the project root.
+	*/
+	GpgHome string `toml:"gpg_home"`
+
+	/*
+		RecordIntegrity defines whether synchronization records each nested module's tree hash and worktree hash.
+	*/
+	RecordIntegrity bool `toml:"record_integrity"`
+
+	/*
+		Policy restricts the origins, refs and paths of nested modules.
+	*/
+	Policy Policy `toml:"policy"`
+
+	/*
+		Timeouts defines how long git commands may run before they are aborted.
+	*/
+	Timeouts Timeouts `toml:"timeouts"`
+
+	/*
+		Retry defines how often clones and pulls are attempted before they fail.
+	*/
+	Retry Retry `toml:"retry"`
 }
 
 /*
 Validate performs validation on this Config.
 */
 func (c Config) Validate() error {
+	switch c.IgnoreMode {
+	case "", IgnoreModeExclude, IgnoreModeGitignore, IgnoreModeBoth:
+	default:
+		return fmt.Errorf("invalid ignore_mode '%s', expected one of %s, %s, %s", c.IgnoreMode, IgnoreModeExclude, IgnoreModeGitignore, IgnoreModeBoth)
+	}
+
+	switch c.GitignoreLocation {
+	case "", GitignoreLocationRoot, GitignoreLocationDirectory:
+	default:
+		return fmt.Errorf("invalid gitignore_location '%s', expected one of %s, %s", c.GitignoreLocation, GitignoreLocationRoot, GitignoreLocationDirectory)
+	}
+
+	hosts := make(map[string]bool)
+	for _, credential := range c.Credentials {
+		err := credential.Validate()
+		if err != nil {
+			return err
+		}
+
+		credentialUrl := credential.CredentialUrl()
+		if hosts[credentialUrl] {
+			return fmt.Errorf("credential for %s defined multiple times", credential.Host)
+		}
+		hosts[credentialUrl] = true
+	}
+
+	err := ValidateUrlRewrites(c.UrlRewrites)
+	if err != nil {
+		return err
+	}
+
+	err = c.Policy.Validate()
+	if err != nil {
+		return err
+	}
+
+	err = c.Timeouts.Validate()
+	if err != nil {
+		return err
+	}
+
+	err = c.Retry.Validate()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+/*
+UsesGitExclude returns whether nested modules are ignored in the git exclude file.
+*/
+func (c Config) UsesGitExclude() bool {
+	return c.IgnoreMode == "" || c.IgnoreMode == IgnoreModeExclude || c.IgnoreMode == IgnoreModeBoth
+}
+
+/*
+UsesGitignore returns whether nested modules are ignored in .gitignore files.
+*/
+func (c Config) UsesGitignore() bool {
+	return c.IgnoreMode == IgnoreModeGitignore || c.IgnoreMode == IgnoreModeBoth
+}
+
+/*
+GitignorePerDirectory returns whether nested modules are ignored in the .gitignore file of their parent directory.
+*/
+func (c Config) GitignorePerDirectory() bool {
+	return c.GitignoreLocation == GitignoreLocationDirectory
+}
+
+/*
+ValidateUrlRewrites validates each UrlRewrite and checks that no prefix is defined multiple times.
+*/
+func ValidateUrlRewrites(rewrites []UrlRewrite) error {
+	prefixes := make(map[string]bool)
+	for _, rewrite := range rewrites {
+		err := rewrite.Validate()
+		if err != nil {
+			return err
+		}
+
+		if prefixes[rewrite.Prefix] {
+			return fmt.Errorf("url rewrite for %s defined multiple times", rewrite.Prefix)
+		}
+		prefixes[rewrite.Prefix] = true
+	}
+
 	return nil
 }
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var environmentVariableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+/*
+credentialHelperPattern matches the name of a git credential helper with optional arguments, e.g. 'store --file=.credentials'.
+git runs helpers through a shell, so that shell metacharacters are not allowed.
+*/
+var credentialHelperPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*( +[A-Za-z0-9._/=:@,+~-]+)*$`)
+
+/*
+Credential configures how git authenticates against a single https host when git-nest runs git for nested modules.
+*/
+type Credential struct {
+	/*
+		Host contains the hostname, optionally including a port, the credential applies to.
+	*/
+	Host string `toml:"host"`
+
+	/*
+		Helper contains the name of a git credential helper, e.g. 'store' or 'manager'. As the configuration is tracked,
+		shell helpers ('!...') and absolute paths are rejected, as they would run arbitrary commands.
+	*/
+	Helper string `toml:"helper"`
+
+	/*
+		TokenEnv contains the name of an environment variable that holds an access token.
+		The token itself is never stored in the configuration.
+	*/
+	TokenEnv string `toml:"token_env"`
+
+	/*
+		Username contains the username that is sent alongside the token from TokenEnv.
+	*/
+	Username string `toml:"username"`
+}
+
+/*
+Validate performs validation on this Credential.
+*/
+func (c Credential) Validate() error {
+	host := strings.TrimSpace(c.Host)
+	if host == "" {
+		return errors.New("credential host may not be empty")
+	}
+
+	if strings.ContainsAny(host, "/@ \t") {
+		return fmt.Errorf("credential host '%s' may only contain a hostname and an optional port", c.Host)
+	}
+
+	helper := strings.TrimSpace(c.Helper)
+	tokenEnv := strings.TrimSpace(c.TokenEnv)
+
+	if helper == "" && tokenEnv == "" {
+		return fmt.Errorf("credential for %s requires either helper or token_env", host)
+	}
+
+	if helper != "" && tokenEnv != "" {
+		return fmt.Errorf("credential for %s may only define one of helper and token_env", host)
+	}
+
+	if tokenEnv != "" && !environmentVariableNamePattern.MatchString(tokenEnv) {
+		return fmt.Errorf("credential for %s: token_env '%s' is not a valid environment variable name", host, c.TokenEnv)
+	}
+
+	if strings.HasPrefix(helper, "!") {
+		return fmt.Errorf("credential for %s: shell helper '%s' is not allowed, configure it in your git configuration instead", host, c.Helper)
+	}
+
+	if helper != "" && !credentialHelperPattern.MatchString(helper) {
+		return fmt.Errorf("credential for %s: helper '%s' must be a helper name with optional arguments", host, c.Helper)
+	}
+
+	if helper != "" && c.Username != "" {
+		return fmt.Errorf("credential for %s: username can only be used with token_env", host)
+	}
+
+	return nil
+}
+
+/*
+CredentialUrl returns the url prefix git uses to match this Credential to remote urls.
+*/
+func (c Credential) CredentialUrl() string {
+	return "https://" + strings.ToLower(strings.TrimSpace(c.Host))
+}
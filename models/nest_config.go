@@ -3,6 +3,7 @@ package models
 import (
 	"fmt"
 	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/jeftadlvw/git-nest/models/urls"
 	"path/filepath"
 	"strings"
 )
@@ -46,6 +47,11 @@ func (c NestConfig) Validate() error {
 		if strings.HasPrefix(submodule.Path.String(), "..") {
 			return fmt.Errorf("submodule path escapes project root (%s)", submodule.Path)
 		}
+
+		err = c.Config.SignatureVerification(submodule, "").Validate()
+		if err != nil {
+			return fmt.Errorf("error at submodule index %d: %w", index, err)
+		}
 	}
 
 	err = CheckForDuplicateSubmodules(c.Config.AllowDuplicateOrigins, c.Submodules...)
@@ -83,8 +89,13 @@ func CheckForDuplicateSubmodules(allowDuplicateOrigins bool, submodules ...Submo
 		// check if submodules have duplicate remote origin urls
 		// it's enough to check for the url, because if AllowDuplicateOrigins is set to true,
 		// duplicate refs are automatically allowed too (no ref is a ref to something default!)
+		// equivalent urls, e.g. with and without '.git' suffix, are considered duplicates
 		submoduleRemoteUrl := submodule.Url.String()
-		added = remoteUrlSet.Add(submoduleRemoteUrl)
+		canonicalRemoteUrl, err := urls.CanonicalUrl(submoduleRemoteUrl, true)
+		if err != nil {
+			canonicalRemoteUrl = submoduleRemoteUrl
+		}
+		added = remoteUrlSet.Add(canonicalRemoteUrl)
 		if !added && !allowDuplicateOrigins {
 			return fmt.Errorf("submodule origin urls %s defined multiple times", submoduleRemoteUrl)
 		}
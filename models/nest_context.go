@@ -25,6 +25,13 @@ type NestContext struct {
 	*/
 	GitRepositoryRoot Path
 
+	/*
+		GitCommonDir is a Path to the git directory shared by all worktrees of the project's repository.
+		It is not necessarily located at `[GitRepositoryRoot]/.git`, e.g. in linked worktrees or repositories
+		with a separate git directory.
+	*/
+	GitCommonDir Path
+
 	/*
 		ConfigFileExists defines whether a `nestmodules.toml` configuration file exists.
 	*/
@@ -42,6 +49,23 @@ type NestContext struct {
 	*/
 	Config NestConfig
 
+	/*
+		UrlRewrites contains the effective url rewrite rules, combined from the environment, the clone-local
+		configuration file and the project configuration, in that order of precedence.
+	*/
+	UrlRewrites []UrlRewrite
+
+	/*
+		Policy contains the effective policy for nested modules. It is read from the project configuration,
+		unless a policy file overrides it.
+	*/
+	Policy Policy
+
+	/*
+		PolicyFile contains the Path to the policy file that overrides the project's policy, if any.
+	*/
+	PolicyFile Path
+
 	/*
 		Checksums contains checksums of every configuration file's contents.
 	*/
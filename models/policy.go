@@ -0,0 +1,210 @@
+package models
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/models/urls"
+	"path"
+	"slices"
+	"strings"
+)
+
+/*
+Policy restricts which repositories can be nested, how their refs are pinned and where they can be placed.
+An empty Policy allows everything.
+*/
+type Policy struct {
+	/*
+		AllowedHosts contains hostname patterns, e.g. 'github.com' or '*.example.com', that remote urls must point to.
+	*/
+	AllowedHosts []string `toml:"allowed_hosts"`
+
+	/*
+		AllowedUrls contains url patterns, e.g. 'https://github.com/organization/*', that remote urls must match.
+		Each '*' matches within a single path segment. Urls are compared by their canonical form.
+	*/
+	AllowedUrls []string `toml:"allowed_urls"`
+
+	/*
+		RequiredRefKinds contains the ref kinds that nested modules must be pinned to, e.g. tag and commit.
+	*/
+	RequiredRefKinds []RefKind `toml:"required_ref_kinds"`
+
+	/*
+		ForbiddenPaths contains path patterns, relative to the project root, that nested modules may not be placed at or in.
+	*/
+	ForbiddenPaths []string `toml:"forbidden_paths"`
+}
+
+/*
+PolicyViolation describes a nested module that violates a Policy.
+*/
+type PolicyViolation struct {
+	/*
+		Submodule contains the path of the violating nested module.
+	*/
+	Submodule string
+
+	/*
+		Message describes the violation.
+	*/
+	Message string
+}
+
+/*
+String returns a string representation of this PolicyViolation.
+*/
+func (v PolicyViolation) String() string {
+	return fmt.Sprintf("nested module %s: %s", v.Submodule, v.Message)
+}
+
+/*
+Empty returns whether this Policy does not restrict anything.
+*/
+func (p Policy) Empty() bool {
+	return len(p.AllowedHosts) == 0 && len(p.AllowedUrls) == 0 && len(p.RequiredRefKinds) == 0 && len(p.ForbiddenPaths) == 0
+}
+
+/*
+Validate performs validation on this Policy.
+*/
+func (p Policy) Validate() error {
+	for _, patterns := range [][]string{p.AllowedHosts, p.AllowedUrls, p.ForbiddenPaths} {
+		for _, pattern := range patterns {
+			if strings.TrimSpace(pattern) == "" {
+				return fmt.Errorf("policy contains an empty pattern")
+			}
+
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("policy pattern '%s' is invalid: %w", pattern, err)
+			}
+		}
+	}
+
+	for _, kind := range p.RequiredRefKinds {
+		if kind == RefKindAuto {
+			return fmt.Errorf("policy contains an empty ref kind")
+		}
+
+		if err := kind.Validate(); err != nil {
+			return fmt.Errorf("policy %w", err)
+		}
+	}
+
+	return nil
+}
+
+/*
+Violations returns all violations of this Policy by a Submodule, whose ref is of the passed kind.
+The kind is only evaluated if ref kinds are required.
+*/
+func (p Policy) Violations(s Submodule, kind RefKind) []PolicyViolation {
+	var violations []PolicyViolation
+	violation := func(format string, a ...any) {
+		violations = append(violations, PolicyViolation{Submodule: s.Path.UnixString(), Message: fmt.Sprintf(format, a...)})
+	}
+
+	remoteUrls := map[string]string{}
+	if s.Url != nil {
+		remoteUrls[s.RemoteName()] = s.Url.String()
+	}
+	for name, remoteUrl := range s.Remotes {
+		remoteUrls[name] = remoteUrl
+	}
+
+	for _, name := range append([]string{s.RemoteName()}, s.RemoteNames()...) {
+		remoteUrl, ok := remoteUrls[name]
+		if ok && !p.AllowsUrl(remoteUrl) {
+			violation("url %s of remote %s is not allowed", urls.RedactCredentials(remoteUrl), name)
+		}
+	}
+
+	if len(p.RequiredRefKinds) != 0 {
+		if s.Ref == "" {
+			violation("ref is required and must be a %s", fmtRefKinds(p.RequiredRefKinds))
+		} else if !slices.Contains(p.RequiredRefKinds, kind) {
+			kindStr := string(kind)
+			if kind == RefKindAuto {
+				kindStr = "ref of unknown kind"
+			}
+			violation("ref %s is a %s, but must be a %s", s.Ref, kindStr, fmtRefKinds(p.RequiredRefKinds))
+		}
+	}
+
+	if pattern, forbidden := p.ForbidsPath(s.Path); forbidden {
+		violation("path is forbidden by pattern '%s'", pattern)
+	}
+
+	return violations
+}
+
+/*
+AllowsUrl returns whether a remote url matches the allowed hosts or allowed url patterns of this Policy.
+All urls are allowed if neither is defined.
+*/
+func (p Policy) AllowsUrl(remoteUrl string) bool {
+	if len(p.AllowedHosts) == 0 && len(p.AllowedUrls) == 0 {
+		return true
+	}
+
+	canonicalUrl, err := urls.CanonicalUrl(remoteUrl, false)
+	if err != nil {
+		return false
+	}
+
+	hostPath, _ := urls.CanonicalUrl(remoteUrl, true)
+	host, _, _ := strings.Cut(hostPath, "/")
+	hostname := host
+	if index := strings.LastIndex(host, ":"); index != -1 && !strings.HasSuffix(host, "]") {
+		hostname = host[:index]
+	}
+
+	for _, pattern := range p.AllowedHosts {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if matched, _ := path.Match(pattern, host); matched {
+			return true
+		}
+		if matched, _ := path.Match(pattern, hostname); matched {
+			return true
+		}
+	}
+
+	for _, pattern := range p.AllowedUrls {
+		if matched, _ := path.Match(urls.CanonicalUrlPattern(pattern), canonicalUrl); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+/*
+ForbidsPath returns whether a module path or one of its parent directories matches a forbidden path pattern,
+including the matching pattern.
+*/
+func (p Policy) ForbidsPath(modulePath Path) (string, bool) {
+	unixPath := modulePath.UnixString()
+
+	for _, pattern := range p.ForbiddenPaths {
+		pattern = strings.Trim(strings.TrimSpace(pattern), "/")
+
+		for candidate := unixPath; candidate != "." && candidate != "/" && candidate != ""; candidate = path.Dir(candidate) {
+			if matched, _ := path.Match(pattern, candidate); matched {
+				return pattern, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+/*
+fmtRefKinds formats ref kinds as a human-readable enumeration.
+*/
+func fmtRefKinds(kinds []RefKind) string {
+	kindStrs := make([]string, len(kinds))
+	for index, kind := range kinds {
+		kindStrs[index] = string(kind)
+	}
+
+	return strings.Join(kindStrs, " or ")
+}
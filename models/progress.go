@@ -0,0 +1,26 @@
+package models
+
+/*
+Progress describes the progress of a phase of a long-running operation, e.g. git's 'Receiving objects'.
+*/
+type Progress struct {
+	/*
+		Phase contains the name of the current phase.
+	*/
+	Phase string
+
+	/*
+		Percent contains the completion of the current phase, from 0 to 100.
+	*/
+	Percent int
+
+	/*
+		Current contains the number of processed items within the current phase.
+	*/
+	Current int
+
+	/*
+		Total contains the number of items within the current phase.
+	*/
+	Total int
+}
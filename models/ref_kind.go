@@ -0,0 +1,67 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+/*
+RefKind describes what kind of git reference a Submodule's ref points to.
+*/
+type RefKind string
+
+const (
+	/*
+		RefKindAuto detects the kind of a reference from the nested module's repository.
+	*/
+	RefKindAuto RefKind = ""
+
+	/*
+		RefKindBranch references a branch that is followed and pulled.
+	*/
+	RefKindBranch RefKind = "branch"
+
+	/*
+		RefKindTag references a tag. The nested module is checked out detached at the tag.
+	*/
+	RefKindTag RefKind = "tag"
+
+	/*
+		RefKindCommit references a commit by its full or abbreviated hash.
+	*/
+	RefKindCommit RefKind = "commit"
+)
+
+var commitHashPattern = regexp.MustCompile(`^[0-9a-fA-F]{4,64}$`)
+
+/*
+Validate performs validation on this RefKind.
+*/
+func (k RefKind) Validate() error {
+	switch k {
+	case RefKindAuto, RefKindBranch, RefKindTag, RefKindCommit:
+		return nil
+	default:
+		return fmt.Errorf("ref kind must be one of '%s', '%s' or '%s', got '%s'", RefKindBranch, RefKindTag, RefKindCommit, string(k))
+	}
+}
+
+/*
+IsCommitHash returns whether a reference looks like a full or abbreviated commit hash.
+*/
+func IsCommitHash(ref string) bool {
+	return commitHashPattern.MatchString(strings.TrimSpace(ref))
+}
+
+/*
+CommitHashMatches returns whether a full commit hash starts with a full or abbreviated commit hash.
+*/
+func CommitHashMatches(commit string, ref string) bool {
+	ref = strings.TrimSpace(ref)
+	if !IsCommitHash(ref) {
+		return false
+	}
+
+	return strings.HasPrefix(strings.ToLower(strings.TrimSpace(commit)), strings.ToLower(ref))
+}
@@ -0,0 +1,163 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+/*
+GitErrorClass describes the cause of a failed git operation that contacted a remote.
+*/
+type GitErrorClass string
+
+const (
+	GitErrorClassNetwork        GitErrorClass = "network"
+	GitErrorClassTimeout        GitErrorClass = "timeout"
+	GitErrorClassServer         GitErrorClass = "server"
+	GitErrorClassAuthentication GitErrorClass = "authentication"
+)
+
+const (
+	DefaultRetryAttempts   = 1
+	DefaultRetryBackoff    = 2 * time.Second
+	DefaultRetryMaxBackoff = 30 * time.Second
+)
+
+/*
+DefaultRetryOn contains the error classes that are retried if no classes are configured.
+*/
+var DefaultRetryOn = []GitErrorClass{GitErrorClassNetwork, GitErrorClassTimeout, GitErrorClassServer}
+
+/*
+Validate performs validation on this GitErrorClass.
+*/
+func (c GitErrorClass) Validate() error {
+	switch c {
+	case GitErrorClassNetwork, GitErrorClassTimeout, GitErrorClassServer, GitErrorClassAuthentication:
+		return nil
+	}
+
+	return fmt.Errorf("invalid error class '%s', expected one of %s, %s, %s, %s", c, GitErrorClassNetwork, GitErrorClassTimeout, GitErrorClassServer, GitErrorClassAuthentication)
+}
+
+/*
+Retry defines how often clones and pulls are attempted before they fail. Delays are durations, e.g. '5s'.
+*/
+type Retry struct {
+	/*
+		Attempts contains the maximum number of attempts, including the first one. Defaults to 1, which disables retries.
+	*/
+	Attempts int `toml:"attempts"`
+
+	/*
+		Backoff contains the delay before the first retry, which doubles with every further retry. Defaults to 2 seconds.
+	*/
+	Backoff string `toml:"backoff"`
+
+	/*
+		MaxBackoff contains the upper limit of the delay between two attempts. Defaults to 30 seconds.
+	*/
+	MaxBackoff string `toml:"max_backoff"`
+
+	/*
+		RetryOn contains the error classes that are retried. Defaults to network, timeout and server errors.
+	*/
+	RetryOn []GitErrorClass `toml:"retry_on"`
+}
+
+/*
+RetryPolicy contains the effective values of Retry.
+*/
+type RetryPolicy struct {
+	Attempts   int
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	RetryOn    []GitErrorClass
+}
+
+/*
+Empty returns whether no value of this Retry differs from its default.
+*/
+func (r Retry) Empty() bool {
+	return r.Attempts == 0 && r.Backoff == "" && r.MaxBackoff == "" && len(r.RetryOn) == 0
+}
+
+/*
+Validate performs validation on this Retry.
+*/
+func (r Retry) Validate() error {
+	if r.Attempts < 0 {
+		return fmt.Errorf("retry attempts may not be negative")
+	}
+
+	if _, err := parseTimeout(r.Backoff, 0); err != nil {
+		return fmt.Errorf("invalid retry backoff: %w", err)
+	}
+
+	if _, err := parseTimeout(r.MaxBackoff, 0); err != nil {
+		return fmt.Errorf("invalid retry max_backoff: %w", err)
+	}
+
+	for _, class := range r.RetryOn {
+		if err := class.Validate(); err != nil {
+			return fmt.Errorf("invalid retry_on: %w", err)
+		}
+	}
+
+	return nil
+}
+
+/*
+RetryPolicy returns the effective values of this Retry. Invalid values fall back to their default.
+*/
+func (r Retry) RetryPolicy() RetryPolicy {
+	policy := RetryPolicy{
+		Attempts:   r.Attempts,
+		Backoff:    DefaultRetryBackoff,
+		MaxBackoff: DefaultRetryMaxBackoff,
+		RetryOn:    slices.Clone(r.RetryOn),
+	}
+
+	if policy.Attempts < 1 {
+		policy.Attempts = DefaultRetryAttempts
+	}
+
+	if backoff, err := parseTimeout(r.Backoff, DefaultRetryBackoff); err == nil {
+		policy.Backoff = backoff
+	}
+
+	if maxBackoff, err := parseTimeout(r.MaxBackoff, DefaultRetryMaxBackoff); err == nil {
+		policy.MaxBackoff = maxBackoff
+	}
+
+	if len(policy.RetryOn) == 0 {
+		policy.RetryOn = slices.Clone(DefaultRetryOn)
+	}
+
+	return policy
+}
+
+/*
+Retries returns whether failures of the passed error class are retried.
+*/
+func (p RetryPolicy) Retries(class GitErrorClass) bool {
+	return class != "" && slices.Contains(p.RetryOn, class)
+}
+
+/*
+Delay returns the delay before the passed retry, starting at 1. The backoff doubles with every retry,
+but does not exceed the maximum backoff.
+*/
+func (p RetryPolicy) Delay(retry int) time.Duration {
+	delay := p.Backoff
+	for i := 1; i < retry && (p.MaxBackoff <= 0 || delay < p.MaxBackoff); i++ {
+		delay *= 2
+	}
+
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		return p.MaxBackoff
+	}
+
+	return delay
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+)
+
+/*
+SignatureVerification contains the effective settings that are used to verify the signature of a nested module's
+checked out ref.
+*/
+type SignatureVerification struct {
+	/*
+		Enabled defines whether signatures are verified.
+	*/
+	Enabled bool
+
+	/*
+		AllowedSigners contains the ssh allowed signers file that lists trusted ssh signing keys.
+	*/
+	AllowedSigners Path
+
+	/*
+		GpgHome contains a GnuPG home directory whose keyring contains trusted gpg keys.
+	*/
+	GpgHome Path
+}
+
+/*
+SignatureVerification returns the effective signature verification settings for a Submodule.
+Verification is enabled globally or per submodule, and a submodule's allowed signers file and keyring take precedence
+over the global ones. Relative paths are resolved against root.
+*/
+func (c Config) SignatureVerification(s Submodule, root Path) SignatureVerification {
+	allowedSigners, gpgHome := c.AllowedSigners, c.GpgHome
+	if strings.TrimSpace(s.AllowedSigners) != "" {
+		allowedSigners = s.AllowedSigners
+	}
+	if strings.TrimSpace(s.GpgHome) != "" {
+		gpgHome = s.GpgHome
+	}
+
+	return SignatureVerification{
+		Enabled:        c.VerifySignature || s.VerifySignature,
+		AllowedSigners: resolveSignaturePath(allowedSigners, root),
+		GpgHome:        resolveSignaturePath(gpgHome, root),
+	}
+}
+
+/*
+Validate performs validation on this SignatureVerification.
+*/
+func (v SignatureVerification) Validate() error {
+	if v.Enabled && v.AllowedSigners.Empty() && v.GpgHome.Empty() {
+		return errors.New("signature verification requires allowed_signers or gpg_home")
+	}
+
+	return nil
+}
+
+/*
+resolveSignaturePath resolves a configured path against root, unless it is absolute or empty.
+*/
+func resolveSignaturePath(p string, root Path) Path {
+	p = strings.TrimSpace(p)
+	if p == "" {
+		return ""
+	}
+
+	path := Path(p)
+	if filepath.IsAbs(p) || root.Empty() {
+		return path.Clean()
+	}
+
+	return root.Join(path)
+}
@@ -4,14 +4,80 @@ import (
 	"fmt"
 	"github.com/jeftadlvw/git-nest/models/urls"
 	"net/url"
+	"path"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
 )
 
+/*
+DefaultRemoteName contains the name of a nested module's canonical remote if none is declared.
+*/
+const DefaultRemoteName = "origin"
+
+var (
+	remoteNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+	groupNamePattern  = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+	objectHashPattern = regexp.MustCompile(`^([0-9a-f]{40}|[0-9a-f]{64})$`)
+	checksumPattern   = regexp.MustCompile(`^[0-9a-f]{64}$`)
+)
+
 type Submodule struct {
 	Path Path
 	Url  *urls.HttpUrl
 	Ref  string
+
+	/*
+		RefKind contains the kind of Ref. It is detected from the nested module's repository if empty.
+	*/
+	RefKind RefKind `toml:"ref_kind"`
+
+	/*
+		Remote contains the name of the remote that points to Url. Defaults to DefaultRemoteName.
+	*/
+	Remote string
+
+	/*
+		Remotes contains additional remotes of the nested module, mapping remote names to urls.
+	*/
+	Remotes map[string]string
+
+	/*
+		Groups contains the names of the groups the nested module belongs to, e.g. to archive only some modules.
+	*/
+	Groups []string
+
+	/*
+		SparsePaths contains the paths within the nested module that are included in archives, relative to its root.
+		All files are included if empty.
+	*/
+	SparsePaths []string `toml:"sparse_paths"`
+
+	/*
+		VerifySignature defines whether the signature of the checked out ref is verified.
+	*/
+	VerifySignature bool `toml:"verify_signature"`
+
+	/*
+		AllowedSigners contains the path to an ssh allowed signers file that overrides the global one.
+	*/
+	AllowedSigners string `toml:"allowed_signers"`
+
+	/*
+		GpgHome contains the path to a GnuPG home directory that overrides the global one.
+	*/
+	GpgHome string `toml:"gpg_home"`
+
+	/*
+		TreeHash contains the recorded hash of the tree the nested module's HEAD points to.
+	*/
+	TreeHash string `toml:"tree_hash"`
+
+	/*
+		WorktreeHash contains the recorded checksum of the nested module's working tree, see utils.CalculateChecksumFiles.
+	*/
+	WorktreeHash string `toml:"worktree_hash"`
 }
 
 /*
@@ -20,6 +86,61 @@ Clean performs a data cleanup on this Submodule.
 func (s *Submodule) Clean() {
 	s.Path = s.Path.Clean()
 	s.Ref = strings.TrimSpace(s.Ref)
+	s.RefKind = RefKind(strings.ToLower(strings.TrimSpace(string(s.RefKind))))
+	s.Remote = strings.TrimSpace(s.Remote)
+
+	for index, group := range s.Groups {
+		s.Groups[index] = strings.TrimSpace(group)
+	}
+
+	for index, sparsePath := range s.SparsePaths {
+		sparsePath = filepath.ToSlash(strings.TrimSpace(sparsePath))
+		if sparsePath != "" {
+			sparsePath = path.Clean(sparsePath)
+		}
+		s.SparsePaths[index] = sparsePath
+	}
+}
+
+/*
+InGroups returns whether this Submodule belongs to at least one of the passed groups.
+Every Submodule belongs to an empty list of groups.
+*/
+func (s *Submodule) InGroups(groups []string) bool {
+	if len(groups) == 0 {
+		return true
+	}
+
+	for _, group := range groups {
+		if slices.Contains(s.Groups, strings.TrimSpace(group)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+/*
+RemoteName returns the name of the remote that points to this Submodule's Url.
+*/
+func (s *Submodule) RemoteName() string {
+	if s.Remote == "" {
+		return DefaultRemoteName
+	}
+	return s.Remote
+}
+
+/*
+RemoteNames returns the names of this Submodule's additional remotes in sorted order.
+*/
+func (s *Submodule) RemoteNames() []string {
+	names := make([]string, 0, len(s.Remotes))
+	for name := range s.Remotes {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return names
 }
 
 /*
@@ -95,5 +216,60 @@ func (s *Submodule) Validate() error {
 		return fmt.Errorf("submodule ref contains spaces (%s)", s.Ref)
 	}
 
+	if err := s.RefKind.Validate(); err != nil {
+		return fmt.Errorf("submodule %w", err)
+	}
+
+	if s.RefKind != RefKindAuto && s.Ref == "" {
+		return fmt.Errorf("submodule ref kind %s requires a ref", s.RefKind)
+	}
+
+	if s.RefKind == RefKindCommit && !IsCommitHash(s.Ref) {
+		return fmt.Errorf("submodule ref %s is not a commit hash", s.Ref)
+	}
+
+	if s.TreeHash != "" && !objectHashPattern.MatchString(s.TreeHash) {
+		return fmt.Errorf("submodule tree hash %s is invalid", s.TreeHash)
+	}
+
+	if s.WorktreeHash != "" && !checksumPattern.MatchString(s.WorktreeHash) {
+		return fmt.Errorf("submodule worktree hash %s is invalid", s.WorktreeHash)
+	}
+
+	for _, group := range s.Groups {
+		if !groupNamePattern.MatchString(group) {
+			return fmt.Errorf("submodule group name '%s' is invalid", group)
+		}
+	}
+
+	for _, sparsePath := range s.SparsePaths {
+		if sparsePath == "" || sparsePath == "." || path.IsAbs(sparsePath) || sparsePath == ".." || strings.HasPrefix(sparsePath, "../") {
+			return fmt.Errorf("submodule sparse path '%s' must be a relative path within the submodule", sparsePath)
+		}
+	}
+
+	if !remoteNamePattern.MatchString(s.RemoteName()) {
+		return fmt.Errorf("submodule remote name '%s' is invalid", s.Remote)
+	}
+
+	for _, name := range s.RemoteNames() {
+		if !remoteNamePattern.MatchString(name) {
+			return fmt.Errorf("submodule remote name '%s' is invalid", name)
+		}
+
+		if name == s.RemoteName() {
+			return fmt.Errorf("submodule remote %s is already the remote of the submodule url", name)
+		}
+
+		remoteUrl := strings.TrimSpace(s.Remotes[name])
+		if remoteUrl == "" {
+			return fmt.Errorf("url of submodule remote %s is required", name)
+		}
+
+		if urls.ContainsCredentials(remoteUrl) {
+			return fmt.Errorf("url of submodule remote %s may not contain credentials", name)
+		}
+	}
+
 	return nil
 }
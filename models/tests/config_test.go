@@ -1,7 +1,6 @@
 package tests
 
 import (
-	"errors"
 	"fmt"
 	"github.com/jeftadlvw/git-nest/models"
 	"testing"
@@ -9,16 +8,58 @@ import (
 
 func TestConfigValidate(t *testing.T) {
 	tests := []struct {
-		config        models.Config
-		expectedError error
+		config models.Config
+		err    bool
 	}{
-		{models.Config{true, true}, nil},
-		{models.Config{false, false}, nil},
+		{models.Config{AllowDuplicateOrigins: true, AllowUnequalRoots: true}, false},
+		{models.Config{AllowDuplicateOrigins: false, AllowUnequalRoots: false}, false},
+		{models.Config{IgnoreMode: models.IgnoreModeExclude}, false},
+		{models.Config{IgnoreMode: models.IgnoreModeGitignore, GitignoreLocation: models.GitignoreLocationRoot}, false},
+		{models.Config{IgnoreMode: models.IgnoreModeBoth, GitignoreLocation: models.GitignoreLocationDirectory}, false},
+		{models.Config{IgnoreMode: "foo"}, true},
+		{models.Config{GitignoreLocation: "foo"}, true},
+		{models.Config{Credentials: []models.Credential{{Host: "example.com", TokenEnv: "TOKEN"}, {Host: "example.org", Helper: "store"}}}, false},
+		{models.Config{Credentials: []models.Credential{{Host: "example.com"}}}, true},
+		{models.Config{Credentials: []models.Credential{{Host: "example.com", TokenEnv: "TOKEN"}, {Host: "Example.com", Helper: "store"}}}, true},
+		{models.Config{UrlRewrites: []models.UrlRewrite{{Prefix: "https://github.com/", Replacement: "https://mirror.example.com/"}}}, false},
+		{models.Config{UrlRewrites: []models.UrlRewrite{{Prefix: "https://github.com/"}}}, true},
+		{models.Config{UrlRewrites: []models.UrlRewrite{{Prefix: "https://github.com/", Replacement: "a"}, {Prefix: "https://github.com/", Replacement: "b"}}}, true},
 	}
 	for index, tc := range tests {
 		t.Run(fmt.Sprintf("TestConfigValidate-%d", index+1), func(t *testing.T) {
-			if got := tc.config.Validate(); !errors.Is(got, tc.expectedError) {
-				t.Fatalf("invalid error %v, expected %v", got, tc.expectedError)
+			err := tc.config.Validate()
+			if tc.err && err == nil {
+				t.Fatalf("no error, but expected one")
+			}
+			if !tc.err && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+		})
+	}
+}
+
+func TestConfigIgnoreMode(t *testing.T) {
+	tests := []struct {
+		config          models.Config
+		usesGitExclude  bool
+		usesGitignore   bool
+		gitignorePerDir bool
+	}{
+		{models.Config{}, true, false, false},
+		{models.Config{IgnoreMode: models.IgnoreModeExclude}, true, false, false},
+		{models.Config{IgnoreMode: models.IgnoreModeGitignore}, false, true, false},
+		{models.Config{IgnoreMode: models.IgnoreModeBoth, GitignoreLocation: models.GitignoreLocationDirectory}, true, true, true},
+	}
+	for index, tc := range tests {
+		t.Run(fmt.Sprintf("TestConfigIgnoreMode-%d", index+1), func(t *testing.T) {
+			if tc.config.UsesGitExclude() != tc.usesGitExclude {
+				t.Fatalf("UsesGitExclude() = %t, expected %t", tc.config.UsesGitExclude(), tc.usesGitExclude)
+			}
+			if tc.config.UsesGitignore() != tc.usesGitignore {
+				t.Fatalf("UsesGitignore() = %t, expected %t", tc.config.UsesGitignore(), tc.usesGitignore)
+			}
+			if tc.config.GitignorePerDirectory() != tc.gitignorePerDir {
+				t.Fatalf("GitignorePerDirectory() = %t, expected %t", tc.config.GitignorePerDirectory(), tc.gitignorePerDir)
 			}
 		})
 	}
@@ -0,0 +1,45 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/models"
+	"testing"
+)
+
+func TestCredentialValidate(t *testing.T) {
+	tests := []struct {
+		credential models.Credential
+		err        bool
+	}{
+		{models.Credential{Host: "example.com", Helper: "store"}, false},
+		{models.Credential{Host: "example.com:8443", TokenEnv: "EXAMPLE_TOKEN"}, false},
+		{models.Credential{Host: "example.com", TokenEnv: "EXAMPLE_TOKEN", Username: "ci"}, false},
+		{models.Credential{Host: "", Helper: "store"}, true},
+		{models.Credential{Host: "https://example.com", Helper: "store"}, true},
+		{models.Credential{Host: "user@example.com", Helper: "store"}, true},
+		{models.Credential{Host: "example.com"}, true},
+		{models.Credential{Host: "example.com", Helper: "store", TokenEnv: "EXAMPLE_TOKEN"}, true},
+		{models.Credential{Host: "example.com", TokenEnv: "$EXAMPLE_TOKEN"}, true},
+		{models.Credential{Host: "example.com", TokenEnv: "EXAMPLE TOKEN"}, true},
+		{models.Credential{Host: "example.com", Helper: "store", Username: "ci"}, true},
+		{models.Credential{Host: "example.com", Helper: "store --file=.credentials"}, false},
+		{models.Credential{Host: "example.com", Helper: "cache --timeout=3600"}, false},
+		{models.Credential{Host: "example.com", Helper: "!gh auth git-credential"}, true},
+		{models.Credential{Host: "example.com", Helper: " !echo password=secret"}, true},
+		{models.Credential{Host: "example.com", Helper: "/usr/bin/credential-helper"}, true},
+		{models.Credential{Host: "example.com", Helper: "store; touch pwned"}, true},
+		{models.Credential{Host: "example.com", Helper: "store --file=$(touch pwned)"}, true},
+	}
+
+	for index, tc := range tests {
+		t.Run(fmt.Sprintf("TestCredentialValidate-%d", index+1), func(t *testing.T) {
+			err := tc.credential.Validate()
+			if tc.err && err == nil {
+				t.Fatalf("no error, but expected one")
+			}
+			if !tc.err && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+		})
+	}
+}
@@ -165,6 +165,25 @@ func TestNestConfigValidate(t *testing.T) {
 			},
 			err: false,
 		},
+
+		// equivalent submodule urls with specified dirs; invalid
+		{
+			name: "equivalent submodule urls with specified dirs",
+			config: models.NestConfig{
+				Config: models.Config{},
+				Submodules: []models.Submodule{
+					{
+						Path: "foo",
+						Url:  &urls.HttpUrl{HostnameS: "example.com", Port: 443, PathS: "path", Secure: true},
+					},
+					{
+						Path: "bar",
+						Url:  &urls.HttpUrl{HostnameS: "Example.com", Port: 443, PathS: "path.git", Secure: true},
+					},
+				},
+			},
+			err: true,
+		},
 	}
 
 	for index, tc := range cases {
@@ -0,0 +1,96 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/models/urls"
+	"testing"
+)
+
+func TestPolicyValidate(t *testing.T) {
+	cases := []struct {
+		policy models.Policy
+		err    bool
+	}{
+		{models.Policy{}, false},
+		{models.Policy{AllowedHosts: []string{"github.com", "*.example.com"}, AllowedUrls: []string{"https://github.com/org/*"}, RequiredRefKinds: []models.RefKind{models.RefKindTag, models.RefKindCommit}, ForbiddenPaths: []string{"vendor"}}, false},
+		{models.Policy{AllowedHosts: []string{" "}}, true},
+		{models.Policy{AllowedUrls: []string{"https://github.com/[org"}}, true},
+		{models.Policy{ForbiddenPaths: []string{"vendor\\"}}, true},
+		{models.Policy{RequiredRefKinds: []models.RefKind{""}}, true},
+		{models.Policy{RequiredRefKinds: []models.RefKind{"revision"}}, true},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestPolicyValidate-%d", index+1), func(t *testing.T) {
+			err := tc.policy.Validate()
+			if tc.err && err == nil {
+				t.Fatalf("no error, but expected one")
+			}
+			if !tc.err && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+		})
+	}
+}
+
+func TestPolicyViolations(t *testing.T) {
+	githubUrl, err := urls.HttpUrlFromString("https://github.com/org/repository.git")
+	if err != nil {
+		t.Fatal(err)
+	}
+	internalUrl, err := urls.HttpUrlFromString("https://Git.Example.com:8443/team/repository")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hostPolicy := models.Policy{AllowedHosts: []string{"*.example.com"}}
+	urlPolicy := models.Policy{AllowedUrls: []string{"https://github.com/org/*"}}
+	refPolicy := models.Policy{RequiredRefKinds: []models.RefKind{models.RefKindTag, models.RefKindCommit}}
+	pathPolicy := models.Policy{ForbiddenPaths: []string{"vendor", "*/generated"}}
+
+	cases := []struct {
+		policy     models.Policy
+		submodule  models.Submodule
+		kind       models.RefKind
+		violations int
+	}{
+		{models.Policy{}, models.Submodule{Path: "lib", Url: &githubUrl}, models.RefKindAuto, 0},
+		{hostPolicy, models.Submodule{Path: "lib", Url: &internalUrl}, models.RefKindAuto, 0},
+		{hostPolicy, models.Submodule{Path: "lib", Url: &githubUrl}, models.RefKindAuto, 1},
+		{hostPolicy, models.Submodule{Path: "lib", Url: &internalUrl, Remotes: map[string]string{"upstream": "git@github.com:org/repository.git", "mirror": "git@mirror.example.com:team/repository.git"}}, models.RefKindAuto, 1},
+		{urlPolicy, models.Submodule{Path: "lib", Url: &githubUrl}, models.RefKindAuto, 0},
+		{urlPolicy, models.Submodule{Path: "lib", Url: &internalUrl}, models.RefKindAuto, 1},
+		{models.Policy{AllowedUrls: []string{"https://GitHub.com/org/*"}}, models.Submodule{Path: "lib", Url: &githubUrl}, models.RefKindAuto, 0},
+		{models.Policy{AllowedUrls: []string{"HTTPS://github.com:443/org/*/"}}, models.Submodule{Path: "lib", Url: &githubUrl}, models.RefKindAuto, 0},
+		{models.Policy{AllowedUrls: []string{"https://GIT.example.com:8443/team/*"}}, models.Submodule{Path: "lib", Url: &internalUrl}, models.RefKindAuto, 0},
+		{models.Policy{AllowedUrls: []string{"https://git.example.com/team/*"}}, models.Submodule{Path: "lib", Url: &internalUrl}, models.RefKindAuto, 1},
+		{models.Policy{AllowedUrls: []string{"https://GitHub.com/Org/*"}}, models.Submodule{Path: "lib", Url: &githubUrl}, models.RefKindAuto, 1},
+		{models.Policy{AllowedHosts: hostPolicy.AllowedHosts, AllowedUrls: urlPolicy.AllowedUrls}, models.Submodule{Path: "lib", Url: &githubUrl, Remotes: map[string]string{"fork": "https://github.com/someone/repository"}}, models.RefKindAuto, 1},
+		{refPolicy, models.Submodule{Path: "lib", Url: &githubUrl, Ref: "v1.0.0"}, models.RefKindTag, 0},
+		{refPolicy, models.Submodule{Path: "lib", Url: &githubUrl, Ref: "d9c591c"}, models.RefKindCommit, 0},
+		{refPolicy, models.Submodule{Path: "lib", Url: &githubUrl, Ref: "main"}, models.RefKindBranch, 1},
+		{refPolicy, models.Submodule{Path: "lib", Url: &githubUrl, Ref: "unknown"}, models.RefKindAuto, 1},
+		{refPolicy, models.Submodule{Path: "lib", Url: &githubUrl}, models.RefKindAuto, 1},
+		{pathPolicy, models.Submodule{Path: "vendor", Url: &githubUrl}, models.RefKindAuto, 1},
+		{pathPolicy, models.Submodule{Path: "vendor/lib", Url: &githubUrl}, models.RefKindAuto, 1},
+		{pathPolicy, models.Submodule{Path: "src/generated/lib", Url: &githubUrl}, models.RefKindAuto, 1},
+		{pathPolicy, models.Submodule{Path: "lib/vendor", Url: &githubUrl}, models.RefKindAuto, 0},
+		{models.Policy{AllowedHosts: hostPolicy.AllowedHosts, RequiredRefKinds: refPolicy.RequiredRefKinds, ForbiddenPaths: pathPolicy.ForbiddenPaths}, models.Submodule{Path: "vendor/lib", Url: &githubUrl, Ref: "main"}, models.RefKindBranch, 3},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestPolicyViolations-%d", index+1), func(t *testing.T) {
+			violations := tc.policy.Violations(tc.submodule, tc.kind)
+			if len(violations) != tc.violations {
+				t.Fatalf("expected %d violations, got %d: %v", tc.violations, len(violations), violations)
+			}
+
+			for _, violation := range violations {
+				if violation.Submodule != tc.submodule.Path.UnixString() {
+					t.Fatalf("violation does not reference submodule: %s", violation)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/models"
+	"testing"
+)
+
+func TestRefKindValidate(t *testing.T) {
+	cases := []struct {
+		kind models.RefKind
+		err  bool
+	}{
+		{models.RefKindAuto, false},
+		{models.RefKindBranch, false},
+		{models.RefKindTag, false},
+		{models.RefKindCommit, false},
+		{"Branch", true},
+		{"revision", true},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestRefKindValidate-%d", index+1), func(t *testing.T) {
+			err := tc.kind.Validate()
+			if tc.err && err == nil {
+				t.Fatalf("no error, but expected one")
+			}
+			if !tc.err && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+		})
+	}
+}
+
+func TestCommitHashMatches(t *testing.T) {
+	const commit = "d9c591ca90aa1cedda54d1d6ebb45be3b52e5d6e"
+
+	cases := []struct {
+		ref      string
+		isHash   bool
+		expected bool
+	}{
+		{commit, true, true},
+		{"d9c591c", true, true},
+		{"D9C591C", true, true},
+		{"d9c5", true, true},
+		{"d9c", false, false},
+		{"d9c591d", true, false},
+		{"main", false, false},
+		{"", false, false},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestCommitHashMatches-%d", index+1), func(t *testing.T) {
+			if isHash := models.IsCommitHash(tc.ref); isHash != tc.isHash {
+				t.Fatalf("unexpected IsCommitHash result %t", isHash)
+			}
+			if matches := models.CommitHashMatches(commit, tc.ref); matches != tc.expected {
+				t.Fatalf("unexpected CommitHashMatches result %t", matches)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/models"
+	"testing"
+	"time"
+)
+
+func TestRetryValidate(t *testing.T) {
+	tests := []struct {
+		retry models.Retry
+		err   bool
+	}{
+		{models.Retry{}, false},
+		{models.Retry{Attempts: 5, Backoff: "1s", MaxBackoff: "1m", RetryOn: []models.GitErrorClass{models.GitErrorClassNetwork, models.GitErrorClassAuthentication}}, false},
+		{models.Retry{Attempts: -1}, true},
+		{models.Retry{Backoff: "soon"}, true},
+		{models.Retry{MaxBackoff: "-5s"}, true},
+		{models.Retry{RetryOn: []models.GitErrorClass{"dns"}}, true},
+	}
+
+	for index, tc := range tests {
+		t.Run(fmt.Sprintf("TestRetryValidate-%d", index+1), func(t *testing.T) {
+			err := tc.retry.Validate()
+			if tc.err && err == nil {
+				t.Fatalf("no error, but expected one")
+			}
+			if !tc.err && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy(t *testing.T) {
+	defaults := models.Retry{}.RetryPolicy()
+	if defaults.Attempts != models.DefaultRetryAttempts || !defaults.Retries(models.GitErrorClassNetwork) || defaults.Retries(models.GitErrorClassAuthentication) {
+		t.Fatalf("unexpected default policy: %+v", defaults)
+	}
+
+	tests := []struct {
+		retry  models.Retry
+		delays []time.Duration
+	}{
+		{models.Retry{Attempts: 5}, []time.Duration{2 * time.Second, 4 * time.Second, 8 * time.Second, 16 * time.Second}},
+		{models.Retry{Backoff: "1s", MaxBackoff: "3s"}, []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second}},
+		{models.Retry{Backoff: "1s", MaxBackoff: "0"}, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second}},
+		{models.Retry{Backoff: "0"}, []time.Duration{0, 0, 0}},
+	}
+
+	for index, tc := range tests {
+		t.Run(fmt.Sprintf("TestRetryPolicy-%d", index+1), func(t *testing.T) {
+			policy := tc.retry.RetryPolicy()
+			for retry, expected := range tc.delays {
+				if delay := policy.Delay(retry + 1); delay != expected {
+					t.Fatalf("unexpected delay before retry %d: %s, expected %s", retry+1, delay, expected)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/models"
+	"path/filepath"
+	"testing"
+)
+
+func TestConfigSignatureVerification(t *testing.T) {
+	root := models.Path(filepath.FromSlash("/project"))
+	absolute := filepath.FromSlash("/keys/allowed_signers")
+
+	cases := []struct {
+		config    models.Config
+		submodule models.Submodule
+		expected  models.SignatureVerification
+		err       bool
+	}{
+		{models.Config{}, models.Submodule{}, models.SignatureVerification{}, false},
+		{
+			models.Config{VerifySignature: true, AllowedSigners: "keys/allowed_signers"},
+			models.Submodule{},
+			models.SignatureVerification{Enabled: true, AllowedSigners: models.Path(filepath.FromSlash("/project/keys/allowed_signers"))},
+			false,
+		},
+		{
+			models.Config{AllowedSigners: "keys/allowed_signers", GpgHome: "keys/gnupg"},
+			models.Submodule{VerifySignature: true, AllowedSigners: absolute},
+			models.SignatureVerification{Enabled: true, AllowedSigners: models.Path(absolute), GpgHome: models.Path(filepath.FromSlash("/project/keys/gnupg"))},
+			false,
+		},
+		{models.Config{VerifySignature: true}, models.Submodule{}, models.SignatureVerification{Enabled: true}, true},
+		{models.Config{}, models.Submodule{VerifySignature: true}, models.SignatureVerification{Enabled: true}, true},
+		{models.Config{AllowedSigners: "keys/allowed_signers"}, models.Submodule{}, models.SignatureVerification{AllowedSigners: models.Path(filepath.FromSlash("/project/keys/allowed_signers"))}, false},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestConfigSignatureVerification-%d", index+1), func(t *testing.T) {
+			verification := tc.config.SignatureVerification(tc.submodule, root)
+			if verification != tc.expected {
+				t.Fatalf("unexpected verification %+v, expected %+v", verification, tc.expected)
+			}
+
+			err := verification.Validate()
+			if tc.err && err == nil {
+				t.Fatalf("no error, but expected one")
+			}
+			if !tc.err && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/models"
+	"testing"
+	"time"
+)
+
+func TestTimeoutsValidate(t *testing.T) {
+	tests := []struct {
+		timeouts models.Timeouts
+		err      bool
+	}{
+		{models.Timeouts{}, false},
+		{models.Timeouts{Clone: "1h", Fetch: "90s", LsRemote: "0", Local: "500ms"}, false},
+		{models.Timeouts{Clone: "30"}, true},
+		{models.Timeouts{Fetch: "ten minutes"}, true},
+		{models.Timeouts{Local: "-1s"}, true},
+	}
+
+	for index, tc := range tests {
+		t.Run(fmt.Sprintf("TestTimeoutsValidate-%d", index+1), func(t *testing.T) {
+			err := tc.timeouts.Validate()
+			if tc.err && err == nil {
+				t.Fatalf("no error, but expected one")
+			}
+			if !tc.err && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+		})
+	}
+}
+
+func TestTimeoutsGitTimeouts(t *testing.T) {
+	tests := []struct {
+		timeouts models.Timeouts
+		expected models.GitTimeouts
+	}{
+		{models.Timeouts{}, models.GitTimeouts{Clone: models.DefaultCloneTimeout, Fetch: models.DefaultFetchTimeout, LsRemote: models.DefaultLsRemoteTimeout}},
+		{models.Timeouts{Clone: "0", Fetch: " 90s ", Local: "1m"}, models.GitTimeouts{Fetch: 90 * time.Second, LsRemote: models.DefaultLsRemoteTimeout, Local: time.Minute}},
+	}
+
+	for index, tc := range tests {
+		t.Run(fmt.Sprintf("TestTimeoutsGitTimeouts-%d", index+1), func(t *testing.T) {
+			actual := tc.timeouts.GitTimeouts()
+			if actual != tc.expected {
+				t.Fatalf("unexpected timeouts: %+v, expected %+v", actual, tc.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/models"
+	"testing"
+)
+
+func TestUrlRewriteValidate(t *testing.T) {
+	tests := []struct {
+		rewrite models.UrlRewrite
+		err     bool
+	}{
+		{models.UrlRewrite{Prefix: "https://github.com/", Replacement: "https://git-mirror.example.com/github/"}, false},
+		{models.UrlRewrite{Prefix: "", Replacement: "https://git-mirror.example.com/github/"}, true},
+		{models.UrlRewrite{Prefix: "https://github.com/", Replacement: "  "}, true},
+	}
+
+	for index, tc := range tests {
+		t.Run(fmt.Sprintf("TestUrlRewriteValidate-%d", index+1), func(t *testing.T) {
+			err := tc.rewrite.Validate()
+			if tc.err && err == nil {
+				t.Fatalf("no error, but expected one")
+			}
+			if !tc.err && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+		})
+	}
+}
+
+func TestRewriteUrl(t *testing.T) {
+	rewrites := []models.UrlRewrite{
+		{Prefix: "https://github.com/", Replacement: "https://git-mirror.example.com/github/"},
+		{Prefix: "https://github.com/org/", Replacement: "https://org-mirror.example.com/"},
+		{Prefix: "https://github.com/", Replacement: "https://ignored.example.com/"},
+	}
+
+	tests := []struct {
+		url       string
+		expected  string
+		rewritten bool
+	}{
+		{"https://github.com/user/repository", "https://git-mirror.example.com/github/user/repository", true},
+		{"https://github.com/org/repository", "https://org-mirror.example.com/repository", true},
+		{"https://gitlab.com/user/repository", "https://gitlab.com/user/repository", false},
+		{"", "", false},
+	}
+
+	for index, tc := range tests {
+		t.Run(fmt.Sprintf("TestRewriteUrl-%d", index+1), func(t *testing.T) {
+			result, rewritten := models.RewriteUrl(rewrites, tc.url)
+			if result != tc.expected || rewritten != tc.rewritten {
+				t.Fatalf("RewriteUrl(%s) = (%s, %t), expected (%s, %t)", tc.url, result, rewritten, tc.expected, tc.rewritten)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultCloneTimeout    = 30 * time.Minute
+	DefaultFetchTimeout    = 10 * time.Minute
+	DefaultLsRemoteTimeout = 2 * time.Minute
+)
+
+/*
+Timeouts defines how long git commands may run before they are aborted. Values are durations, e.g. '90s' or '10m'.
+Empty values fall back to their default, '0' disables a timeout.
+*/
+type Timeouts struct {
+	/*
+		Clone contains the timeout for cloning a nested module. Defaults to 30 minutes.
+	*/
+	Clone string `toml:"clone"`
+
+	/*
+		Fetch contains the timeout for fetching and pulling updates of a nested module. Defaults to 10 minutes.
+	*/
+	Fetch string `toml:"fetch"`
+
+	/*
+		LsRemote contains the timeout for querying the refs of a remote repository. Defaults to 2 minutes.
+	*/
+	LsRemote string `toml:"ls_remote"`
+
+	/*
+		Local contains the timeout for git commands that do not contact a remote. Disabled by default.
+	*/
+	Local string `toml:"local"`
+}
+
+/*
+GitTimeouts contains the effective durations of Timeouts. A duration of zero disables the timeout.
+*/
+type GitTimeouts struct {
+	Clone    time.Duration
+	Fetch    time.Duration
+	LsRemote time.Duration
+	Local    time.Duration
+}
+
+/*
+Empty returns whether no timeout differs from its default.
+*/
+func (t Timeouts) Empty() bool {
+	return t.Clone == "" && t.Fetch == "" && t.LsRemote == "" && t.Local == ""
+}
+
+/*
+Validate performs validation on these Timeouts.
+*/
+func (t Timeouts) Validate() error {
+	for _, entry := range t.entries() {
+		if _, err := parseTimeout(entry.value, 0); err != nil {
+			return fmt.Errorf("invalid %s timeout: %w", entry.key, err)
+		}
+	}
+
+	return nil
+}
+
+/*
+GitTimeouts returns the effective durations of these Timeouts. Invalid values fall back to their default.
+*/
+func (t Timeouts) GitTimeouts() GitTimeouts {
+	parse := func(value string, fallback time.Duration) time.Duration {
+		duration, err := parseTimeout(value, fallback)
+		if err != nil {
+			return fallback
+		}
+		return duration
+	}
+
+	return GitTimeouts{
+		Clone:    parse(t.Clone, DefaultCloneTimeout),
+		Fetch:    parse(t.Fetch, DefaultFetchTimeout),
+		LsRemote: parse(t.LsRemote, DefaultLsRemoteTimeout),
+		Local:    parse(t.Local, 0),
+	}
+}
+
+/*
+entries returns the configuration key and value of every timeout.
+*/
+func (t Timeouts) entries() []struct{ key, value string } {
+	return []struct{ key, value string }{
+		{"clone", t.Clone},
+		{"fetch", t.Fetch},
+		{"ls_remote", t.LsRemote},
+		{"local", t.Local},
+	}
+}
+
+/*
+parseTimeout parses a timeout duration, returning fallback for empty values.
+*/
+func parseTimeout(value string, fallback time.Duration) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return fallback, nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("'%s' is not a duration, e.g. '90s' or '10m'", value)
+	}
+
+	if duration < 0 {
+		return 0, fmt.Errorf("'%s' is negative", value)
+	}
+
+	return duration, nil
+}
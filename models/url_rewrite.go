@@ -0,0 +1,64 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+/*
+UrlRewrite replaces the prefix of remote urls whenever git contacts a remote, e.g. to use a mirror.
+The configured url of a nested module is never changed.
+*/
+type UrlRewrite struct {
+	/*
+		Prefix contains the url prefix that is replaced.
+	*/
+	Prefix string `toml:"prefix"`
+
+	/*
+		Replacement contains the value the prefix is replaced with.
+	*/
+	Replacement string `toml:"replacement"`
+}
+
+/*
+Validate performs validation on this UrlRewrite.
+*/
+func (r UrlRewrite) Validate() error {
+	if strings.TrimSpace(r.Prefix) == "" {
+		return errors.New("url rewrite prefix may not be empty")
+	}
+
+	if strings.TrimSpace(r.Replacement) == "" {
+		return fmt.Errorf("url rewrite replacement for %s may not be empty", r.Prefix)
+	}
+
+	return nil
+}
+
+/*
+RewriteUrl applies the UrlRewrite with the longest matching prefix to a url, like git does for 'url.<base>.insteadOf'.
+If multiple rules have the same prefix, the first one is used. The second return value reports whether a rule matched.
+*/
+func RewriteUrl(rewrites []UrlRewrite, url string) (string, bool) {
+	var (
+		match   UrlRewrite
+		matched bool
+	)
+
+	for _, rewrite := range rewrites {
+		if !strings.HasPrefix(url, rewrite.Prefix) || (matched && len(rewrite.Prefix) <= len(match.Prefix)) {
+			continue
+		}
+
+		match = rewrite
+		matched = true
+	}
+
+	if !matched {
+		return url, false
+	}
+
+	return match.Replacement + strings.TrimPrefix(url, match.Prefix), true
+}
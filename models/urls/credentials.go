@@ -0,0 +1,35 @@
+package urls
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+/*
+RedactedCredentials is the placeholder that replaces credentials embedded in urls.
+*/
+const RedactedCredentials = "***"
+
+var httpCredentialsPattern = regexp.MustCompile(`(?i)(https?://)[^/@\s'"]+@`)
+
+/*
+ContainsCredentials returns whether the passed http(s) url contains embedded credentials, e.g. a username,
+password or access token in its userinfo section.
+*/
+func ContainsCredentials(s string) bool {
+	u, err := url.Parse(strings.TrimSpace(s))
+	if err != nil {
+		return httpCredentialsPattern.MatchString(s)
+	}
+
+	return strings.HasPrefix(strings.ToLower(u.Scheme), "http") && u.User != nil
+}
+
+/*
+RedactCredentials replaces credentials embedded into any http(s) urls within the passed string.
+It can be used for single urls as well as for longer texts like command output.
+*/
+func RedactCredentials(s string) string {
+	return httpCredentialsPattern.ReplaceAllString(s, "${1}"+RedactedCredentials+"@")
+}
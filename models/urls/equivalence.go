@@ -0,0 +1,134 @@
+package urls
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+/*
+defaultPorts contains the ports that are omitted in canonical urls.
+*/
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+	"ssh":   "22",
+}
+
+/*
+CanonicalUrl returns a normalized representation of a http(s) or ssh remote url that is used to compare urls.
+Hostnames are lower-cased, and credentials, default ports, trailing slashes and a '.git' suffix are removed.
+If ignoreScheme is set, the scheme is omitted so that ssh and http(s) urls to the same host and path are equal.
+*/
+func CanonicalUrl(s string, ignoreScheme bool) (string, error) {
+	var (
+		scheme string
+		host   string
+		port   string
+		path   string
+	)
+
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", errors.New("empty url")
+	}
+
+	if strings.Contains(s, "://") {
+		u, err := url.Parse(s)
+		if err != nil {
+			return "", err
+		}
+
+		scheme = strings.ToLower(u.Scheme)
+		if scheme == "git+ssh" || scheme == "ssh+git" {
+			scheme = "ssh"
+		}
+		if _, ok := defaultPorts[scheme]; !ok {
+			return "", fmt.Errorf("unsupported url scheme %s", u.Scheme)
+		}
+
+		host = u.Hostname()
+		port = u.Port()
+		path = u.Path
+	} else {
+		// scp-like syntax, e.g. git@example.com:path/to/repository.git
+		sshUrl, err := SshUrlFromString(s)
+		if err != nil {
+			return "", err
+		}
+
+		scheme = "ssh"
+		host = sshUrl.HostnameS
+		path = sshUrl.PathS
+	}
+
+	host = strings.ToLower(strings.TrimSpace(host))
+	if host == "" {
+		return "", errors.New("url does not contain a hostname")
+	}
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if port != "" && port != defaultPorts[scheme] {
+		host = host + ":" + port
+	}
+
+	path = strings.Trim(strings.TrimSpace(path), "/")
+	path = strings.TrimSuffix(path, ".git")
+	path = strings.TrimRight(path, "/")
+
+	if ignoreScheme {
+		return host + "/" + path, nil
+	}
+
+	return scheme + "://" + host + "/" + path, nil
+}
+
+/*
+CanonicalUrlPattern returns the representation of a url pattern, e.g. 'https://GitHub.com:443/org/*', that is matched
+against urls returned by CanonicalUrl. Like there, scheme and hostname are lower-cased, and credentials, default ports,
+trailing slashes and a '.git' suffix are removed. Wildcards are kept as-is.
+*/
+func CanonicalUrlPattern(pattern string) string {
+	pattern = strings.TrimSpace(pattern)
+
+	scheme, rest, found := strings.Cut(pattern, "://")
+	if !found {
+		return strings.TrimSuffix(strings.TrimRight(pattern, "/"), ".git")
+	}
+
+	scheme = strings.ToLower(scheme)
+	if scheme == "git+ssh" || scheme == "ssh+git" {
+		scheme = "ssh"
+	}
+
+	host, path, _ := strings.Cut(rest, "/")
+	if index := strings.LastIndex(host, "@"); index != -1 {
+		host = host[index+1:]
+	}
+	host = strings.ToLower(host)
+	if port, ok := defaultPorts[scheme]; ok {
+		host = strings.TrimSuffix(host, ":"+port)
+	}
+
+	path = strings.Trim(strings.TrimSpace(path), "/")
+	path = strings.TrimSuffix(path, ".git")
+	path = strings.TrimRight(path, "/")
+
+	return scheme + "://" + host + "/" + path
+}
+
+/*
+Equivalent returns whether two remote urls point to the same repository, comparing their CanonicalUrl.
+Urls that cannot be normalized are compared by their exact string.
+*/
+func Equivalent(a string, b string, ignoreScheme bool) bool {
+	canonicalA, errA := CanonicalUrl(a, ignoreScheme)
+	canonicalB, errB := CanonicalUrl(b, ignoreScheme)
+	if errA != nil || errB != nil {
+		return strings.TrimSpace(a) == strings.TrimSpace(b)
+	}
+
+	return canonicalA == canonicalB
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/jeftadlvw/git-nest/models"
 	test_env_models "github.com/jeftadlvw/git-nest/test_env/models"
 	"github.com/jeftadlvw/git-nest/utils"
+	"os"
 	"path/filepath"
 	"strings"
 )
@@ -73,3 +74,74 @@ func CreateTestEnvironment(root models.Path, settings test_env_models.EnvSetting
 
 	return nil
 }
+
+/*
+CreateLocalRepository initializes a git repository at the passed path that does not depend on any remote.
+Each passed commit message creates an empty commit on the default branch.
+*/
+func CreateLocalRepository(p models.Path, commitMessages ...string) error {
+	err := os.MkdirAll(p.String(), os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("unable to create repository directory: %w", err)
+	}
+
+	commands := [][]string{
+		{"init", "--initial-branch", RepoBranchDefault},
+		{"config", "user.name", "foo"},
+		{"config", "user.email", "foo@email.com"},
+		{"config", "commit.gpgsign", "false"},
+	}
+
+	for _, message := range commitMessages {
+		commands = append(commands, []string{"commit", "--allow-empty", "-m", message})
+	}
+
+	for _, args := range commands {
+		out, err := utils.RunCommandCombinedOutput(p, "git", args...)
+		if err != nil {
+			return fmt.Errorf("error running git %s at %s: %w; %s", args[0], p, err, out)
+		}
+	}
+
+	return nil
+}
+
+/*
+CreateSshSigningKey generates a passphrase-less ed25519 ssh key named name within dir and an ssh allowed signers file
+that trusts it for principal. Returns the paths to the private key and to the allowed signers file.
+*/
+func CreateSshSigningKey(dir models.Path, name string, principal string) (models.Path, models.Path, error) {
+	key := dir.Join(models.Path(name))
+	allowedSigners := dir.Join(models.Path(name + ".allowed_signers"))
+
+	out, err := utils.RunCommandCombinedOutput(dir, "ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-C", principal, "-f", key.String())
+	if err != nil {
+		return "", "", fmt.Errorf("error generating ssh key: %w; %s", err, out)
+	}
+
+	publicKey, err := utils.ReadFileToStr(models.Path(key.String() + ".pub"))
+	if err != nil {
+		return "", "", fmt.Errorf("error reading public key: %w", err)
+	}
+
+	err = utils.WriteStrToFile(allowedSigners, principal+" "+strings.TrimSpace(publicKey)+"\n")
+	if err != nil {
+		return "", "", fmt.Errorf("error writing allowed signers file: %w", err)
+	}
+
+	return key, allowedSigners, nil
+}
+
+/*
+ConfigureSshSigning configures a repository to sign commits and tags with the passed ssh key.
+*/
+func ConfigureSshSigning(repository models.Path, key models.Path) error {
+	for _, args := range [][]string{{"config", "gpg.format", "ssh"}, {"config", "user.signingkey", key.String()}} {
+		out, err := utils.RunCommandCombinedOutput(repository, "git", args...)
+		if err != nil {
+			return fmt.Errorf("error running git %s: %w; %s", args[0], err, out)
+		}
+	}
+
+	return nil
+}
@@ -6,6 +6,8 @@ import (
 	"github.com/jeftadlvw/git-nest/models"
 	"io"
 	"os"
+	"slices"
+	"strings"
 )
 
 /*
@@ -38,3 +40,53 @@ func CalculateChecksumF(path models.Path) (string, error) {
 
 	return fmt.Sprintf("%x", h.Sum(nil)), nil
 }
+
+/*
+CalculateChecksumFiles calculates a combined checksum of multiple files that are passed relative to root.
+Paths, contents and the executable bit of each file contribute to the checksum, so that added, removed, renamed
+and changed files change it. Symbolic links contribute their target, directories their name and files that do not
+exist are recorded as missing.
+*/
+func CalculateChecksumFiles(root models.Path, files ...string) (string, error) {
+	sortedFiles := slices.Clone(files)
+	slices.Sort(sortedFiles)
+
+	var sb strings.Builder
+	for _, file := range slices.Compact(sortedFiles) {
+		filePath := root.SJoin(file)
+
+		entry := ""
+		info, err := os.Lstat(filePath.String())
+		switch {
+		case os.IsNotExist(err):
+			entry = "missing"
+		case err != nil:
+			return "", err
+		case info.Mode()&os.ModeSymlink != 0:
+			target, err := os.Readlink(filePath.String())
+			if err != nil {
+				return "", err
+			}
+			entry = "link " + target
+		case info.IsDir():
+			entry = "directory"
+		default:
+			checksum, err := CalculateChecksumF(filePath)
+			if err != nil {
+				return "", err
+			}
+
+			entry = "file " + checksum
+			if info.Mode()&0111 != 0 {
+				entry = "executable " + checksum
+			}
+		}
+
+		sb.WriteString(file)
+		sb.WriteString("\x00")
+		sb.WriteString(entry)
+		sb.WriteString("\n")
+	}
+
+	return CalculateChecksumS(sb.String()), nil
+}
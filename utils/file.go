@@ -1,9 +1,11 @@
 package utils
 
 import (
+	"errors"
 	"fmt"
 	"github.com/jeftadlvw/git-nest/models"
 	"os"
+	"path/filepath"
 )
 
 /*
@@ -32,3 +34,60 @@ func WriteStrToFile(path models.Path, str string) error {
 
 	return os.WriteFile(path.String(), []byte(str), 0644)
 }
+
+/*
+WriteStrToFileAtomic writes a string to a file without ever leaving it partially written: the content is written to a
+temporary file in the same directory, synced to disk and renamed over the original file. The permissions of an existing
+file are kept, and symbolic links are followed, so that their target is replaced instead of the link itself.
+*/
+func WriteStrToFileAtomic(path models.Path, str string) error {
+	if path.IsDir() {
+		return fmt.Errorf("%s is a directory", path.String())
+	}
+
+	target := path.String()
+	if resolved, err := filepath.EvalSymlinks(target); err == nil {
+		target = resolved
+	}
+
+	perm := os.FileMode(0644)
+	if info, err := os.Stat(target); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	dir, base := filepath.Split(target)
+	if dir == "" {
+		dir = "."
+	}
+
+	tempFile, err := os.CreateTemp(dir, "."+base+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("could not create temporary file: %w", err)
+	}
+	tempName := tempFile.Name()
+
+	_, err = tempFile.WriteString(str)
+	if err == nil {
+		err = tempFile.Sync()
+	}
+	err = errors.Join(err, tempFile.Close())
+	if err == nil {
+		err = os.Chmod(tempName, perm)
+	}
+	if err == nil {
+		err = os.Rename(tempName, target)
+	}
+
+	if err != nil {
+		_ = os.Remove(tempName)
+		return fmt.Errorf("could not write %s: %w", path.String(), err)
+	}
+
+	// persist the rename itself, not supported on every platform
+	if d, err := os.Open(dir); err == nil {
+		_ = d.Sync()
+		_ = d.Close()
+	}
+
+	return nil
+}
@@ -1,11 +1,18 @@
 package utils
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/models/urls"
+	"io"
+	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 )
 
@@ -13,6 +20,14 @@ import (
 CloneGitRepository clones a remote git repository.
 */
 func CloneGitRepository(url string, p models.Path, cloneDirName string, liveOutput func(string)) error {
+	return CloneGitRepositoryContext(commandContext, url, p, cloneDirName, liveOutput)
+}
+
+/*
+CloneGitRepositoryContext is like CloneGitRepository, but aborts the clone if ctx is done or the clone timeout is exceeded.
+The clone directory of an aborted clone is removed.
+*/
+func CloneGitRepositoryContext(ctx context.Context, url string, p models.Path, cloneDirName string, liveOutput func(string)) error {
 	url = strings.TrimSpace(url)
 	if url == "" {
 		return fmt.Errorf("git repository url is empty")
@@ -28,9 +43,22 @@ func CloneGitRepository(url string, p models.Path, cloneDirName string, liveOutp
 		return fmt.Errorf("%s does not exist", p)
 	}
 
-	commandArgsArr := []string{"clone", "--progress", url}
+	cloneArgs := []string{"clone", "--progress", url}
 	if cloneDirName != "" {
-		commandArgsArr = append(commandArgsArr, cloneDirName)
+		cloneArgs = append(cloneArgs, cloneDirName)
+	}
+
+	// only directories created by this clone are removed if an attempt fails or the clone is aborted
+	var partialClone models.Path
+	cloneDir := p.SJoin(gitCloneDirectoryName(url, cloneDirName))
+	if !cloneDir.Exists() {
+		partialClone = cloneDir
+	}
+
+	removePartialClone := func() {
+		if !partialClone.Empty() {
+			_ = os.RemoveAll(partialClone.String())
+		}
 	}
 
 	outputBuilder := strings.Builder{}
@@ -42,22 +70,52 @@ func CloneGitRepository(url string, p models.Path, cloneDirName string, liveOutp
 		}
 	}
 
-	err := RunCommandLiveOutputCombinedOutput(liveOutputFunc, p, "git", commandArgsArr...)
+	output, attempts, err := retryGitOperation(ctx, "git clone", removePartialClone, func(ctx context.Context) (string, error) {
+		ctx, cancel := contextWithTimeout(ctx, gitTimeouts.Clone)
+		defer cancel()
 
-	output := outputBuilder.String()
-	if err != nil {
-		return fmt.Errorf("error running git clone: %w; output: %s", err, output)
+		outputBuilder.Reset()
+		err := RunCommandLiveOutputCombinedOutputContext(ctx, liveOutputFunc, p, "git", gitRemoteCommandArgs(cloneArgs...)...)
+		if ctxErr := commandContextError(ctx, "git clone", gitTimeouts.Clone); err != nil && ctxErr != nil {
+			err = ctxErr
+		}
+
+		return urls.RedactCredentials(outputBuilder.String()), err
+	})
+
+	if err == nil {
+		return nil
 	}
 
-	if strings.Contains(output, "ERROR: Repository not found.") {
-		return fmt.Errorf("remote repository %s does not exist", url)
+	var message string
+	kind := ClassifyGitOutput(output)
+	switch {
+	case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+		removePartialClone()
+		kind, message = nil, err.Error()
+	case kind == ErrAuthRequired:
+		message = gitAuthenticationMessage(url)
+	case kind == ErrRepositoryNotFound:
+		message = fmt.Sprintf("remote repository %s does not exist", urls.RedactCredentials(url))
+	case kind == ErrDestinationExists:
+		message = "destination path already exists"
 	}
 
-	if strings.Contains(output, "fatal: destination path") {
-		return fmt.Errorf("destination path already exists")
+	return withAttempts(newGitError(kind, message, p, cloneArgs, output, err), attempts)
+}
+
+/*
+gitCloneDirectoryName returns the name of the directory git clones into, which is derived from the url
+if no clone directory name is passed.
+*/
+func gitCloneDirectoryName(url string, cloneDirName string) string {
+	if cloneDirName != "" {
+		return cloneDirName
 	}
 
-	return nil
+	name := strings.TrimSuffix(strings.TrimRight(url, "/"), "/.git")
+	name = name[strings.LastIndexAny(name, "/:")+1:]
+	return strings.TrimSuffix(strings.TrimSuffix(name, ".git"), ".bundle")
 }
 
 /*
@@ -81,27 +139,35 @@ func GitCheckout(repository models.Path, ref string) error {
 		return fmt.Errorf("%s is not a directory", repository)
 	}
 
-	output, err := RunCommandCombinedOutput(repository, "git", "checkout", ref, "--progress")
-
-	if strings.Contains(output, "fatal: not a git repository") {
-		return fmt.Errorf("%s is not a git repository", repository)
+	args := []string{"checkout", ref, "--progress"}
+	output, err := RunCommandCombinedOutput(repository, "git", args...)
+	if err == nil {
+		return nil
 	}
 
-	if strings.Contains(output, "error: pathspec") {
-		return fmt.Errorf("ref '%s' does not exist", ref)
-	}
-
-	if err != nil {
-		return fmt.Errorf("error running git checkout: %w; output: %s", err, output)
+	var message string
+	kind := ClassifyGitOutput(output)
+	switch kind {
+	case ErrNotARepository:
+		message = fmt.Sprintf("%s is not a git repository", repository)
+	case ErrRefNotFound:
+		message = fmt.Sprintf("ref '%s' does not exist", ref)
 	}
 
-	return nil
+	return newGitError(kind, message, repository, args, output, err)
 }
 
 /*
 GitPull changes a local repository's HEAD.
 */
 func GitPull(repository models.Path, liveOutput func(string)) error {
+	return GitPullContext(commandContext, repository, liveOutput)
+}
+
+/*
+GitPullContext is like GitPull, but aborts the pull if ctx is done or the fetch timeout is exceeded.
+*/
+func GitPullContext(ctx context.Context, repository models.Path, liveOutput func(string)) error {
 	if repository.Empty() {
 		return errors.New("empty repository value")
 	}
@@ -123,43 +189,50 @@ func GitPull(repository models.Path, liveOutput func(string)) error {
 		}
 	}
 
-	err := RunCommandLiveOutputCombinedOutput(liveOutputFunc, repository, "git", "pull", "--progress")
-	output := outputBuilder.String()
-
-	if strings.Contains(output, "fatal: not a git repository") {
-		return fmt.Errorf("%s is not a git repository", repository)
-	}
-
-	if strings.Contains(output, "There is no tracking information for the current branch.") {
-		return fmt.Errorf("repository has no configured remote")
-	}
-
-	if strings.Contains(output, "error: The following untracked working tree files would be overwritten by merge:") {
-		return fmt.Errorf("repository contains untracked contents")
-	}
+	pullArgs := []string{"pull", "--progress"}
+	output, attempts, err := retryGitOperation(ctx, "git pull", nil, func(ctx context.Context) (string, error) {
+		ctx, cancel := contextWithTimeout(ctx, gitTimeouts.Fetch)
+		defer cancel()
 
-	if strings.Contains(output, "fatal: Need to specify how to reconcile divergent branches.") {
-		return fmt.Errorf("repository does not know how to merge")
-	}
-
-	if strings.Contains(output, "fatal: Not possible to fast-forward, aborting") {
-		return fmt.Errorf("unable to fast-forward")
-	}
+		outputBuilder.Reset()
+		err := RunCommandLiveOutputCombinedOutputContext(ctx, liveOutputFunc, repository, "git", gitRemoteCommandArgs(pullArgs...)...)
+		if ctxErr := commandContextError(ctx, "git pull", gitTimeouts.Fetch); err != nil && ctxErr != nil {
+			err = ctxErr
+		}
 
-	if strings.Contains(output, "Merge conflict") {
+		return urls.RedactCredentials(outputBuilder.String()), err
+	})
+
+	if err == nil {
+		return nil
+	}
+
+	var message string
+	kind := ClassifyGitOutput(output)
+	switch {
+	case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+		kind, message = nil, err.Error()
+	case kind == ErrNotARepository:
+		message = fmt.Sprintf("%s is not a git repository", repository)
+	case kind == ErrNoUpstream:
+		message = "repository has no configured remote"
+	case kind == ErrLocalChanges:
+		message = "repository contains untracked contents"
+	case kind == ErrDivergedBranches && strings.Contains(output, "Need to specify how to reconcile divergent branches"):
+		message = "repository does not know how to merge"
+	case kind == ErrDivergedBranches:
+		message = "unable to fast-forward"
+	case kind == ErrMergeConflict:
+		message = "pull would cause merge conflict"
 		abortOutput, abortErr := RunCommandCombinedOutput(repository, "git", "merge", "--abort")
 		if abortErr != nil {
-			return fmt.Errorf("error aborting merge: %w; output: %s", abortErr, abortOutput)
+			message = fmt.Sprintf("%s; error aborting merge: %s; output: %s", message, abortErr, abortOutput)
 		}
-
-		return fmt.Errorf("pull would cause merge conflict")
+	case kind == ErrAuthRequired:
+		message = gitAuthenticationMessage(repositoryRemote(repository))
 	}
 
-	if err != nil {
-		return fmt.Errorf("error running git checkout: %w; output: %s", err, output)
-	}
-
-	return nil
+	return withAttempts(newGitError(kind, message, repository, pullArgs, output, err), attempts)
 }
 
 /*
@@ -170,33 +243,39 @@ func GetGitRootDirectory(d models.Path) (string, error) {
 		return "", errors.New("path to repository may not be empty")
 	}
 
-	path, err := RunCommandCombinedOutput(d, "git", "rev-parse", "--show-toplevel")
+	args := []string{"rev-parse", "--show-toplevel"}
+	path, err := RunCommandCombinedOutput(d, "git", args...)
 	if err != nil {
-		return "", fmt.Errorf("error running git rev-parse: %w; output: %s", err, path)
-	}
-
-	if strings.HasPrefix(path, "fatal:") {
-		return "", errors.New("git root not found")
+		return "", gitCommandError(d, args, path, err)
 	}
 
 	return path, nil
 }
 
 /*
-GetGitRemoteUrl retrieves the remote url from a git directory tree.
+GetGitRemoteUrl retrieves the url of the 'origin' remote from a git directory tree.
 */
 func GetGitRemoteUrl(d models.Path) (string, error) {
+	return GetGitRemoteUrlByName(d, "origin")
+}
+
+/*
+GetGitRemoteUrlByName retrieves the url of a named remote from a git directory tree.
+*/
+func GetGitRemoteUrlByName(d models.Path, remote string) (string, error) {
 	if d.Empty() {
 		return "", errors.New("path to repository may not be empty")
 	}
 
-	remoteUrl, err := RunCommandCombinedOutput(d, "git", "config", "--get", "remote.origin.url")
-	if err != nil {
-		return "", fmt.Errorf("error running git config: %w; output: %s", err, remoteUrl)
+	remote = strings.TrimSpace(remote)
+	if remote == "" {
+		return "", errors.New("remote name cannot be blank")
 	}
 
-	if strings.HasPrefix(remoteUrl, "fatal:") {
-		return "", errors.New("git root not found")
+	args := []string{"config", "--get", "remote." + remote + ".url"}
+	remoteUrl, err := RunCommandCombinedOutput(d, "git", args...)
+	if err != nil {
+		return "", gitCommandError(d, args, remoteUrl, err)
 	}
 
 	return remoteUrl, nil
@@ -211,18 +290,16 @@ func GetGitFetchHead(d models.Path) (string, string, error) {
 		return "", "", errors.New("path to repository may not be empty")
 	}
 
-	longHead, err := RunCommandCombinedOutput(d, "git", "rev-parse", "--verify", "HEAD")
+	args := []string{"rev-parse", "--verify", "HEAD"}
+	longHead, err := RunCommandCombinedOutput(d, "git", args...)
 	if err != nil {
-		return "", "", fmt.Errorf("error running git rev-parse: %w; output: %s", err, longHead)
-	}
-
-	if strings.HasPrefix(longHead, "fatal: not a git repository") {
-		return "", "", errors.New("no git repository")
+		return "", "", gitCommandError(d, args, longHead, err)
 	}
 
-	abbrevHead, err := RunCommandCombinedOutput(d, "git", "rev-parse", "--abbrev-ref", "HEAD")
+	args = []string{"rev-parse", "--abbrev-ref", "HEAD"}
+	abbrevHead, err := RunCommandCombinedOutput(d, "git", args...)
 	if err != nil {
-		return "", "", fmt.Errorf("error running git rev-parse: %w; output: %s", err, abbrevHead)
+		return "", "", gitCommandError(d, args, abbrevHead, err)
 	}
 
 	if abbrevHead != "HEAD" {
@@ -253,19 +330,10 @@ func GetGitHasUntrackedChanges(d models.Path) (bool, error) {
 		return true, errors.New("path to repository may not be empty")
 	}
 
-	out, err := RunCommandCombinedOutput(d, "git", "status", "--porcelain=v1")
+	args := []string{"status", "--porcelain=v1"}
+	out, err := RunCommandCombinedOutput(d, "git", args...)
 	if err != nil {
-		return true, err
-	}
-
-	// if not a repository, then return false
-	if strings.HasPrefix(out, "fatal: not a git repository") {
-		return false, nil
-	}
-
-	// in case another error occurs, return it
-	if strings.HasPrefix(out, "fatal:") {
-		return true, fmt.Errorf("git error: %s", out)
+		return true, gitCommandError(d, args, out, err)
 	}
 
 	return strings.TrimSpace(out) != "", nil
@@ -280,20 +348,610 @@ func GetGitHasUnpublishedChanges(d models.Path) (bool, error) {
 		return true, errors.New("path to repository may not be empty")
 	}
 
-	out, err := RunCommandCombinedOutput(d, "git", "status")
+	args := []string{"status"}
+	out, err := RunCommandCombinedOutput(d, "git", args...)
 	if err != nil {
-		return true, err
+		return true, gitCommandError(d, args, out, err)
 	}
 
-	// if not a repository, then return false
-	if strings.HasPrefix(out, "fatal: not a git repository") {
-		return false, nil
+	return strings.Contains(out, "(use \"git push\" to publish your local commits)"), nil
+}
+
+/*
+GitRemoteRefs contains the references a remote repository advertises.
+*/
+type GitRemoteRefs struct {
+	/*
+		Head contains the branch the remote's HEAD points to. Empty if the remote has a detached HEAD.
+	*/
+	Head string
+
+	/*
+		Branches maps branch names to their commit hashes.
+	*/
+	Branches map[string]string
+
+	/*
+		Tags maps tag names to the commit hashes they point to. Annotated tags are peeled.
+	*/
+	Tags map[string]string
+
+	/*
+		TagOrder contains all tag names in ascending version order.
+	*/
+	TagOrder []string
+}
+
+/*
+GetGitRemoteRefs queries branches and tags of a remote repository using git ls-remote without fetching any objects.
+*/
+func GetGitRemoteRefs(url string) (GitRemoteRefs, error) {
+	return GetGitRemoteRefsContext(commandContext, url)
+}
+
+/*
+GetGitRemoteRefsContext is like GetGitRemoteRefs, but aborts the query if ctx is done or the ls-remote timeout is exceeded.
+*/
+func GetGitRemoteRefsContext(ctx context.Context, url string) (GitRemoteRefs, error) {
+	refs := GitRemoteRefs{
+		Branches: make(map[string]string),
+		Tags:     make(map[string]string),
 	}
 
-	// in case another error occurs, return it
-	if strings.HasPrefix(out, "fatal:") {
-		return true, fmt.Errorf("git error: %s", out)
+	url = strings.TrimSpace(url)
+	if url == "" {
+		return refs, errors.New("git repository url is empty")
 	}
 
-	return strings.Contains(out, "(use \"git push\" to publish your local commits)"), nil
+	ctx, cancel := contextWithTimeout(ctx, gitTimeouts.LsRemote)
+	defer cancel()
+
+	args := []string{"ls-remote", "--symref", "--sort=v:refname", url}
+	out, errOut, err := runGitRemoteCommand(ctx, "", args...)
+	if err != nil {
+		if ctxErr := commandContextError(ctx, "git ls-remote", gitTimeouts.LsRemote); ctxErr != nil {
+			return refs, newGitError(nil, ctxErr.Error(), "", args, errOut, ctxErr)
+		}
+
+		var message string
+		kind := ClassifyGitOutput(errOut)
+		if kind == ErrAuthRequired {
+			message = gitAuthenticationMessage(url)
+		}
+		return refs, newGitError(kind, message, "", args, errOut, err)
+	}
+
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		// symbolic reference, e.g. 'ref: refs/heads/main HEAD'
+		if fields[0] == "ref:" {
+			if len(fields) == 3 && fields[2] == "HEAD" {
+				refs.Head = strings.TrimPrefix(fields[1], "refs/heads/")
+			}
+			continue
+		}
+
+		sha, name := fields[0], fields[1]
+		switch {
+		case strings.HasPrefix(name, "refs/heads/"):
+			refs.Branches[strings.TrimPrefix(name, "refs/heads/")] = sha
+		case strings.HasPrefix(name, "refs/tags/") && strings.HasSuffix(name, "^{}"):
+			// peeled annotated tag overrides the tag object's hash
+			refs.Tags[strings.TrimSuffix(strings.TrimPrefix(name, "refs/tags/"), "^{}")] = sha
+		case strings.HasPrefix(name, "refs/tags/"):
+			tag := strings.TrimPrefix(name, "refs/tags/")
+			if _, ok := refs.Tags[tag]; !ok {
+				refs.TagOrder = append(refs.TagOrder, tag)
+				refs.Tags[tag] = sha
+			}
+		}
+	}
+
+	return refs, nil
+}
+
+/*
+GetGitExactTag returns the tag that points exactly at a local repository's HEAD.
+Returns an empty string if no such tag exists.
+*/
+func GetGitExactTag(d models.Path) (string, error) {
+	if d.Empty() {
+		return "", errors.New("path to repository may not be empty")
+	}
+
+	tag, err := RunCommandCombinedOutput(d, "git", "describe", "--tags", "--exact-match", "HEAD")
+	if err != nil {
+		// git describe exits with an error if no tag matches
+		return "", nil
+	}
+
+	return tag, nil
+}
+
+/*
+GetGitCommitsBehind returns how many commits a local repository's HEAD is behind another commit.
+Only objects that already exist locally are considered, so no network access is required.
+Returns -1 if the commit is not known to the local repository.
+*/
+func GetGitCommitsBehind(d models.Path, commit string) (int, error) {
+	if d.Empty() {
+		return -1, errors.New("path to repository may not be empty")
+	}
+
+	commit = strings.TrimSpace(commit)
+	if commit == "" {
+		return -1, errors.New("commit may not be empty")
+	}
+
+	_, err := RunCommandCombinedOutput(d, "git", "cat-file", "-e", commit+"^{commit}")
+	if err != nil {
+		return -1, nil
+	}
+
+	args := []string{"rev-list", "--count", "HEAD.." + commit}
+	out, err := RunCommandCombinedOutput(d, "git", args...)
+	if err != nil {
+		return -1, gitCommandError(d, args, out, err)
+	}
+
+	behind, err := strconv.Atoi(out)
+	if err != nil {
+		return -1, fmt.Errorf("unable to parse commit count %s: %w", out, err)
+	}
+
+	return behind, nil
+}
+
+/*
+GetGitPath resolves a path within a repository's git directory using git rev-parse --git-path.
+This respects configuration that relocates parts of the git directory, e.g. core.hooksPath.
+*/
+func GetGitPath(d models.Path, p string) (models.Path, error) {
+	if d.Empty() {
+		return "", errors.New("path to repository may not be empty")
+	}
+
+	args := []string{"rev-parse", "--git-path", p}
+	out, err := RunCommandCombinedOutput(d, "git", args...)
+	if err != nil {
+		return "", gitCommandError(d, args, out, err)
+	}
+
+	gitPath := models.Path(out)
+	if !filepath.IsAbs(out) {
+		gitPath = d.Join(gitPath)
+	}
+
+	return gitPath.Clean(), nil
+}
+
+/*
+GetGitRefCommit resolves a reference to its commit hash in a local repository.
+Returns an error if the reference does not exist locally.
+*/
+func GetGitRefCommit(d models.Path, ref string) (string, error) {
+	if d.Empty() {
+		return "", errors.New("path to repository may not be empty")
+	}
+
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return "", errors.New("ref cannot be blank")
+	}
+
+	args := []string{"rev-parse", "--verify", "--quiet", ref + "^{commit}"}
+	commit, err := RunCommandCombinedOutput(d, "git", args...)
+	if err != nil {
+		return "", gitRefNotFoundError(d, args, commit, err, fmt.Sprintf("ref '%s' does not exist", ref))
+	}
+
+	return commit, nil
+}
+
+/*
+GetGitTreeHash returns the hash of the tree a revision in a local repository points to, e.g. of HEAD.
+*/
+func GetGitTreeHash(d models.Path, rev string) (string, error) {
+	if d.Empty() {
+		return "", errors.New("path to repository may not be empty")
+	}
+
+	rev = strings.TrimSpace(rev)
+	if rev == "" {
+		return "", errors.New("revision cannot be blank")
+	}
+
+	args := []string{"rev-parse", "--verify", "--quiet", rev + "^{tree}"}
+	tree, err := RunCommandCombinedOutput(d, "git", args...)
+	if err != nil {
+		return "", gitRefNotFoundError(d, args, tree, err, fmt.Sprintf("revision '%s' does not exist", rev))
+	}
+
+	return tree, nil
+}
+
+/*
+GitArchive runs git archive in tar format for a revision of a local repository and passes the archive stream to handle.
+If run in a subdirectory of a repository, only that subdirectory is archived. If paths are passed, only files
+matching them are archived.
+*/
+func GitArchive(d models.Path, rev string, handle func(io.Reader) error, paths ...string) error {
+	if d.Empty() {
+		return errors.New("path to repository may not be empty")
+	}
+
+	rev = strings.TrimSpace(rev)
+	if rev == "" {
+		return errors.New("revision cannot be blank")
+	}
+
+	args := []string{"archive", "--format=tar", rev}
+	if len(paths) != 0 {
+		args = append(append(args, "--"), paths...)
+	}
+	cmd := constructCommand(d, "git", args...)
+
+	var errBuf bytes.Buffer
+	cmd.Stderr = &errBuf
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("could not obtain stdout pipe: %w", err)
+	}
+
+	err = cmd.Start()
+	if err != nil {
+		return fmt.Errorf("error starting git archive: %w", err)
+	}
+
+	handleErr := handle(stdout)
+
+	// drain the stream, so that git does not block on a full pipe
+	_, _ = io.Copy(io.Discard, stdout)
+
+	err = cmd.Wait()
+	if err != nil {
+		return gitCommandError(d, args, errBuf.String(), err)
+	}
+
+	return handleErr
+}
+
+/*
+GitBundleCreate writes all refs and the HEAD of a local repository into a git bundle file.
+*/
+func GitBundleCreate(d models.Path, bundle models.Path) error {
+	if d.Empty() {
+		return errors.New("path to repository may not be empty")
+	}
+
+	if bundle.Empty() {
+		return errors.New("path to bundle may not be empty")
+	}
+
+	args := []string{"bundle", "create", "--quiet", bundle.String(), "--all"}
+	out, err := RunCommandCombinedOutput(d, "git", args...)
+	if err != nil {
+		return gitCommandError(d, args, out, err)
+	}
+
+	return nil
+}
+
+/*
+GetGitRefKind detects whether a reference is a local or remote-tracking branch of the passed remote, a tag or a commit
+in a local repository. Branches take precedence over tags, and tags over commit hashes.
+Returns an error if the reference does not exist locally.
+*/
+func GetGitRefKind(d models.Path, ref string, remote string) (models.RefKind, error) {
+	if d.Empty() {
+		return models.RefKindAuto, errors.New("path to repository may not be empty")
+	}
+
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return models.RefKindAuto, errors.New("ref cannot be blank")
+	}
+
+	refExists := func(fullRef string) bool {
+		_, err := RunCommandCombinedOutput(d, "git", "show-ref", "--verify", "--quiet", fullRef)
+		return err == nil
+	}
+
+	if refExists("refs/heads/" + ref) {
+		return models.RefKindBranch, nil
+	}
+
+	if remote = strings.TrimSpace(remote); remote != "" && refExists("refs/remotes/"+remote+"/"+ref) {
+		return models.RefKindBranch, nil
+	}
+
+	if refExists("refs/tags/" + ref) {
+		return models.RefKindTag, nil
+	}
+
+	if models.IsCommitHash(ref) {
+		if _, err := GetGitRefCommit(d, ref); err == nil {
+			return models.RefKindCommit, nil
+		}
+	}
+
+	return models.RefKindAuto, newGitError(ErrRefNotFound, fmt.Sprintf("ref '%s' does not exist", ref), d, []string{"show-ref", ref}, "", nil)
+}
+
+/*
+GitFetch fetches updates from a local repository's remote without changing its HEAD.
+If remote is empty, git's default remote is used.
+*/
+func GitFetch(repository models.Path, remote string, liveOutput func(string)) error {
+	return GitFetchContext(commandContext, repository, remote, liveOutput)
+}
+
+/*
+GitFetchContext is like GitFetch, but aborts the fetch if ctx is done or the fetch timeout is exceeded.
+*/
+func GitFetchContext(ctx context.Context, repository models.Path, remote string, liveOutput func(string)) error {
+	if repository.Empty() {
+		return errors.New("empty repository value")
+	}
+
+	if !repository.IsDir() {
+		return fmt.Errorf("%s is not a directory", repository)
+	}
+
+	outputBuilder := strings.Builder{}
+	liveOutputFunc := func(line string) {
+		outputBuilder.WriteString(line + "\n")
+
+		if liveOutput != nil {
+			liveOutput(line)
+		}
+	}
+
+	fetchArgs := []string{"fetch", "--tags", "--progress"}
+	if remote = strings.TrimSpace(remote); remote != "" {
+		fetchArgs = append(fetchArgs, remote)
+	}
+
+	ctx, cancel := contextWithTimeout(ctx, gitTimeouts.Fetch)
+	defer cancel()
+
+	err := RunCommandLiveOutputCombinedOutputContext(ctx, liveOutputFunc, repository, "git", gitRemoteCommandArgs(fetchArgs...)...)
+	if err == nil {
+		return nil
+	}
+
+	output := urls.RedactCredentials(outputBuilder.String())
+	if ctxErr := commandContextError(ctx, "git fetch", gitTimeouts.Fetch); ctxErr != nil {
+		return newGitError(nil, ctxErr.Error(), repository, fetchArgs, output, ctxErr)
+	}
+
+	var message string
+	kind := ClassifyGitOutput(output)
+	switch kind {
+	case ErrNotARepository:
+		message = fmt.Sprintf("%s is not a git repository", repository)
+	case ErrAuthRequired:
+		message = gitAuthenticationMessage(repositoryRemote(repository))
+	}
+
+	return newGitError(kind, message, repository, fetchArgs, output, err)
+}
+
+/*
+GetGitRemotes returns the names of all remotes of a local repository.
+*/
+func GetGitRemotes(repository models.Path) ([]string, error) {
+	if repository.Empty() {
+		return nil, errors.New("empty repository value")
+	}
+
+	args := []string{"remote"}
+	out, err := RunCommandCombinedOutput(repository, "git", args...)
+	if err != nil {
+		return nil, gitCommandError(repository, args, out, err)
+	}
+
+	return strings.Fields(out), nil
+}
+
+/*
+GitRenameRemote renames a remote of a local repository.
+*/
+func GitRenameRemote(repository models.Path, remote string, newRemote string) error {
+	if repository.Empty() {
+		return errors.New("empty repository value")
+	}
+
+	remote, newRemote = strings.TrimSpace(remote), strings.TrimSpace(newRemote)
+	if remote == "" || newRemote == "" {
+		return errors.New("remote name cannot be blank")
+	}
+
+	args := []string{"remote", "rename", remote, newRemote}
+	out, err := RunCommandCombinedOutput(repository, "git", args...)
+	if err != nil {
+		return gitCommandError(repository, args, out, err)
+	}
+
+	return nil
+}
+
+/*
+GitSetRemoteUrl sets the url of a local repository's remote. The remote is created if it does not exist.
+*/
+func GitSetRemoteUrl(repository models.Path, remote string, url string) error {
+	if repository.Empty() {
+		return errors.New("empty repository value")
+	}
+
+	remote = strings.TrimSpace(remote)
+	if remote == "" {
+		return errors.New("remote name cannot be blank")
+	}
+
+	url = strings.TrimSpace(url)
+	if url == "" {
+		return errors.New("remote url cannot be blank")
+	}
+
+	_, err := RunCommandCombinedOutput(repository, "git", "config", "--get", "remote."+remote+".url")
+	subcommand := "set-url"
+	if err != nil {
+		subcommand = "add"
+	}
+
+	args := []string{"remote", subcommand, remote, url}
+	out, err := RunCommandCombinedOutput(repository, "git", args...)
+	if err != nil {
+		return gitCommandError(repository, args, out, err)
+	}
+
+	return nil
+}
+
+/*
+GetGitStagedPaths returns the paths that are staged for the next commit, relative to the repository root.
+Staged deletions are not included. If indexFile is not empty, it is used instead of the repository's
+default index, which is required when called from hooks of commands like 'git commit -a'.
+GIT_DIR and GIT_WORK_TREE of the current environment are respected, see GetGitRepositoryDirectories.
+*/
+func GetGitStagedPaths(d models.Path, indexFile models.Path) ([]string, error) {
+	if d.Empty() {
+		return nil, errors.New("path to repository may not be empty")
+	}
+
+	args := []string{"diff", "--cached", "--name-only", "--no-renames", "--diff-filter=d", "-z"}
+	cmd := constructCommand(d, "git", args...)
+	cmd.Env = append(cmd.Env, repositoryEnvironment()...)
+	if !indexFile.Empty() {
+		cmd.Env = append(cmd.Env, "GIT_INDEX_FILE="+indexFile.String())
+	}
+
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, gitCommandError(d, args, string(out), err)
+	}
+
+	var paths []string
+	for _, path := range strings.Split(string(out), "\x00") {
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+
+	return paths, nil
+}
+
+/*
+GetGitListFiles returns the tracked and untracked, but not ignored, files in a local repository that match the passed
+pathspecs. Paths are relative to the passed directory. GIT_DIR and GIT_WORK_TREE of the current environment
+are respected, see GetGitRepositoryDirectories.
+*/
+func GetGitListFiles(d models.Path, pathspecs ...string) ([]string, error) {
+	return gitListFiles(d, repositoryEnvironment(), pathspecs...)
+}
+
+/*
+GetGitWorktreeFiles lists all tracked and all untracked, non-ignored files of a nested module's repository.
+In contrast to GetGitListFiles, a parent repository's GIT_DIR and GIT_WORK_TREE are never used.
+*/
+func GetGitWorktreeFiles(d models.Path) ([]string, error) {
+	return gitListFiles(d, nil)
+}
+
+/*
+gitListFiles runs git ls-files for tracked and untracked, non-ignored files with additional environment variables.
+*/
+func gitListFiles(d models.Path, env []string, pathspecs ...string) ([]string, error) {
+	if d.Empty() {
+		return nil, errors.New("path to repository may not be empty")
+	}
+
+	args := append([]string{"ls-files", "-z", "--cached", "--others", "--exclude-standard", "--"}, pathspecs...)
+	cmd := constructCommand(d, "git", args...)
+	cmd.Env = append(cmd.Env, env...)
+
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, gitCommandError(d, args, string(out), err)
+	}
+
+	var files []string
+	for _, file := range strings.Split(string(out), "\x00") {
+		if file != "" && !slices.Contains(files, file) {
+			files = append(files, file)
+		}
+	}
+
+	return files, nil
+}
+
+/*
+GetGitRepositoryDirectories returns the working tree root and the common git directory of the repository a directory
+belongs to. The common git directory is shared between all worktrees of a repository and contains e.g. the exclude
+file. In contrast to other functions, GIT_DIR and GIT_WORK_TREE of the current environment are respected, as they
+describe the repository git-nest is run in.
+*/
+func GetGitRepositoryDirectories(d models.Path) (models.Path, models.Path, error) {
+	if d.Empty() {
+		return "", "", errors.New("path to repository may not be empty")
+	}
+
+	args := []string{"rev-parse", "--show-toplevel", "--git-common-dir"}
+	cmd := constructCommand(d, "git", args...)
+	cmd.Env = append(cmd.Env, repositoryEnvironment()...)
+
+	out, err := cmd.CombinedOutput()
+	output := strings.TrimSpace(string(out))
+	if err != nil {
+		return "", "", gitCommandError(d, args, output, err)
+	}
+
+	lines := strings.Split(output, "\n")
+	if len(lines) != 2 {
+		return "", "", fmt.Errorf("unexpected git rev-parse output: %s", output)
+	}
+
+	root := models.Path(strings.TrimSpace(lines[0]))
+	commonDir := models.Path(strings.TrimSpace(lines[1]))
+
+	// the common git directory is printed relative to the working directory
+	if !filepath.IsAbs(commonDir.String()) {
+		commonDir = d.Join(commonDir)
+	}
+
+	return root.Clean(), commonDir.Clean(), nil
+}
+
+/*
+repositoryEnvironment returns GIT_DIR and GIT_WORK_TREE of the current environment as absolute paths,
+so that they can be passed to commands that run in another working directory.
+*/
+func repositoryEnvironment() []string {
+	var env []string
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return env
+	}
+
+	for _, name := range []string{"GIT_DIR", "GIT_WORK_TREE"} {
+		value, ok := os.LookupEnv(name)
+		if !ok || value == "" {
+			continue
+		}
+
+		if !filepath.IsAbs(value) {
+			value = filepath.Join(cwd, value)
+		}
+
+		env = append(env, name+"="+value)
+	}
+
+	return env
 }
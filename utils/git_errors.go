@@ -0,0 +1,208 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/models/urls"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+var (
+	/*
+		ErrNotARepository is returned if a git command runs in a directory that is not within a git repository.
+	*/
+	ErrNotARepository = errors.New("not a git repository")
+
+	/*
+		ErrRefNotFound is returned if a branch, tag, commit or revision does not exist.
+	*/
+	ErrRefNotFound = errors.New("ref does not exist")
+
+	/*
+		ErrRepositoryNotFound is returned if a remote repository does not exist or cannot be found.
+	*/
+	ErrRepositoryNotFound = errors.New("remote repository does not exist")
+
+	/*
+		ErrAuthRequired is returned by remote git operations that failed because the remote requires
+		credentials that could not be obtained without prompting.
+	*/
+	ErrAuthRequired = errors.New("authentication required")
+
+	/*
+		ErrMergeConflict is returned if merging changes results in conflicts.
+	*/
+	ErrMergeConflict = errors.New("merge conflict")
+
+	/*
+		ErrLocalChanges is returned if local changes or untracked files would be overwritten.
+	*/
+	ErrLocalChanges = errors.New("local changes would be overwritten")
+
+	/*
+		ErrDivergedBranches is returned if a branch cannot be fast-forwarded to its upstream.
+	*/
+	ErrDivergedBranches = errors.New("branches have diverged")
+
+	/*
+		ErrNoUpstream is returned if the current branch of a repository does not track a remote branch.
+	*/
+	ErrNoUpstream = errors.New("no upstream branch")
+
+	/*
+		ErrDestinationExists is returned if a repository is cloned into an existing, non-empty directory.
+	*/
+	ErrDestinationExists = errors.New("destination path already exists")
+)
+
+/*
+gitErrorPatterns maps git output fragments to the kind of error they indicate. The first matching pattern wins.
+*/
+var gitErrorPatterns = []struct {
+	pattern *regexp.Regexp
+	kind    error
+}{
+	{regexp.MustCompile(`not a git repository`), ErrNotARepository},
+	{regexp.MustCompile(`repository '.*' (not found|does not exist)|repository not found|does not appear to be a git repository`), ErrRepositoryNotFound},
+	{regexp.MustCompile(`destination path '.*' already exists`), ErrDestinationExists},
+	{regexp.MustCompile(`pathspec '.*' did not match|unknown revision|invalid reference|couldn't find remote ref|not a valid object name|needed a single revision|not found in upstream`), ErrRefNotFound},
+	{regexp.MustCompile(`merge conflict|conflict \(|automatic merge failed`), ErrMergeConflict},
+	{regexp.MustCompile(`would be overwritten by`), ErrLocalChanges},
+	{regexp.MustCompile(`need to specify how to reconcile divergent branches|not possible to fast-forward`), ErrDivergedBranches},
+	{regexp.MustCompile(`there is no tracking information`), ErrNoUpstream},
+}
+
+/*
+GitError describes a failed git command. Kind contains one of the sentinel errors of this package, e.g. ErrRefNotFound,
+or nil if the cause is unknown. Use errors.Is to test for a kind and errors.As to access the command's details.
+*/
+type GitError struct {
+	/*
+		Kind contains the sentinel error that describes the cause, or nil if the cause is unknown.
+	*/
+	Kind error
+
+	/*
+		Message contains a description of the failure. If empty, the command, error and output are described.
+	*/
+	Message string
+
+	/*
+		Command contains the git command line without configuration parameters, with redacted credentials.
+	*/
+	Command string
+
+	/*
+		Dir contains the directory the command ran in.
+	*/
+	Dir string
+
+	/*
+		ExitCode contains the command's exit code, or -1 if it did not exit by itself.
+	*/
+	ExitCode int
+
+	/*
+		Stderr contains the command's output, with redacted credentials.
+	*/
+	Stderr string
+
+	/*
+		Err contains the underlying error.
+	*/
+	Err error
+}
+
+/*
+Error returns a string representation of this GitError.
+*/
+func (e *GitError) Error() string {
+	if e.Message != "" {
+		return e.Message
+	}
+
+	// git and its subcommand, e.g. 'git rev-parse'
+	name := strings.Fields(e.Command)
+	if len(name) > 2 {
+		name = name[:2]
+	}
+
+	return fmt.Sprintf("error running %s: %s; output: %s", strings.Join(name, " "), e.Err, e.Stderr)
+}
+
+/*
+Unwrap returns the kind and the underlying error of this GitError.
+*/
+func (e *GitError) Unwrap() []error {
+	var errs []error
+	if e.Kind != nil {
+		errs = append(errs, e.Kind)
+	}
+	if e.Err != nil {
+		errs = append(errs, e.Err)
+	}
+
+	return errs
+}
+
+/*
+ClassifyGitOutput returns the sentinel error that the output of a failed git command indicates, or nil if it is unknown.
+*/
+func ClassifyGitOutput(output string) error {
+	if IsGitAuthenticationError(output) {
+		return ErrAuthRequired
+	}
+
+	output = strings.ToLower(output)
+	for _, entry := range gitErrorPatterns {
+		if entry.pattern.MatchString(output) {
+			return entry.kind
+		}
+	}
+
+	return nil
+}
+
+/*
+newGitError returns a GitError for a git command that ran in dir with the passed arguments.
+*/
+func newGitError(kind error, message string, dir models.Path, args []string, output string, err error) *GitError {
+	exitCode := -1
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		exitCode = exitErr.ExitCode()
+	}
+
+	return &GitError{
+		Kind:     kind,
+		Message:  message,
+		Command:  urls.RedactCredentials("git " + strings.Join(args, " ")),
+		Dir:      dir.String(),
+		ExitCode: exitCode,
+		Stderr:   urls.RedactCredentials(strings.TrimSpace(output)),
+		Err:      err,
+	}
+}
+
+/*
+gitCommandError returns a GitError for a failed git command, whose kind is derived from its output.
+*/
+func gitCommandError(dir models.Path, args []string, output string, err error) error {
+	return newGitError(ClassifyGitOutput(output), "", dir, args, output, err)
+}
+
+/*
+gitRefNotFoundError returns a GitError for a git command that failed to resolve a ref. Unless the output indicates
+another cause, e.g. a missing repository, the error is of kind ErrRefNotFound and described by message.
+*/
+func gitRefNotFoundError(dir models.Path, args []string, output string, err error, message string) error {
+	kind := ClassifyGitOutput(output)
+	if kind == nil || kind == ErrRefNotFound {
+		return newGitError(ErrRefNotFound, message, dir, args, output, err)
+	}
+
+	return newGitError(kind, "", dir, args, output, err)
+}
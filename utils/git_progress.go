@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"github.com/jeftadlvw/git-nest/models"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+/*
+gitProgressPattern matches progress lines that git prints with --progress, e.g.
+'Receiving objects:  45% (450/1000), 1.20 MiB | 2.00 MiB/s' or 'remote: Counting objects: 100% (10/10), done.'.
+*/
+var gitProgressPattern = regexp.MustCompile(`^(?:remote:\s*)?([A-Za-z][A-Za-z ]*?):\s+(\d{1,3})% \((\d+)/(\d+)\)`)
+
+/*
+ParseGitProgress parses a progress line printed by git. The second return value reports whether the line
+contains progress.
+*/
+func ParseGitProgress(line string) (models.Progress, bool) {
+	matches := gitProgressPattern.FindStringSubmatch(strings.TrimSpace(line))
+	if matches == nil {
+		return models.Progress{}, false
+	}
+
+	percent, _ := strconv.Atoi(matches[2])
+	current, _ := strconv.Atoi(matches[3])
+	total, _ := strconv.Atoi(matches[4])
+
+	return models.Progress{
+		Phase:   matches[1],
+		Percent: MinInt(percent, 100),
+		Current: current,
+		Total:   total,
+	}, true
+}
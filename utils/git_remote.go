@@ -0,0 +1,140 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/models/urls"
+	"strings"
+)
+
+/*
+gitAuthenticationErrorMessages contains git and ssh output fragments that indicate missing or rejected credentials.
+*/
+var gitAuthenticationErrorMessages = []string{
+	"terminal prompts disabled",
+	"could not read username",
+	"could not read password",
+	"authentication failed",
+	"permission denied (publickey",
+	"host key verification failed",
+	"the requested url returned error: 401",
+	"the requested url returned error: 403",
+	"access denied",
+	"invalid username or password",
+}
+
+/*
+gitConfigParameters contains configuration values that are passed to every git command that contacts a remote.
+*/
+var gitConfigParameters []string
+
+/*
+gitUrlRewrites contains url rewrites that are passed to every git command that contacts a remote.
+*/
+var gitUrlRewrites []models.UrlRewrite
+
+/*
+gitTimeouts contains the timeouts of git commands, see SetGitTimeouts.
+*/
+var gitTimeouts = models.Timeouts{}.GitTimeouts()
+
+/*
+SetGitConfigParameters sets configuration values in 'key=value' format that are passed to git using '-c'
+whenever git contacts a remote, e.g. when cloning, pulling, fetching or listing remote references.
+The values are not persisted in any repository configuration.
+*/
+func SetGitConfigParameters(parameters ...string) {
+	gitConfigParameters = parameters
+}
+
+/*
+SetGitUrlRewrites sets url rewrites that are passed to git as 'url.<replacement>.insteadOf=<prefix>' whenever git
+contacts a remote. As git does not store rewritten urls, remote urls of cloned repositories remain unchanged.
+*/
+func SetGitUrlRewrites(rewrites ...models.UrlRewrite) {
+	gitUrlRewrites = rewrites
+}
+
+/*
+SetGitTimeouts sets how long git commands may run before they are terminated. Remote operations use the timeout
+of their kind, all other commands use the local timeout. A timeout of zero disables it.
+*/
+func SetGitTimeouts(timeouts models.GitTimeouts) {
+	gitTimeouts = timeouts
+}
+
+/*
+RewriteGitUrl returns the url git contacts for the passed url, using the url rewrites set by SetGitUrlRewrites.
+The second return value reports whether the url was rewritten.
+*/
+func RewriteGitUrl(url string) (string, bool) {
+	return models.RewriteUrl(gitUrlRewrites, url)
+}
+
+/*
+gitRemoteCommandArgs prefixes the passed git arguments with the configured git configuration parameters and url rewrites.
+*/
+func gitRemoteCommandArgs(args ...string) []string {
+	commandArgs := make([]string, 0, (len(gitConfigParameters)+len(gitUrlRewrites))*2+len(args))
+	for _, parameter := range gitConfigParameters {
+		commandArgs = append(commandArgs, "-c", parameter)
+	}
+
+	for _, rewrite := range gitUrlRewrites {
+		commandArgs = append(commandArgs, "-c", fmt.Sprintf("url.%s.insteadOf=%s", rewrite.Replacement, rewrite.Prefix))
+	}
+
+	return append(commandArgs, args...)
+}
+
+/*
+runGitRemoteCommand runs a git command that contacts a remote and returns its stdout and its redacted stderr,
+which in contrast to RunCommand is also returned if the command fails.
+*/
+func runGitRemoteCommand(ctx context.Context, d models.Path, args ...string) (string, string, error) {
+	cmd := constructCommandContext(ctx, d, "git", gitRemoteCommandArgs(args...)...)
+
+	var errBuf bytes.Buffer
+	cmd.Stderr = &errBuf
+
+	stdout, err := cmd.Output()
+	if err != nil {
+		err = withContextError(ctx, err)
+	}
+	return strings.TrimSpace(string(stdout)), urls.RedactCredentials(strings.TrimSpace(errBuf.String())), err
+}
+
+/*
+IsGitAuthenticationError returns whether the output of a failed git command indicates missing or rejected credentials.
+*/
+func IsGitAuthenticationError(output string) bool {
+	output = strings.ToLower(output)
+	for _, message := range gitAuthenticationErrorMessages {
+		if strings.Contains(output, message) {
+			return true
+		}
+	}
+
+	return false
+}
+
+/*
+repositoryRemote returns the origin url of a repository for error messages, falling back to the repository path.
+*/
+func repositoryRemote(repository models.Path) string {
+	remoteUrl, err := GetGitRemoteUrl(repository)
+	if err != nil || remoteUrl == "" {
+		return repository.String()
+	}
+
+	return remoteUrl
+}
+
+/*
+gitAuthenticationMessage describes an ErrAuthRequired error with the redacted remote and a hint on how to provide credentials.
+*/
+func gitAuthenticationMessage(remote string) string {
+	return fmt.Sprintf("%s for %s: configure a credential helper or token environment variable for this host in [[config.credentials]]", ErrAuthRequired, urls.RedactCredentials(remote))
+}
@@ -0,0 +1,139 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jeftadlvw/git-nest/models"
+	"regexp"
+	"strings"
+	"time"
+)
+
+/*
+gitNetworkErrorMessages contains git, curl and ssh output fragments that indicate a transient network failure.
+*/
+var gitNetworkErrorMessages = []string{
+	"could not resolve host",
+	"could not resolve proxy",
+	"failed to connect",
+	"connection refused",
+	"connection reset",
+	"connection timed out",
+	"operation timed out",
+	"network is unreachable",
+	"the remote end hung up unexpectedly",
+	"early eof",
+	"rpc failed",
+	"gnutls_handshake",
+	"ssl_error_syscall",
+	"kex_exchange_identification",
+	"received http code 407 from proxy",
+}
+
+/*
+gitServerErrorRegex matches http status codes that indicate an overloaded or failing server.
+*/
+var gitServerErrorRegex = regexp.MustCompile(`the requested url returned error: (5\d\d|429)`)
+
+/*
+gitRetryPolicy contains the retry policy of clones and pulls, see SetGitRetryPolicy.
+*/
+var gitRetryPolicy = models.Retry{}.RetryPolicy()
+
+/*
+gitRetryNotifier is called with a message before a failed operation is retried, see SetGitRetryNotifier.
+*/
+var gitRetryNotifier func(string)
+
+/*
+SetGitRetryPolicy sets how often clones and pulls are attempted and which failures are retried.
+*/
+func SetGitRetryPolicy(policy models.RetryPolicy) {
+	gitRetryPolicy = policy
+}
+
+/*
+SetGitRetryNotifier sets a function that is called with a message before a failed operation is retried.
+*/
+func SetGitRetryNotifier(notify func(message string)) {
+	gitRetryNotifier = notify
+}
+
+/*
+ClassifyGitError returns the class of a failed git operation that contacted a remote, based on its error and output.
+Returns an empty class if the cause is unknown, e.g. for a missing ref, or if the operation was cancelled.
+*/
+func ClassifyGitError(err error, output string) models.GitErrorClass {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return ""
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return models.GitErrorClassTimeout
+	}
+
+	if errors.Is(err, ErrAuthRequired) || IsGitAuthenticationError(output) {
+		return models.GitErrorClassAuthentication
+	}
+
+	output = strings.ToLower(output)
+	if gitServerErrorRegex.MatchString(output) {
+		return models.GitErrorClassServer
+	}
+
+	for _, message := range gitNetworkErrorMessages {
+		if strings.Contains(output, message) {
+			return models.GitErrorClassNetwork
+		}
+	}
+
+	return ""
+}
+
+/*
+retryGitOperation calls run until it succeeds, fails with an error that is not retried by the retry policy or
+the policy's attempts are exhausted. Before each retry, cleanup is called and the backoff delay is awaited.
+Returns the output and error of the last attempt and the number of attempts.
+*/
+func retryGitOperation(ctx context.Context, operation string, cleanup func(), run func(context.Context) (string, error)) (string, int, error) {
+	policy := gitRetryPolicy
+
+	for attempt := 1; ; attempt++ {
+		output, err := run(ctx)
+		if err == nil {
+			return output, attempt, nil
+		}
+
+		class := ClassifyGitError(err, output)
+		if attempt >= policy.Attempts || !policy.Retries(class) || ctx.Err() != nil {
+			return output, attempt, err
+		}
+
+		if cleanup != nil {
+			cleanup()
+		}
+
+		delay := policy.Delay(attempt)
+		if gitRetryNotifier != nil {
+			gitRetryNotifier(fmt.Sprintf("%s failed with a %s error (attempt %d of %d), retrying in %s", operation, class, attempt, policy.Attempts, delay))
+		}
+
+		select {
+		case <-ctx.Done():
+			return output, attempt, fmt.Errorf("%s was aborted while waiting to retry: %w", operation, ctx.Err())
+		case <-time.After(delay):
+		}
+	}
+}
+
+/*
+withAttempts adds the number of attempts to the error of an operation that was retried.
+*/
+func withAttempts(err error, attempts int) error {
+	if err == nil || attempts <= 1 {
+		return err
+	}
+
+	return fmt.Errorf("%w (after %d attempts)", err, attempts)
+}
@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jeftadlvw/git-nest/models"
+	"strings"
+)
+
+/*
+ErrGitSignatureUnverified is returned if a commit or tag is unsigned or signed by an untrusted key.
+*/
+var ErrGitSignatureUnverified = errors.New("signature could not be verified")
+
+/*
+GitVerifyCommit verifies the gpg or ssh signature of a commit in a local repository using git verify-commit.
+Trusted ssh keys are read from allowedSigners and trusted gpg keys from the keyring in gpgHome.
+Empty paths fall back to git's and gpg's own configuration.
+*/
+func GitVerifyCommit(repository models.Path, commit string, allowedSigners models.Path, gpgHome models.Path) error {
+	return gitVerifySignature(repository, "verify-commit", commit, allowedSigners, gpgHome)
+}
+
+/*
+GitVerifyTag verifies the gpg or ssh signature of a tag in a local repository using git verify-tag.
+As lightweight tags cannot be signed, the signature of the commit they point to is verified instead.
+See GitVerifyCommit for allowedSigners and gpgHome.
+*/
+func GitVerifyTag(repository models.Path, tag string, allowedSigners models.Path, gpgHome models.Path) error {
+	tag = strings.TrimSpace(tag)
+	if tag == "" {
+		return errors.New("tag cannot be blank")
+	}
+
+	args := []string{"cat-file", "-t", "refs/tags/" + tag}
+	objectType, err := RunCommandCombinedOutput(repository, "git", args...)
+	if err != nil {
+		return gitRefNotFoundError(repository, args, objectType, err, fmt.Sprintf("tag '%s' does not exist", tag))
+	}
+
+	if objectType != "tag" {
+		return gitVerifySignature(repository, "verify-commit", "refs/tags/"+tag, allowedSigners, gpgHome)
+	}
+
+	return gitVerifySignature(repository, "verify-tag", "refs/tags/"+tag, allowedSigners, gpgHome)
+}
+
+/*
+gitVerifySignature runs git verify-commit or git verify-tag with the passed trust configuration.
+*/
+func gitVerifySignature(repository models.Path, subcommand string, ref string, allowedSigners models.Path, gpgHome models.Path) error {
+	if repository.Empty() {
+		return errors.New("empty repository value")
+	}
+
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return errors.New("ref cannot be blank")
+	}
+
+	var args []string
+	if !allowedSigners.Empty() {
+		if !allowedSigners.IsFile() {
+			return fmt.Errorf("allowed signers file %s does not exist", allowedSigners)
+		}
+		args = append(args, "-c", "gpg.ssh.allowedSignersFile="+allowedSigners.String())
+	}
+	args = append(args, subcommand, ref)
+
+	cmd := constructCommand(repository, "git", args...)
+	if !gpgHome.Empty() {
+		if !gpgHome.IsDir() {
+			return fmt.Errorf("gpg home directory %s does not exist", gpgHome)
+		}
+		cmd.Env = append(cmd.Env, "GNUPGHOME="+gpgHome.String())
+	}
+
+	out, err := cmd.CombinedOutput()
+	if err == nil {
+		return nil
+	}
+
+	output := strings.TrimSpace(string(out))
+	if ClassifyGitOutput(output) == ErrNotARepository {
+		return newGitError(ErrNotARepository, fmt.Sprintf("%s is not a git repository", repository), repository, []string{subcommand, ref}, output, err)
+	}
+
+	// the last line contains the reason, e.g. 'No principal matched.'
+	reason := "no signature found"
+	if lines := strings.Split(output, "\n"); output != "" {
+		reason = strings.TrimSpace(lines[len(lines)-1])
+	}
+
+	return newGitError(ErrGitSignatureUnverified, fmt.Sprintf("%s for %s: %s", ErrGitSignatureUnverified, ref, reason), repository, []string{subcommand, ref}, output, err)
+}
@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"github.com/jeftadlvw/git-nest/models/urls"
+	"log/slog"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+/*
+logger contains the logger of git-nest, see SetLogger. Nothing is logged by default.
+*/
+var logger = slog.New(discardHandler{})
+
+/*
+SetLogger sets the logger that executed commands and other events are logged to. Passing nil disables logging.
+*/
+func SetLogger(l *slog.Logger) {
+	if l == nil {
+		l = slog.New(discardHandler{})
+	}
+
+	logger = l
+}
+
+/*
+Logger returns the logger set by SetLogger.
+*/
+func Logger() *slog.Logger {
+	return logger
+}
+
+/*
+logCommand logs a command that ran since start, together with its result. It is called by loggedCommand only.
+Credentials within its arguments are redacted.
+*/
+func logCommand(cmd *exec.Cmd, start time.Time, err error) {
+	if !logger.Enabled(context.Background(), slog.LevelDebug) {
+		return
+	}
+
+	args := make([]string, 0, len(cmd.Args))
+	for _, arg := range cmd.Args[1:] {
+		args = append(args, urls.RedactCredentials(arg))
+	}
+
+	exitCode := 0
+	if err != nil {
+		exitCode = -1
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		exitCode = exitErr.ExitCode()
+	}
+
+	attrs := []any{
+		slog.String("command", filepath.Base(cmd.Path)),
+		slog.Any("args", args),
+		slog.String("dir", cmd.Dir),
+		slog.Duration("duration", time.Since(start)),
+		slog.Int("exit_code", exitCode),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+
+	logger.Debug("command executed", attrs...)
+}
+
+/*
+discardHandler is a slog.Handler that discards all records.
+*/
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
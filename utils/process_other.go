@@ -0,0 +1,14 @@
+//go:build !unix
+
+package utils
+
+import (
+	"os/exec"
+)
+
+/*
+configureCommandCancellation makes a cancelled command get killed, as processes cannot be terminated gracefully.
+*/
+func configureCommandCancellation(cmd *exec.Cmd) {
+	cmd.WaitDelay = commandWaitDelay
+}
@@ -0,0 +1,26 @@
+//go:build unix
+
+package utils
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+/*
+configureCommandCancellation makes a cancelled command terminate gracefully, so that git can clean up after itself.
+If process groups are isolated, the whole process group is terminated, including git's child processes.
+*/
+func configureCommandCancellation(cmd *exec.Cmd) {
+	if isolateProcessGroups {
+		cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	}
+
+	cmd.Cancel = func() error {
+		if isolateProcessGroups {
+			return syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM)
+		}
+		return cmd.Process.Signal(syscall.SIGTERM)
+	}
+	cmd.WaitDelay = commandWaitDelay
+}
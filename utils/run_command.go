@@ -3,25 +3,102 @@ package utils
 import (
 	"bufio"
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"github.com/jeftadlvw/git-nest/models"
+	"os"
 	"os/exec"
+	"slices"
 	"strings"
 	"sync"
+	"time"
 )
 
+/*
+commandWaitDelay defines how long a cancelled command may take to exit before it is killed.
+*/
+const commandWaitDelay = 10 * time.Second
+
+/*
+commandContext contains the context all commands are bound to, see SetCommandContext.
+*/
+var commandContext = context.Background()
+
+/*
+isolateProcessGroups defines whether commands are started in their own process group, see SetIsolateProcessGroups.
+*/
+var isolateProcessGroups bool
+
+/*
+gitLocalEnvironmentVariables contains the variables returned by 'git rev-parse --local-env-vars'.
+*/
+var gitLocalEnvironmentVariables = []string{
+	"GIT_ALTERNATE_OBJECT_DIRECTORIES",
+	"GIT_CONFIG",
+	"GIT_CONFIG_PARAMETERS",
+	"GIT_CONFIG_COUNT",
+	"GIT_OBJECT_DIRECTORY",
+	"GIT_DIR",
+	"GIT_WORK_TREE",
+	"GIT_IMPLICIT_WORK_TREE",
+	"GIT_GRAFT_FILE",
+	"GIT_INDEX_FILE",
+	"GIT_NO_REPLACE_OBJECTS",
+	"GIT_REPLACE_REF_BASE",
+	"GIT_PREFIX",
+	"GIT_SHALLOW_FILE",
+	"GIT_COMMON_DIR",
+}
+
+/*
+SetCommandContext sets the context all commands are bound to. Once it is cancelled, running commands are terminated
+and no further commands are started.
+*/
+func SetCommandContext(ctx context.Context) {
+	commandContext = ctx
+}
+
+/*
+CommandContext returns the context all commands are bound to, see SetCommandContext.
+*/
+func CommandContext() context.Context {
+	return commandContext
+}
+
+/*
+SetIsolateProcessGroups sets whether commands are started in their own process group on unix systems, so that
+cancelling a command also terminates all of its child processes, e.g. git's remote helpers and ssh.
+Commands in their own process group cannot read from the terminal, so this should only be enabled if git
+does not prompt for input.
+*/
+func SetIsolateProcessGroups(isolate bool) {
+	isolateProcessGroups = isolate
+}
+
 /*
 RunCommand is a subset-wrapper for exec.Command, providing separate return values for stdout and stderr.
+Both are also returned if the command fails.
 */
 func RunCommand(d models.Path, command string, args ...string) (string, string, error) {
-	cmd := constructCommand(d, command, args...)
+	ctx, cancel := contextWithTimeout(commandContext, gitTimeouts.Local)
+	defer cancel()
+
+	return RunCommandContext(ctx, d, command, args...)
+}
+
+/*
+RunCommandContext is like RunCommand, but terminates the command if ctx is done.
+*/
+func RunCommandContext(ctx context.Context, d models.Path, command string, args ...string) (string, string, error) {
+	cmd := constructCommandContext(ctx, d, command, args...)
 
 	var errBuf bytes.Buffer
 	cmd.Stderr = &errBuf
 
 	stdout, err := cmd.Output()
 	if err != nil {
-		return "", "", err
+		err = withContextError(ctx, err)
 	}
 
 	return strings.TrimSpace(string(stdout)), strings.TrimSpace(errBuf.String()), err
@@ -29,13 +106,24 @@ func RunCommand(d models.Path, command string, args ...string) (string, string,
 
 /*
 RunCommandCombinedOutput is a subset-wrapper for exec.Command, returning both stdout and stderr in one string.
+The output is also returned if the command fails, so that callers can inspect git's error messages.
 */
 func RunCommandCombinedOutput(d models.Path, command string, args ...string) (string, error) {
-	cmd := constructCommand(d, command, args...)
+	ctx, cancel := contextWithTimeout(commandContext, gitTimeouts.Local)
+	defer cancel()
+
+	return RunCommandCombinedOutputContext(ctx, d, command, args...)
+}
+
+/*
+RunCommandCombinedOutputContext is like RunCommandCombinedOutput, but terminates the command if ctx is done.
+*/
+func RunCommandCombinedOutputContext(ctx context.Context, d models.Path, command string, args ...string) (string, error) {
+	cmd := constructCommandContext(ctx, d, command, args...)
 
 	stdout, err := cmd.CombinedOutput()
 	if err != nil {
-		return "", err
+		err = withContextError(ctx, err)
 	}
 
 	return strings.TrimSpace(string(stdout)), err
@@ -45,13 +133,33 @@ func RunCommandCombinedOutput(d models.Path, command string, args ...string) (st
 RunCommandLiveOutput is a wrapper for exec.Command that takes a callback function for updates in both stdout and stderr streams.
 */
 func RunCommandLiveOutput(fStdout func(string), fStderr func(string), wd models.Path, command string, args ...string) error {
-	return runCommandLive(fStdout, fStderr, wd, command, args...)
+	ctx, cancel := contextWithTimeout(commandContext, gitTimeouts.Local)
+	defer cancel()
+
+	return RunCommandLiveOutputContext(ctx, fStdout, fStderr, wd, command, args...)
+}
+
+/*
+RunCommandLiveOutputContext is like RunCommandLiveOutput, but terminates the command if ctx is done.
+*/
+func RunCommandLiveOutputContext(ctx context.Context, fStdout func(string), fStderr func(string), wd models.Path, command string, args ...string) error {
+	return runCommandLive(ctx, fStdout, fStderr, wd, command, args...)
 }
 
 /*
 RunCommandLiveOutputCombinedOutput is a wrapper for exec.Command that takes a callback function for updates in a combined stdout and stderr stream.
 */
 func RunCommandLiveOutputCombinedOutput(fStdout func(string), wd models.Path, command string, args ...string) error {
+	ctx, cancel := contextWithTimeout(commandContext, gitTimeouts.Local)
+	defer cancel()
+
+	return RunCommandLiveOutputCombinedOutputContext(ctx, fStdout, wd, command, args...)
+}
+
+/*
+RunCommandLiveOutputCombinedOutputContext is like RunCommandLiveOutputCombinedOutput, but terminates the command if ctx is done.
+*/
+func RunCommandLiveOutputCombinedOutputContext(ctx context.Context, fStdout func(string), wd models.Path, command string, args ...string) error {
 	var mutex = &sync.Mutex{}
 
 	fStdoutWrapper := func(s string) {
@@ -60,23 +168,77 @@ func RunCommandLiveOutputCombinedOutput(fStdout func(string), wd models.Path, co
 		mutex.Unlock()
 	}
 
-	return runCommandLive(fStdoutWrapper, fStdoutWrapper, wd, command, args...)
+	return runCommandLive(ctx, fStdoutWrapper, fStdoutWrapper, wd, command, args...)
 }
 
-func constructCommand(wd models.Path, command string, args ...string) exec.Cmd {
-	cmd := exec.Command(command, args...)
+func constructCommand(wd models.Path, command string, args ...string) *loggedCommand {
+	return constructCommandContext(commandContext, wd, command, args...)
+}
+
+func constructCommandContext(ctx context.Context, wd models.Path, command string, args ...string) *loggedCommand {
+	cmd := exec.CommandContext(ctx, command, args...)
+	cmd.Env = commandEnvironment()
 	addEnglishLocaleEnv(cmd)
+	configureCommandCancellation(cmd)
 	if !wd.Empty() {
 		cmd.Dir = wd.String()
 	}
 
-	return *cmd
+	return &loggedCommand{Cmd: cmd}
 }
 
-func runCommandLive(fStdout func(string), fStderr func(string), wd models.Path, command string, args ...string) error {
+/*
+loggedCommand is an exec.Cmd that logs itself and its result once it finished, see logCommand.
+Every command git-nest runs is constructed by constructCommand, so that every command is logged.
+*/
+type loggedCommand struct {
+	*exec.Cmd
+	start time.Time
+}
+
+func (c *loggedCommand) Start() error {
+	c.start = time.Now()
+	err := c.Cmd.Start()
+	if err != nil {
+		logCommand(c.Cmd, c.start, err)
+	}
+
+	return err
+}
+
+func (c *loggedCommand) Wait() error {
+	err := c.Cmd.Wait()
+	logCommand(c.Cmd, c.start, err)
+	return err
+}
+
+func (c *loggedCommand) Run() error {
+	err := c.Start()
+	if err != nil {
+		return err
+	}
+
+	return c.Wait()
+}
+
+func (c *loggedCommand) Output() ([]byte, error) {
+	start := time.Now()
+	out, err := c.Cmd.Output()
+	logCommand(c.Cmd, start, err)
+	return out, err
+}
+
+func (c *loggedCommand) CombinedOutput() ([]byte, error) {
+	start := time.Now()
+	out, err := c.Cmd.CombinedOutput()
+	logCommand(c.Cmd, start, err)
+	return out, err
+}
+
+func runCommandLive(ctx context.Context, fStdout func(string), fStderr func(string), wd models.Path, command string, args ...string) error {
 
 	// configure command
-	cmd := constructCommand(wd, command, args...)
+	cmd := constructCommandContext(ctx, wd, command, args...)
 
 	// obtain pipes
 	stdoutPipe, err := cmd.StdoutPipe()
@@ -112,10 +274,47 @@ func runCommandLive(fStdout func(string), fStderr func(string), wd models.Path,
 	if err = cmd.Start(); err != nil {
 		return fmt.Errorf("could not start command: %w", err)
 	}
-	if err := cmd.Wait(); err != nil {
+	err = cmd.Wait()
+	if err != nil {
+		return withContextError(ctx, err)
+	}
+
+	return nil
+}
+
+/*
+contextWithTimeout returns a copy of ctx that is cancelled after timeout. A timeout of zero disables it.
+*/
+func contextWithTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
+
+/*
+withContextError adds the reason to the error of a command that was terminated because ctx is done.
+*/
+func withContextError(ctx context.Context, err error) error {
+	if ctx.Err() == nil || errors.Is(err, ctx.Err()) {
 		return err
 	}
 
+	return fmt.Errorf("%w (%w)", ctx.Err(), err)
+}
+
+/*
+commandContextError returns a descriptive error if a command was terminated because ctx is done, or nil otherwise.
+*/
+func commandContextError(ctx context.Context, command string, timeout time.Duration) error {
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return fmt.Errorf("%s did not finish within %s: %w", command, timeout, context.DeadlineExceeded)
+	case errors.Is(ctx.Err(), context.Canceled):
+		return fmt.Errorf("%s was cancelled: %w", command, context.Canceled)
+	}
+
 	return nil
 }
 
@@ -153,6 +352,23 @@ func scanLines(data []byte, atEOF bool) (advance int, token []byte, err error) {
 	return 0, nil, nil
 }
 
+/*
+commandEnvironment returns the environment for child processes. It is based on the current process' environment,
+but does not contain git's repository-local variables. Otherwise, commands that run within nested modules could operate
+on the parent repository, e.g. when git-nest is executed from within a git hook.
+*/
+func commandEnvironment() []string {
+	var env []string
+	for _, variable := range os.Environ() {
+		name, _, _ := strings.Cut(variable, "=")
+		if !slices.Contains(gitLocalEnvironmentVariables, name) {
+			env = append(env, variable)
+		}
+	}
+
+	return env
+}
+
 /*
 addEnglishLocaleEnv adds an environment variables that causes some programs to force their output language to english.
 Works on unix only, but is added for every platform.
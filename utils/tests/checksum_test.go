@@ -7,6 +7,7 @@ import (
 	"github.com/jeftadlvw/git-nest/utils"
 	"io"
 	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -81,3 +82,67 @@ func TestCalculateChecksumF(t *testing.T) {
 		})
 	}
 }
+
+func TestCalculateChecksumFiles(t *testing.T) {
+	setup := func(t *testing.T) models.Path {
+		tempDir := models.Path(t.TempDir())
+		for name, content := range map[string]string{"a.txt": "a", "dir/b.txt": "b"} {
+			err := os.MkdirAll(filepath.Dir(filepath.Join(string(tempDir), name)), os.ModePerm)
+			if err != nil {
+				t.Fatalf("error creating directory: %s", err)
+			}
+			err = utils.WriteStrToFile(tempDir.SJoin(name), content)
+			if err != nil {
+				t.Fatalf("error writing temporary file: %s", err)
+			}
+		}
+		return tempDir
+	}
+
+	baseDir := setup(t)
+	baseChecksum, err := utils.CalculateChecksumFiles(baseDir, "a.txt", "dir/b.txt")
+	if err != nil {
+		t.Fatalf("error calculating checksum: %s", err)
+	}
+
+	cases := []struct {
+		modify  func(dir models.Path) error
+		files   []string
+		changed bool
+	}{
+		{func(dir models.Path) error { return nil }, []string{"a.txt", "dir/b.txt"}, false},
+		{func(dir models.Path) error { return nil }, []string{"dir/b.txt", "a.txt", "a.txt"}, false},
+		{func(dir models.Path) error { return utils.WriteStrToFile(dir.SJoin("a.txt"), "changed") }, []string{"a.txt", "dir/b.txt"}, true},
+		{func(dir models.Path) error {
+			return os.Rename(filepath.Join(string(dir), "a.txt"), filepath.Join(string(dir), "c.txt"))
+		}, []string{"c.txt", "dir/b.txt"}, true},
+		{func(dir models.Path) error { return os.Remove(filepath.Join(string(dir), "a.txt")) }, []string{"a.txt", "dir/b.txt"}, true},
+		{func(dir models.Path) error { return os.Chmod(filepath.Join(string(dir), "a.txt"), 0755) }, []string{"a.txt", "dir/b.txt"}, true},
+		{func(dir models.Path) error { return utils.WriteStrToFile(dir.SJoin("c.txt"), "") }, []string{"a.txt", "c.txt", "dir/b.txt"}, true},
+		{func(dir models.Path) error {
+			if err := os.Remove(filepath.Join(string(dir), "a.txt")); err != nil {
+				return err
+			}
+			return os.Symlink("dir/b.txt", filepath.Join(string(dir), "a.txt"))
+		}, []string{"a.txt", "dir/b.txt"}, true},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestCalculateChecksumFiles-%d", index+1), func(t *testing.T) {
+			dir := setup(t)
+			err := tc.modify(dir)
+			if err != nil {
+				t.Fatalf("error modifying files: %s", err)
+			}
+
+			checksum, err := utils.CalculateChecksumFiles(dir, tc.files...)
+			if err != nil {
+				t.Fatalf("error calculating checksum: %s", err)
+			}
+
+			if (checksum != baseChecksum) != tc.changed {
+				t.Fatalf("unexpected checksum change: %t", checksum != baseChecksum)
+			}
+		})
+	}
+}
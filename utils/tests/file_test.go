@@ -56,3 +56,68 @@ func TestReadFileIO(t *testing.T) {
 		t.Fatalf("Writing and reading file caused different results, expected: %s, got: %s", fileContents, readFileContents)
 	}
 }
+
+func TestWriteStrToFileAtomic(t *testing.T) {
+	dir := models.Path(t.TempDir())
+
+	err := utils.WriteStrToFileAtomic(dir, "foo")
+	if err == nil {
+		t.Fatalf("WriteStrToFileAtomic() to directory should have returned an error, but did not")
+	}
+
+	file := dir.SJoin("file")
+	for _, content := range []string{"first", "second"} {
+		err = utils.WriteStrToFileAtomic(file, content)
+		if err != nil {
+			t.Fatalf("WriteStrToFileAtomic() returned error, but should've not: %s", err)
+		}
+
+		readFileContents, err := utils.ReadFileToStr(file)
+		if err != nil || readFileContents != content {
+			t.Fatalf("unexpected file contents %q, expected %q: %v", readFileContents, content, err)
+		}
+	}
+
+	// permissions are kept
+	err = os.Chmod(file.String(), 0600)
+	if err != nil {
+		t.Fatalf("error changing permissions: %s", err)
+	}
+
+	err = utils.WriteStrToFileAtomic(file, "third")
+	if err != nil {
+		t.Fatalf("WriteStrToFileAtomic() returned error, but should've not: %s", err)
+	}
+
+	info, err := os.Stat(file.String())
+	if err != nil || info.Mode().Perm() != 0600 {
+		t.Fatalf("unexpected permissions %v: %v", info.Mode().Perm(), err)
+	}
+
+	// symbolic links are followed
+	link := dir.SJoin("link")
+	err = os.Symlink(file.String(), link.String())
+	if err != nil {
+		t.Skipf("symbolic links not supported: %s", err)
+	}
+
+	err = utils.WriteStrToFileAtomic(link, "fourth")
+	if err != nil {
+		t.Fatalf("WriteStrToFileAtomic() returned error, but should've not: %s", err)
+	}
+
+	linkInfo, err := os.Lstat(link.String())
+	if err != nil || linkInfo.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("symbolic link has been replaced")
+	}
+
+	readFileContents, _ := utils.ReadFileToStr(file)
+	if readFileContents != "fourth" {
+		t.Fatalf("link target was not written: %q", readFileContents)
+	}
+
+	entries, _ := os.ReadDir(dir.String())
+	if len(entries) != 2 {
+		t.Fatalf("temporary files have not been removed: %d entries", len(entries))
+	}
+}
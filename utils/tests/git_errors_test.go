@@ -0,0 +1,90 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/test_env"
+	"github.com/jeftadlvw/git-nest/utils"
+	"testing"
+)
+
+func TestClassifyGitOutput(t *testing.T) {
+	cases := []struct {
+		output   string
+		expected error
+	}{
+		{"", nil},
+		{"fatal: something unexpected happened", nil},
+		{"fatal: not a git repository (or any of the parent directories): .git", utils.ErrNotARepository},
+		{"error: pathspec 'missing' did not match any file(s) known to git", utils.ErrRefNotFound},
+		{"fatal: couldn't find remote ref missing", utils.ErrRefNotFound},
+		{"fatal: repository 'https://example.com/repository.git/' not found", utils.ErrRepositoryNotFound},
+		{"fatal: 'origin' does not appear to be a git repository", utils.ErrRepositoryNotFound},
+		{"fatal: destination path 'repository' already exists and is not an empty directory.", utils.ErrDestinationExists},
+		{"CONFLICT (content): Merge conflict in file.txt\nAutomatic merge failed; fix conflicts and then commit the result.", utils.ErrMergeConflict},
+		{"error: Your local changes to the following files would be overwritten by merge:", utils.ErrLocalChanges},
+		{"fatal: Not possible to fast-forward, aborting.", utils.ErrDivergedBranches},
+		{"There is no tracking information for the current branch.", utils.ErrNoUpstream},
+		{"fatal: could not read Username for 'https://example.com': terminal prompts disabled", utils.ErrAuthRequired},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestClassifyGitOutput-%d", index+1), func(t *testing.T) {
+			t.Parallel()
+
+			if kind := utils.ClassifyGitOutput(tc.output); kind != tc.expected {
+				t.Fatalf("unexpected kind: >%v<, expected >%v<", kind, tc.expected)
+			}
+		})
+	}
+}
+
+func TestGitCheckoutError(t *testing.T) {
+	repository := models.Path(t.TempDir())
+	err := test_env.CreateLocalRepository(repository)
+	if err != nil {
+		t.Fatalf("error creating repository: %s", err)
+	}
+
+	noRepository := models.Path(t.TempDir())
+	ceiling := noRepository.Parent()
+
+	cases := []struct {
+		dir      models.Path
+		ref      string
+		expected error
+	}{
+		{repository, "missing", utils.ErrRefNotFound},
+		{noRepository, "main", utils.ErrNotARepository},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestGitCheckoutError-%d", index+1), func(t *testing.T) {
+			t.Setenv("GIT_CEILING_DIRECTORIES", ceiling.String())
+
+			err := utils.GitCheckout(tc.dir, tc.ref)
+			if err == nil {
+				t.Fatalf("no error, but expected one")
+			}
+
+			if !errors.Is(err, tc.expected) {
+				t.Fatalf("error is not of kind >%v<: %s", tc.expected, err)
+			}
+
+			var gitErr *utils.GitError
+			if !errors.As(err, &gitErr) {
+				t.Fatalf("error is not a GitError: %T", err)
+			}
+			if gitErr.ExitCode != 128 && gitErr.ExitCode != 1 {
+				t.Fatalf("unexpected exit code: %d", gitErr.ExitCode)
+			}
+			if gitErr.Stderr == "" {
+				t.Fatalf("GitError contains no output")
+			}
+			if gitErr.Dir != tc.dir.String() {
+				t.Fatalf("unexpected directory: %s, expected %s", gitErr.Dir, tc.dir)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/utils"
+	"testing"
+)
+
+func TestParseGitProgress(t *testing.T) {
+	cases := []struct {
+		line     string
+		expected models.Progress
+		ok       bool
+	}{
+		{"Receiving objects:  45% (450/1000), 1.20 MiB | 2.00 MiB/s", models.Progress{Phase: "Receiving objects", Percent: 45, Current: 450, Total: 1000}, true},
+		{"remote: Counting objects: 100% (10/10), done.", models.Progress{Phase: "Counting objects", Percent: 100, Current: 10, Total: 10}, true},
+		{"remote: Compressing objects:   3% (1/30)", models.Progress{Phase: "Compressing objects", Percent: 3, Current: 1, Total: 30}, true},
+		{"Resolving deltas: 100% (5/5), done.", models.Progress{Phase: "Resolving deltas", Percent: 100, Current: 5, Total: 5}, true},
+		{"Updating files:  50% (1/2)", models.Progress{Phase: "Updating files", Percent: 50, Current: 1, Total: 2}, true},
+		{"Cloning into 'repository'...", models.Progress{}, false},
+		{"remote: Enumerating objects: 12, done.", models.Progress{}, false},
+		{"", models.Progress{}, false},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestParseGitProgress-%d", index+1), func(t *testing.T) {
+			t.Parallel()
+
+			progress, ok := utils.ParseGitProgress(tc.line)
+			if ok != tc.ok {
+				t.Fatalf("unexpected result: %t, expected %t", ok, tc.ok)
+			}
+			if progress != tc.expected {
+				t.Fatalf("unexpected progress: %+v, expected %+v", progress, tc.expected)
+			}
+		})
+	}
+}
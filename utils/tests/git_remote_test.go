@@ -0,0 +1,32 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/jeftadlvw/git-nest/utils"
+	"testing"
+)
+
+func TestIsGitAuthenticationError(t *testing.T) {
+	cases := []struct {
+		output   string
+		expected bool
+	}{
+		{"", false},
+		{"fatal: could not read Username for 'https://example.com': terminal prompts disabled", true},
+		{"remote: Invalid username or password.\nfatal: Authentication failed for 'https://example.com/repository.git/'", true},
+		{"git@example.com: Permission denied (publickey).\nfatal: Could not read from remote repository.", true},
+		{"fatal: unable to access 'https://example.com/repository.git/': The requested URL returned error: 403", true},
+		{"fatal: repository 'https://example.com/repository.git/' not found", false},
+		{"fatal: unable to access 'https://example.com/': Could not resolve host: example.com", false},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestIsGitAuthenticationError-%d", index+1), func(t *testing.T) {
+			t.Parallel()
+
+			if result := utils.IsGitAuthenticationError(tc.output); result != tc.expected {
+				t.Fatalf("IsGitAuthenticationError(%s) = %t, expected %t", tc.output, result, tc.expected)
+			}
+		})
+	}
+}
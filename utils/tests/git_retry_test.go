@@ -0,0 +1,114 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/utils"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClassifyGitError(t *testing.T) {
+	failed := errors.New("exit status 128")
+
+	cases := []struct {
+		err      error
+		output   string
+		expected models.GitErrorClass
+	}{
+		{nil, "fatal: unable to access 'https://example.com/': Could not resolve host: example.com", ""},
+		{failed, "fatal: unable to access 'https://example.com/': Could not resolve host: example.com", models.GitErrorClassNetwork},
+		{failed, "error: RPC failed; curl 56 Recv failure: Connection reset by peer\nfatal: early EOF", models.GitErrorClassNetwork},
+		{failed, "ssh: connect to host example.com port 22: Connection refused\nfatal: Could not read from remote repository.", models.GitErrorClassNetwork},
+		{failed, "fatal: unable to access 'https://example.com/repository.git/': The requested URL returned error: 503", models.GitErrorClassServer},
+		{failed, "fatal: unable to access 'https://example.com/repository.git/': The requested URL returned error: 429", models.GitErrorClassServer},
+		{failed, "fatal: Authentication failed for 'https://example.com/repository.git/'", models.GitErrorClassAuthentication},
+		{fmt.Errorf("git clone did not finish within 1s: %w", context.DeadlineExceeded), "", models.GitErrorClassTimeout},
+		{fmt.Errorf("git clone was cancelled: %w", context.Canceled), "fatal: early EOF", ""},
+		{failed, "fatal: repository 'https://example.com/repository.git/' not found", ""},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestClassifyGitError-%d", index+1), func(t *testing.T) {
+			t.Parallel()
+
+			if class := utils.ClassifyGitError(tc.err, tc.output); class != tc.expected {
+				t.Fatalf("unexpected class: >%s<, expected >%s<", class, tc.expected)
+			}
+		})
+	}
+}
+
+func TestCloneGitRepositoryRetry(t *testing.T) {
+	var notifications []string
+	utils.SetGitRetryNotifier(func(message string) {
+		notifications = append(notifications, message)
+	})
+	defer utils.SetGitRetryNotifier(nil)
+	defer utils.SetGitRetryPolicy(models.Retry{}.RetryPolicy())
+
+	// otherwise, git runs the ssh command once more to detect its variant
+	t.Setenv("GIT_SSH_VARIANT", "ssh")
+
+	counterDir := models.Path(t.TempDir())
+	counterFile := counterDir.SJoin("attempts")
+
+	cases := []struct {
+		sshCommand string
+		retry      models.Retry
+		attempts   int
+	}{
+		// refused connections are retried until the attempts are exhausted
+		{"echo 'ssh: connect to host example.com port 22: Connection refused' >&2; exit 255", models.Retry{Attempts: 3, Backoff: "10ms"}, 3},
+		{"echo 'ssh: connect to host example.com port 22: Connection refused' >&2; exit 255", models.Retry{}, 1},
+		{"echo 'ssh: connect to host example.com port 22: Connection refused' >&2; exit 255", models.Retry{Attempts: 3, Backoff: "10ms", RetryOn: []models.GitErrorClass{models.GitErrorClassServer}}, 1},
+
+		// missing repositories are never retried
+		{"echo 'ERROR: Repository not found.' >&2; exit 1", models.Retry{Attempts: 3, Backoff: "10ms"}, 1},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestCloneGitRepositoryRetry-%d", index+1), func(t *testing.T) {
+			notifications = nil
+			_ = os.Remove(counterFile.String())
+
+			t.Setenv("GIT_SSH_COMMAND", fmt.Sprintf("echo attempt >> '%s'; %s #", counterFile, tc.sshCommand))
+			utils.SetGitRetryPolicy(tc.retry.RetryPolicy())
+
+			tempDir := models.Path(t.TempDir())
+
+			start := time.Now()
+			err := utils.CloneGitRepositoryContext(context.Background(), "ssh://example.com/repository.git", tempDir, "", nil)
+			if err == nil {
+				t.Fatalf("no error, but expected one")
+			}
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Fatalf("clone took too long: %s", elapsed)
+			}
+
+			counter, err2 := os.ReadFile(counterFile.String())
+			if err2 != nil {
+				t.Fatalf("error reading attempts: %s", err2)
+			}
+
+			if attempts := strings.Count(string(counter), "attempt"); attempts != tc.attempts {
+				t.Fatalf("unexpected number of attempts: %d, expected %d", attempts, tc.attempts)
+			}
+			if len(notifications) != tc.attempts-1 {
+				t.Fatalf("unexpected number of retry notifications: %d, expected %d", len(notifications), tc.attempts-1)
+			}
+			if hasAttempts := strings.Contains(err.Error(), fmt.Sprintf("(after %d attempts)", tc.attempts)); hasAttempts != (tc.attempts > 1) {
+				t.Fatalf("error does not report the number of attempts: %s", err)
+			}
+
+			clonePath := tempDir.SJoin("repository")
+			if clonePath.Exists() {
+				t.Fatalf("partial clone %s was not removed", clonePath)
+			}
+		})
+	}
+}
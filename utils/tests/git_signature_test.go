@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jeftadlvw/git-nest/models"
+	"github.com/jeftadlvw/git-nest/test_env"
+	"github.com/jeftadlvw/git-nest/utils"
+	"os/exec"
+	"testing"
+)
+
+func TestGitVerifySignature(t *testing.T) {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen is not installed")
+	}
+
+	keyDir := models.Path(t.TempDir())
+	key, trustedSigners, err := test_env.CreateSshSigningKey(keyDir, "trusted", "foo@email.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, untrustedSigners, err := test_env.CreateSshSigningKey(keyDir, "untrusted", "foo@email.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repoDir := models.Path(t.TempDir())
+	err = test_env.CreateLocalRepository(repoDir)
+	if err != nil {
+		t.Fatalf("error creating local repository: %s", err)
+	}
+	err = test_env.ConfigureSshSigning(repoDir, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, args := range [][]string{
+		{"commit", "--allow-empty", "-S", "-m", "signed"},
+		{"tag", "-s", "-m", "signed tag", "signed"},
+		{"tag", "-a", "-m", "unsigned tag", "annotated"},
+		{"tag", "lightweight"},
+		{"commit", "--allow-empty", "-m", "unsigned"},
+		{"tag", "lightweight-unsigned"},
+	} {
+		out, err := utils.RunCommandCombinedOutput(repoDir, "git", args...)
+		if err != nil {
+			t.Fatalf("error running git %s: %s; %s", args[0], err, out)
+		}
+	}
+
+	missingSigners := keyDir.Join("missing")
+
+	cases := []struct {
+		tag            bool
+		ref            string
+		allowedSigners models.Path
+		err            bool
+		unverified     bool
+	}{
+		{false, "HEAD~1", trustedSigners, false, false},
+		{false, "HEAD~1", untrustedSigners, true, true},
+		{false, "HEAD", trustedSigners, true, true},
+		{true, "signed", trustedSigners, false, false},
+		{true, "signed", untrustedSigners, true, true},
+		{true, "annotated", trustedSigners, true, true},
+		{true, "lightweight", trustedSigners, false, false},
+		{true, "lightweight-unsigned", trustedSigners, true, true},
+		{true, "nonexisting", trustedSigners, true, false},
+		{false, "HEAD~1", missingSigners, true, false},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestGitVerifySignature-%d", index+1), func(t *testing.T) {
+			verify := utils.GitVerifyCommit
+			if tc.tag {
+				verify = utils.GitVerifyTag
+			}
+
+			err := verify(repoDir, tc.ref, tc.allowedSigners, "")
+			if tc.err && err == nil {
+				t.Fatalf("no error, but expected one")
+			}
+			if !tc.err && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if errors.Is(err, utils.ErrGitSignatureUnverified) != tc.unverified {
+				t.Fatalf("unexpected error type: %v", err)
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package tests
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/jeftadlvw/git-nest/models"
 	"github.com/jeftadlvw/git-nest/test_env"
@@ -8,9 +10,11 @@ import (
 	"github.com/jeftadlvw/git-nest/utils"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 )
 
 const (
@@ -66,6 +70,55 @@ func TestCloneGitRepository(t *testing.T) {
 
 }
 
+func TestCloneGitRepositoryContext(t *testing.T) {
+	// the ssh command hangs like an unresponsive remote
+	t.Setenv("GIT_SSH_COMMAND", "sleep 30 #")
+	utils.SetIsolateProcessGroups(true)
+	defer utils.SetIsolateProcessGroups(false)
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	cases := []struct {
+		ctx     func() (context.Context, context.CancelFunc)
+		timeout models.GitTimeouts
+		err     error
+	}{
+		{func() (context.Context, context.CancelFunc) { return cancelled, func() {} }, models.GitTimeouts{}, context.Canceled},
+		{func() (context.Context, context.CancelFunc) { return context.Background(), func() {} }, models.GitTimeouts{Clone: 500 * time.Millisecond}, context.DeadlineExceeded},
+		{func() (context.Context, context.CancelFunc) {
+			return context.WithTimeout(context.Background(), 500*time.Millisecond)
+		}, models.GitTimeouts{}, context.DeadlineExceeded},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestCloneGitRepositoryContext-%d", index+1), func(t *testing.T) {
+			utils.SetGitTimeouts(tc.timeout)
+			defer utils.SetGitTimeouts(models.Timeouts{}.GitTimeouts())
+
+			ctx, cancel := tc.ctx()
+			defer cancel()
+
+			tempDir := models.Path(t.TempDir())
+
+			start := time.Now()
+			err := utils.CloneGitRepositoryContext(ctx, "ssh://git.example.com/repository.git", tempDir, "repository", nil)
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Fatalf("clone was not aborted in time, took %s", elapsed)
+			}
+
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("unexpected error: %v, expected %v", err, tc.err)
+			}
+
+			clonePath := tempDir.SJoin("repository")
+			if clonePath.Exists() {
+				t.Fatalf("partial clone %s was not removed", clonePath)
+			}
+		})
+	}
+}
+
 func TestGitCheckout(t *testing.T) {
 	t.Parallel()
 
@@ -544,3 +597,475 @@ func TestGetGitHasUnpublishedChanges(t *testing.T) {
 		})
 	}
 }
+
+func TestGetGitRemoteRefs(t *testing.T) {
+	t.Parallel()
+
+	remoteDir := models.Path(t.TempDir())
+	err := test_env.CreateLocalRepository(remoteDir, "first", "second")
+	if err != nil {
+		t.Fatalf("error creating local repository: %s", err)
+	}
+
+	for _, args := range [][]string{
+		{"tag", "v1.2.0", "HEAD~1"},
+		{"tag", "-a", "v1.10.0", "-m", "annotated"},
+		{"tag", "v1.9.0"},
+		{"branch", test_env.RepoBranch1, "HEAD~1"},
+	} {
+		out, err := utils.RunCommandCombinedOutput(remoteDir, "git", args...)
+		if err != nil {
+			t.Fatalf("error running git %s: %s; %s", args[0], err, out)
+		}
+	}
+
+	headSha, _, err := utils.GetGitFetchHead(remoteDir)
+	if err != nil {
+		t.Fatalf("error getting head: %s", err)
+	}
+
+	cases := []struct {
+		url string
+		err bool
+	}{
+		{"", true},
+		{"   ", true},
+		{string(nonExistingDir), true},
+		{remoteDir.String(), false},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestGetGitRemoteRefs-%d", index+1), func(t *testing.T) {
+			t.Parallel()
+
+			refs, err := utils.GetGitRemoteRefs(tc.url)
+			if tc.err && err == nil {
+				t.Fatalf("no error, but expected one")
+			}
+			if !tc.err && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if tc.err {
+				return
+			}
+
+			if refs.Head != test_env.RepoBranchDefault {
+				t.Fatalf("unexpected head: >%s<, expected >%s<", refs.Head, test_env.RepoBranchDefault)
+			}
+			if refs.Branches[test_env.RepoBranchDefault] != headSha {
+				t.Fatalf("unexpected branch tip: >%s<, expected >%s<", refs.Branches[test_env.RepoBranchDefault], headSha)
+			}
+			if _, ok := refs.Branches[test_env.RepoBranch1]; !ok {
+				t.Fatalf("branch %s not reported", test_env.RepoBranch1)
+			}
+			if strings.Join(refs.TagOrder, ",") != "v1.2.0,v1.9.0,v1.10.0" {
+				t.Fatalf("unexpected tag order: %v", refs.TagOrder)
+			}
+			if refs.Tags["v1.10.0"] != headSha {
+				t.Fatalf("annotated tag was not peeled: >%s<, expected >%s<", refs.Tags["v1.10.0"], headSha)
+			}
+		})
+	}
+}
+
+func TestGetGitCommitsBehind(t *testing.T) {
+	t.Parallel()
+
+	repoDir := models.Path(t.TempDir())
+	err := test_env.CreateLocalRepository(repoDir, "first", "second", "third")
+	if err != nil {
+		t.Fatalf("error creating local repository: %s", err)
+	}
+
+	headSha, _, err := utils.GetGitFetchHead(repoDir)
+	if err != nil {
+		t.Fatalf("error getting head: %s", err)
+	}
+
+	_, err = utils.RunCommandCombinedOutput(repoDir, "git", "checkout", "--detach", "HEAD~2")
+	if err != nil {
+		t.Fatalf("error detaching head: %s", err)
+	}
+
+	cases := []struct {
+		dir      models.Path
+		commit   string
+		expected int
+		err      bool
+	}{
+		{"", headSha, -1, true},
+		{repoDir, "", -1, true},
+		{repoDir, headSha, 2, false},
+		{repoDir, "HEAD", 0, false},
+		{repoDir, "0000000000000000000000000000000000000001", -1, false},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestGetGitCommitsBehind-%d", index+1), func(t *testing.T) {
+			t.Parallel()
+
+			behind, err := utils.GetGitCommitsBehind(tc.dir, tc.commit)
+			if tc.err && err == nil {
+				t.Fatalf("no error, but expected one")
+			}
+			if !tc.err && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if behind != tc.expected {
+				t.Fatalf("unexpected commit count: %d, expected %d", behind, tc.expected)
+			}
+		})
+	}
+}
+
+func TestGetGitPath(t *testing.T) {
+	t.Parallel()
+
+	repoDir := models.Path(t.TempDir())
+	err := test_env.CreateLocalRepository(repoDir)
+	if err != nil {
+		t.Fatalf("error creating local repository: %s", err)
+	}
+
+	hooksPathRepoDir := models.Path(t.TempDir())
+	err = test_env.CreateLocalRepository(hooksPathRepoDir)
+	if err != nil {
+		t.Fatalf("error creating local repository: %s", err)
+	}
+
+	_, err = utils.RunCommandCombinedOutput(hooksPathRepoDir, "git", "config", "core.hooksPath", ".githooks")
+	if err != nil {
+		t.Fatalf("error setting hooks path: %s", err)
+	}
+
+	cases := []struct {
+		dir      models.Path
+		path     string
+		expected models.Path
+		err      bool
+	}{
+		{"", "hooks", "", true},
+		{repoDir, "hooks", repoDir.SJoin(".git", "hooks"), false},
+		{repoDir, "info/exclude", repoDir.SJoin(".git", "info", "exclude"), false},
+		{hooksPathRepoDir, "hooks", hooksPathRepoDir.SJoin(".githooks"), false},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestGetGitPath-%d", index+1), func(t *testing.T) {
+			t.Parallel()
+
+			gitPath, err := utils.GetGitPath(tc.dir, tc.path)
+			if tc.err && err == nil {
+				t.Fatalf("no error, but expected one")
+			}
+			if !tc.err && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if gitPath.String() != tc.expected.String() {
+				t.Fatalf("unexpected path: %s, expected %s", gitPath, tc.expected)
+			}
+		})
+	}
+}
+
+func TestGetGitRefCommit(t *testing.T) {
+	t.Parallel()
+
+	repoDir := models.Path(t.TempDir())
+	err := test_env.CreateLocalRepository(repoDir, "first", "second")
+	if err != nil {
+		t.Fatalf("error creating local repository: %s", err)
+	}
+
+	headSha, _, err := utils.GetGitFetchHead(repoDir)
+	if err != nil {
+		t.Fatalf("error getting head: %s", err)
+	}
+
+	_, err = utils.RunCommandCombinedOutput(repoDir, "git", "tag", "-a", "-m", "tag", "v1.0.0")
+	if err != nil {
+		t.Fatalf("error creating tag: %s", err)
+	}
+
+	cases := []struct {
+		dir      models.Path
+		ref      string
+		expected string
+		err      bool
+	}{
+		{"", "HEAD", "", true},
+		{repoDir, "", "", true},
+		{repoDir, "HEAD", headSha, false},
+		{repoDir, test_env.RepoBranchDefault, headSha, false},
+		{repoDir, "v1.0.0", headSha, false},
+		{repoDir, headSha[:7], headSha, false},
+		{repoDir, "nonexisting", "", true},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestGetGitRefCommit-%d", index+1), func(t *testing.T) {
+			t.Parallel()
+
+			commit, err := utils.GetGitRefCommit(tc.dir, tc.ref)
+			if tc.err && err == nil {
+				t.Fatalf("no error, but expected one")
+			}
+			if !tc.err && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if commit != tc.expected {
+				t.Fatalf("unexpected commit: >%s<, expected >%s<", commit, tc.expected)
+			}
+		})
+	}
+}
+
+func TestGitSetRemoteUrl(t *testing.T) {
+	cases := []struct {
+		existingUrl string
+		remote      string
+		url         string
+		err         bool
+	}{
+		{"", "", "https://example.com/foo", true},
+		{"", "origin", "", true},
+		{"", "origin", "https://example.com/foo", false},
+		{"https://example.com/bar", "origin", "https://example.com/foo", false},
+		{"https://example.com/bar", "upstream", "https://example.com/foo", false},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestGitSetRemoteUrl-%d", index+1), func(t *testing.T) {
+			t.Parallel()
+
+			repoDir := models.Path(t.TempDir())
+			err := test_env.CreateLocalRepository(repoDir)
+			if err != nil {
+				t.Fatalf("error creating local repository: %s", err)
+			}
+
+			if tc.existingUrl != "" {
+				_, err = utils.RunCommandCombinedOutput(repoDir, "git", "remote", "add", "origin", tc.existingUrl)
+				if err != nil {
+					t.Fatalf("error adding remote: %s", err)
+				}
+			}
+
+			err = utils.GitSetRemoteUrl(repoDir, tc.remote, tc.url)
+			if tc.err && err == nil {
+				t.Fatalf("no error, but expected one")
+			}
+			if !tc.err && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if tc.err {
+				return
+			}
+
+			url, err := utils.RunCommandCombinedOutput(repoDir, "git", "config", "--get", "remote."+tc.remote+".url")
+			if err != nil {
+				t.Fatalf("error reading remote url: %s", err)
+			}
+			if url != tc.url {
+				t.Fatalf("unexpected remote url: >%s<, expected >%s<", url, tc.url)
+			}
+		})
+	}
+}
+
+func TestGitRemotes(t *testing.T) {
+	cases := []struct {
+		remotes  []string
+		rename   string
+		expected []string
+		err      bool
+	}{
+		{nil, "", nil, false},
+		{[]string{"origin"}, "", []string{"origin"}, false},
+		{[]string{"origin", "fork"}, "upstream", []string{"fork", "upstream"}, false},
+		{nil, "upstream", nil, true},
+		{[]string{"origin", "upstream"}, "upstream", nil, true},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestGitRemotes-%d", index+1), func(t *testing.T) {
+			t.Parallel()
+
+			repoDir := models.Path(t.TempDir())
+			err := test_env.CreateLocalRepository(repoDir)
+			if err != nil {
+				t.Fatalf("error creating local repository: %s", err)
+			}
+
+			for _, remote := range tc.remotes {
+				err = utils.GitSetRemoteUrl(repoDir, remote, "https://example.com/"+remote)
+				if err != nil {
+					t.Fatalf("error adding remote: %s", err)
+				}
+			}
+
+			if tc.rename != "" {
+				err = utils.GitRenameRemote(repoDir, "origin", tc.rename)
+				if tc.err && err == nil {
+					t.Fatalf("no error, but expected one")
+				}
+				if !tc.err && err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				if tc.err {
+					return
+				}
+			}
+
+			remotes, err := utils.GetGitRemotes(repoDir)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if strings.Join(remotes, ",") != strings.Join(tc.expected, ",") {
+				t.Fatalf("unexpected remotes %v, expected %v", remotes, tc.expected)
+			}
+
+			for _, remote := range tc.expected {
+				expectedUrl := "https://example.com/" + remote
+				if remote == tc.rename {
+					expectedUrl = "https://example.com/origin"
+				}
+
+				url, err := utils.GetGitRemoteUrlByName(repoDir, remote)
+				if err != nil {
+					t.Fatalf("error reading remote url: %s", err)
+				}
+				if url != expectedUrl {
+					t.Fatalf("unexpected remote url: >%s<, expected >%s<", url, expectedUrl)
+				}
+			}
+		})
+	}
+
+	_, err := utils.GetGitRemotes(nonExistingDir)
+	if err == nil {
+		t.Fatalf("no error for non-existing repository")
+	}
+}
+
+func TestGetGitStagedPaths(t *testing.T) {
+	t.Parallel()
+
+	repoDir := models.Path(t.TempDir())
+	err := test_env.CreateLocalRepository(repoDir)
+	if err != nil {
+		t.Fatalf("error creating local repository: %s", err)
+	}
+
+	for _, file := range []string{"foo", "bar baz", "deleted", "untracked"} {
+		err = utils.WriteStrToFile(repoDir.SJoin(file), file)
+		if err != nil {
+			t.Fatalf("error writing test file: %s", err)
+		}
+	}
+
+	commands := [][]string{
+		{"add", "deleted"},
+		{"commit", "-m", "add file to delete"},
+		{"rm", "--quiet", "deleted"},
+		{"add", "foo", "bar baz"},
+	}
+	for _, args := range commands {
+		out, err := utils.RunCommandCombinedOutput(repoDir, "git", args...)
+		if err != nil {
+			t.Fatalf("error running git %s: %s; %s", args[0], err, out)
+		}
+	}
+
+	// alternative index that only contains the committed state
+	alternativeIndex := repoDir.SJoin("alternative-index")
+	out, err := utils.RunCommandCombinedOutput(repoDir, "sh", "-c", "GIT_INDEX_FILE="+alternativeIndex.String()+" git read-tree HEAD")
+	if err != nil {
+		t.Fatalf("error creating alternative index: %s; %s", err, out)
+	}
+
+	cases := []struct {
+		dir       models.Path
+		indexFile models.Path
+		expected  []string
+		err       bool
+	}{
+		{"", "", nil, true},
+		{repoDir, "", []string{"bar baz", "foo"}, false},
+		{repoDir, alternativeIndex, nil, false},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestGetGitStagedPaths-%d", index+1), func(t *testing.T) {
+			t.Parallel()
+
+			paths, err := utils.GetGitStagedPaths(tc.dir, tc.indexFile)
+			if tc.err && err == nil {
+				t.Fatalf("no error, but expected one")
+			}
+			if !tc.err && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !slices.Equal(paths, tc.expected) {
+				t.Fatalf("unexpected staged paths: %v, expected %v", paths, tc.expected)
+			}
+		})
+	}
+}
+
+func TestGetGitRepositoryDirectories(t *testing.T) {
+	t.Parallel()
+
+	tempDir := models.Path(t.TempDir())
+	mainRepo := tempDir.SJoin("main")
+	worktree := tempDir.SJoin("worktree")
+	subDir := mainRepo.SJoin("sub")
+
+	err := test_env.CreateLocalRepository(mainRepo, "initial commit")
+	if err != nil {
+		t.Fatalf("error creating local repository: %s", err)
+	}
+
+	err = os.Mkdir(subDir.String(), os.ModePerm)
+	if err != nil {
+		t.Fatalf("error creating directory: %s", err)
+	}
+
+	out, err := utils.RunCommandCombinedOutput(mainRepo, "git", "worktree", "add", "--quiet", "--detach", worktree.String())
+	if err != nil {
+		t.Fatalf("error adding worktree: %s; %s", err, out)
+	}
+
+	cases := []struct {
+		dir               models.Path
+		expectedRoot      models.Path
+		expectedCommonDir models.Path
+		err               bool
+	}{
+		{"", "", "", true},
+		{tempDir, "", "", true},
+		{mainRepo, mainRepo, mainRepo.SJoin(".git"), false},
+		{subDir, mainRepo, mainRepo.SJoin(".git"), false},
+		{worktree, worktree, mainRepo.SJoin(".git"), false},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestGetGitRepositoryDirectories-%d", index+1), func(t *testing.T) {
+			t.Parallel()
+
+			root, commonDir, err := utils.GetGitRepositoryDirectories(tc.dir)
+			if tc.err && err == nil {
+				t.Fatalf("no error, but expected one")
+			}
+			if !tc.err && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if root.String() != tc.expectedRoot.String() {
+				t.Fatalf("unexpected root: %s, expected %s", root, tc.expectedRoot)
+			}
+			if commonDir.String() != tc.expectedCommonDir.String() {
+				t.Fatalf("unexpected common directory: %s, expected %s", commonDir, tc.expectedCommonDir)
+			}
+		})
+	}
+}
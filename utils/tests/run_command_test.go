@@ -1 +1,84 @@
 package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jeftadlvw/git-nest/utils"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestRunCommandContext(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a unix shell")
+	}
+
+	// commands that leave child processes behind are only terminated quickly within their own process group
+	utils.SetIsolateProcessGroups(true)
+	defer utils.SetIsolateProcessGroups(false)
+
+	cases := []struct {
+		args    []string
+		timeout time.Duration
+		out     string
+		err     error
+	}{
+		{[]string{"echo", "hello"}, time.Second, "hello", nil},
+		{[]string{"sleep", "5"}, 200 * time.Millisecond, "", context.DeadlineExceeded},
+		{[]string{"sh", "-c", "sleep 5 & wait"}, 200 * time.Millisecond, "", context.DeadlineExceeded},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestRunCommandContext-%d", index+1), func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), tc.timeout)
+			defer cancel()
+
+			start := time.Now()
+			out, err := utils.RunCommandCombinedOutputContext(ctx, "", tc.args[0], tc.args[1:]...)
+			if elapsed := time.Since(start); elapsed > 3*time.Second {
+				t.Fatalf("command was not terminated in time, took %s", elapsed)
+			}
+
+			if tc.err == nil && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if tc.err != nil && !errors.Is(err, tc.err) {
+				t.Fatalf("unexpected error: %v, expected %v", err, tc.err)
+			}
+			if out != tc.out {
+				t.Fatalf("unexpected output: >%s<, expected >%s<", out, tc.out)
+			}
+		})
+	}
+}
+
+func TestRunCommandCombinedOutputError(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a unix shell")
+	}
+
+	cases := []struct {
+		script string
+		out    string
+	}{
+		{"echo failed; exit 1", "failed"},
+		{"echo failed >&2; exit 128", "failed"},
+		{"exit 1", ""},
+	}
+
+	for index, tc := range cases {
+		t.Run(fmt.Sprintf("TestRunCommandCombinedOutputError-%d", index+1), func(t *testing.T) {
+			t.Parallel()
+
+			out, err := utils.RunCommandCombinedOutput("", "sh", "-c", tc.script)
+			if err == nil {
+				t.Fatalf("no error, but expected one")
+			}
+			if out != tc.out {
+				t.Fatalf("unexpected output: >%s<, expected >%s<", out, tc.out)
+			}
+		})
+	}
+}